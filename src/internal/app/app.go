@@ -42,13 +42,22 @@ func (a *App) Run() error {
 	r := server.SetupRouter(ctx, a.sp)
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%s", httpPort),
-		Handler: r,
+		Addr:      fmt.Sprintf(":%s", httpPort),
+		Handler:   r,
+		TLSConfig: a.sp.ServerTLSConfig(),
 	}
 
 	go func() {
-		logger.Info("Starting HTTP server on port %s", httpPort)
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if server.TLSConfig != nil {
+			// Сертификат и ключ уже загружены в TLSConfig
+			logger.Info("Starting HTTPS server on port %s", httpPort)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			logger.Info("Starting HTTP server on port %s", httpPort)
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatalf("Error starting server: %v", err)
 		}
 	}()
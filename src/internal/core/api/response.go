@@ -14,6 +14,22 @@ func SuccessResponse(c *gin.Context, data any) {
 	})
 }
 
+// PaginationMeta описывает метаданные постраничной выдачи
+type PaginationMeta struct {
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PaginatedResponse отправляет успешный ответ со списком данных и метаданными пагинации
+func PaginatedResponse(c *gin.Context, data any, meta PaginationMeta) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"meta": meta,
+	})
+}
+
 // CreatedResponse отправляет ответ о создании ресурса с его ID и дополнительными данными
 func CreatedResponse(c *gin.Context, id string, data any) {
 	response := gin.H{
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Conditions накапливает условия WHERE и значения позиционных параметров.
+// Выражения задаются только кодом приложения, а пользовательские значения
+// всегда передаются через параметры ($1, $2, ...), что исключает SQL-инъекции
+type Conditions struct {
+	parts []string
+	args  []any
+}
+
+// Add добавляет условие. Каждый символ "?" в выражении заменяется
+// на очередной позиционный параметр со значением из args
+func (c *Conditions) Add(expr string, args ...any) {
+	var b strings.Builder
+	next := 0
+	for _, r := range expr {
+		if r == '?' && next < len(args) {
+			b.WriteString(c.Arg(args[next]))
+			next++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	c.parts = append(c.parts, b.String())
+}
+
+// Arg регистрирует значение параметра и возвращает его плейсхолдер
+func (c *Conditions) Arg(value any) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+// Where возвращает предложение WHERE или пустую строку, если условий нет
+func (c *Conditions) Where() string {
+	if len(c.parts) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.parts, " AND ")
+}
+
+// Args возвращает значения параметров в порядке плейсхолдеров
+func (c *Conditions) Args() []any {
+	return c.args
+}
+
+// Clone возвращает независимую копию условий
+func (c *Conditions) Clone() *Conditions {
+	return &Conditions{
+		parts: append([]string(nil), c.parts...),
+		args:  append([]any(nil), c.args...),
+	}
+}
@@ -59,6 +59,20 @@ func (p *pg) ExecContext(ctx context.Context, q db.Query, args ...any) (pgconn.C
 		return tx.Exec(ctx, q.QueryRaw, args...)
 	}
 
+	if db.SessionFromContext(ctx) != nil {
+		var tag pgconn.CommandTag
+		tx, err := p.beginSession(ctx)
+		if err != nil {
+			return tag, err
+		}
+		tag, err = tx.Exec(ctx, q.QueryRaw, args...)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return tag, err
+		}
+		return tag, tx.Commit(ctx)
+	}
+
 	return p.dbc.Exec(ctx, q.QueryRaw, args...)
 }
 
@@ -70,6 +84,10 @@ func (p *pg) QueryContext(ctx context.Context, q db.Query, args ...any) (pgx.Row
 		return tx.Query(ctx, q.QueryRaw, args...)
 	}
 
+	if db.SessionFromContext(ctx) != nil {
+		return p.querySession(ctx, q, args...)
+	}
+
 	return p.dbc.Query(ctx, q.QueryRaw, args...)
 }
 
@@ -81,9 +99,30 @@ func (p *pg) QueryRowContext(ctx context.Context, q db.Query, args ...any) pgx.R
 		return tx.QueryRow(ctx, q.QueryRaw, args...)
 	}
 
+	if db.SessionFromContext(ctx) != nil {
+		rows, err := p.querySession(ctx, q, args...)
+		return &sessionRow{rows: rows, err: err}
+	}
+
 	return p.dbc.QueryRow(ctx, q.QueryRaw, args...)
 }
 
+// querySession выполняет запрос вне транзакции с параметрами сессии из контекста
+func (p *pg) querySession(ctx context.Context, q db.Query, args ...any) (pgx.Rows, error) {
+	tx, err := p.beginSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, q.QueryRaw, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return &sessionRows{Rows: rows, ctx: ctx, tx: tx, logger: p.logger}, nil
+}
+
 func (p *pg) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
 	return p.dbc.BeginTx(ctx, txOptions)
 }
@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+)
+
+// setSessionQuery устанавливает параметры сессии до конца транзакции: set_config(..., true)
+// равнозначен SET LOCAL, но принимает значения параметрами запроса
+const setSessionQuery = `SELECT set_config('app.user_id', $1, true),
+	set_config('app.tenant_id', $2, true),
+	set_config('app.roles', $3, true),
+	set_config('app.bypass_rls', $4, true)`
+
+// SetSession устанавливает в транзакции параметры сессии из контекста. Без параметров ничего не делает
+func SetSession(ctx context.Context, tx pgx.Tx) error {
+	s := db.SessionFromContext(ctx)
+	if s == nil {
+		return nil
+	}
+
+	bypass := "off"
+	if s.Bypass {
+		bypass = "on"
+	}
+
+	_, err := tx.Exec(ctx, setSessionQuery, s.UserID, s.TenantID, strings.Join(s.Roles, ","), bypass)
+	return err
+}
+
+// beginSession начинает транзакцию из одного запроса с параметрами сессии из контекста.
+// SET LOCAL действует только внутри транзакции, поэтому запросы вне транзакции менеджера
+// получают параметры так же, как и в ней
+func (p *pg) beginSession(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.dbc.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetSession(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// sessionRows результат запроса в транзакции beginSession. Транзакция фиксируется при закрытии,
+// а ошибка фиксации возвращается из Err, так как запрос с RETURNING может изменять данные
+type sessionRows struct {
+	pgx.Rows
+
+	ctx    context.Context
+	tx     pgx.Tx
+	logger logger.Logger
+
+	once sync.Once
+	err  error
+}
+
+func (r *sessionRows) Close() {
+	r.Rows.Close()
+	r.once.Do(func() {
+		if r.Rows.Err() != nil {
+			_ = r.tx.Rollback(r.ctx)
+			return
+		}
+		if err := r.tx.Commit(r.ctx); err != nil {
+			r.err = err
+			if r.logger != nil {
+				r.logger.WithError(err).Error("Failed to commit session transaction")
+			}
+		}
+	})
+}
+
+func (r *sessionRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+// sessionRow реализует pgx.Row поверх sessionRows так же, как QueryRow в pgx
+type sessionRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *sessionRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	r.rows.Close()
+	return r.rows.Err()
+}
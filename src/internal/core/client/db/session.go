@@ -0,0 +1,46 @@
+package db
+
+import "context"
+
+// Значения app.tenant_id, не являющиеся идентификатором организации
+const (
+	// SessionTenantAll доступ к данным всех организаций (суперадминистратор без выбранной организации)
+	SessionTenantAll = "*"
+	// SessionTenantPlatform доступ только к данным без организации (уровень платформы)
+	SessionTenantPlatform = "platform"
+)
+
+// Session параметры сессии БД, по которым политики row-level security (RLS) ограничивают строки.
+// Менеджер транзакций устанавливает их через SET LOCAL, поэтому после завершения транзакции
+// соединение возвращается в пул без них
+type Session struct {
+	// UserID пользователь запроса (app.user_id); пусто для сервисных клиентов и фоновых задач
+	UserID string
+	// TenantID организация запроса (app.tenant_id): UUID, SessionTenantPlatform или SessionTenantAll
+	TenantID string
+	// Roles названия ролей пользователя (app.roles, через запятую)
+	Roles []string
+	// Bypass отключает политики RLS (app.bypass_rls). Только для служебных задач, см. WithoutRowSecurity
+	Bypass bool
+}
+
+type sessionKey struct{}
+
+// WithSession возвращает контекст, запросы в котором выполняются с параметрами сессии s
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// SessionFromContext возвращает параметры сессии запроса. Без них политики RLS не возвращают строк
+// защищённых таблиц: служебные запросы (вход в систему, фоновые задачи) должны явно использовать WithoutRowSecurity
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// WithoutRowSecurity явно отключает политики RLS для служебных задач, которым нужны строки
+// всех пользователей и организаций: вход в систему и обновление токенов, пока субъект ещё не известен,
+// очистка и обслуживание. Внутри уже начатой транзакции не действует: параметры сессии задаются при её начале
+func WithoutRowSecurity(ctx context.Context) context.Context {
+	return WithSession(ctx, &Session{Bypass: true})
+}
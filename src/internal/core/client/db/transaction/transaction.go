@@ -56,6 +56,11 @@ func (m *manager) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Han
 		}
 	}()
 
+	// Параметры сессии для политик row-level security действуют до конца транзакции (SET LOCAL)
+	if err = pg.SetSession(ctx, tx); err != nil {
+		return errors.Wrap(err, "can't set session parameters")
+	}
+
 	if err = fn(ctx); err != nil {
 		err = errors.Wrap(err, "failed executing code inside transaction")
 	}
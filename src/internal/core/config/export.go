@@ -0,0 +1,80 @@
+package config
+
+import (
+	"strconv"
+)
+
+const (
+	exportDir            = "EXPORT_DIR"
+	exportSigningKey     = "EXPORT_SIGNING_KEY"
+	exportLinkTTLSeconds = "EXPORT_LINK_TTL_SECONDS"
+	exportRetentionHours = "EXPORT_RETENTION_HOURS"
+	exportBatchSize      = "EXPORT_BATCH_SIZE"
+	exportPublicURL      = "EXPORT_PUBLIC_URL"
+)
+
+type ExportConfig interface {
+	// Dir каталог, в который записываются файлы выгрузок
+	Dir() string
+	// SigningKey ключ подписи ссылок на скачивание; если не задан, используется JWT_SECRET_KEY
+	SigningKey() string
+	// LinkTTLSeconds время жизни подписанной ссылки на скачивание
+	LinkTTLSeconds() int
+	// RetentionHours сколько часов хранится готовый файл выгрузки
+	RetentionHours() int
+	// BatchSize сколько пользователей читается из БД за один запрос
+	BatchSize() int
+	// PublicURL внешний адрес API для построения ссылок; пустое значение - относительные ссылки
+	PublicURL() string
+}
+
+type exportConfig struct {
+	dir            string
+	signingKey     string
+	linkTTLSeconds int
+	retentionHours int
+	batchSize      int
+	publicURL      string
+}
+
+func NewExportConfig() (ExportConfig, error) {
+	linkTTLSeconds, _ := strconv.Atoi(getEnv(exportLinkTTLSeconds, "900"))
+	retentionHours, _ := strconv.Atoi(getEnv(exportRetentionHours, "24"))
+	batchSize, _ := strconv.Atoi(getEnv(exportBatchSize, "500"))
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &exportConfig{
+		dir:            getEnv(exportDir, "storage/exports"),
+		signingKey:     getEnv(exportSigningKey, ""),
+		linkTTLSeconds: linkTTLSeconds,
+		retentionHours: retentionHours,
+		batchSize:      batchSize,
+		publicURL:      getEnv(exportPublicURL, ""),
+	}, nil
+}
+
+func (cfg *exportConfig) Dir() string {
+	return cfg.dir
+}
+
+func (cfg *exportConfig) SigningKey() string {
+	return cfg.signingKey
+}
+
+func (cfg *exportConfig) LinkTTLSeconds() int {
+	return cfg.linkTTLSeconds
+}
+
+func (cfg *exportConfig) RetentionHours() int {
+	return cfg.retentionHours
+}
+
+func (cfg *exportConfig) BatchSize() int {
+	return cfg.batchSize
+}
+
+func (cfg *exportConfig) PublicURL() string {
+	return cfg.publicURL
+}
@@ -1,26 +1,42 @@
 package config
 
+import "strings"
+
 const (
-	portEnvName = "HTTP_PORT"
-	hostEnvName = "HTTP_HOST"
+	portEnvName           = "HTTP_PORT"
+	hostEnvName           = "HTTP_HOST"
+	trustedProxiesEnvName = "HTTP_TRUSTED_PROXIES"
 )
 
 type HTTPConfig interface {
 	Port() string
 	Host() string
+	// TrustedProxies IP-адреса и подсети (CIDR) обратных прокси, которым разрешено передавать
+	// заголовки X-Forwarded-*. Пустой список - заголовки игнорируются
+	TrustedProxies() []string
 }
 
 type httpConfig struct {
-	port string
-	host string
+	port           string
+	host           string
+	trustedProxies []string
 }
 
 func NewHTTPConfig() (HTTPConfig, error) {
 	port := getEnv(portEnvName, "8080")
 	host := getEnv(hostEnvName, "localhost")
+
+	var trustedProxies []string
+	for _, proxy := range strings.Split(getEnv(trustedProxiesEnvName, ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
 	return &httpConfig{
-		port: port,
-		host: host,
+		port:           port,
+		host:           host,
+		trustedProxies: trustedProxies,
 	}, nil
 }
 
@@ -31,3 +47,7 @@ func (cfg *httpConfig) Port() string {
 func (cfg *httpConfig) Host() string {
 	return cfg.host
 }
+
+func (cfg *httpConfig) TrustedProxies() []string {
+	return cfg.trustedProxies
+}
@@ -5,32 +5,57 @@ import (
 )
 
 const (
-	secretKey                = "JWT_SECRET_KEY"
-	accessTokenExpiryMinutes = "ACCESS_TOKEN_EXPIRY_MINUTES"
-	refreshTokenExpiryHours  = "REFRESH_TOKEN_EXPIRY_HOURS"
+	secretKey                  = "JWT_SECRET_KEY"
+	accessTokenExpiryMinutes   = "ACCESS_TOKEN_EXPIRY_MINUTES"
+	refreshTokenExpiryHours    = "REFRESH_TOKEN_EXPIRY_HOURS"
+	sessionMaxLifetimeHours    = "SESSION_MAX_LIFETIME_HOURS"
+	rememberMeIdleTimeoutHours = "REMEMBER_ME_IDLE_TIMEOUT_HOURS"
+	rememberMeMaxLifetimeHours = "REMEMBER_ME_MAX_LIFETIME_HOURS"
+	dpopProofMaxAgeSeconds     = "DPOP_PROOF_MAX_AGE_SECONDS"
 )
 
 type JWTConfig interface {
 	SecretKey() string
 	AccessTokenExpiryMinutes() int
+	// RefreshTokenExpiryHours время бездействия, после которого обычная сессия истекает
 	RefreshTokenExpiryHours() int
+	// SessionMaxLifetimeHours абсолютный срок жизни обычной сессии
+	SessionMaxLifetimeHours() int
+	// RememberMeIdleTimeoutHours время бездействия для сессии "запомнить меня"
+	RememberMeIdleTimeoutHours() int
+	// RememberMeMaxLifetimeHours абсолютный срок жизни сессии "запомнить меня"
+	RememberMeMaxLifetimeHours() int
+	// DPoPProofMaxAgeSeconds сколько секунд после создания DPoP proof принимается сервером
+	DPoPProofMaxAgeSeconds() int
 }
 
 type jwtConfig struct {
-	secretKey                string
-	accessTokenExpiryMinutes int
-	refreshTokenExpiryHours  int
+	secretKey                  string
+	accessTokenExpiryMinutes   int
+	refreshTokenExpiryHours    int
+	sessionMaxLifetimeHours    int
+	rememberMeIdleTimeoutHours int
+	rememberMeMaxLifetimeHours int
+	dpopProofMaxAgeSeconds     int
 }
 
 func NewJWTConfig() (JWTConfig, error) {
 	secretKey := getEnv(secretKey, "sa!5da#54d3@4")
 	accessTokenExpiryMinutes, _ := strconv.Atoi(getEnv(accessTokenExpiryMinutes, "60"))
 	refreshTokenExpiryHours, _ := strconv.Atoi(getEnv(refreshTokenExpiryHours, "24"))
+	sessionMaxLifetimeHours, _ := strconv.Atoi(getEnv(sessionMaxLifetimeHours, "168"))
+	rememberMeIdleTimeoutHours, _ := strconv.Atoi(getEnv(rememberMeIdleTimeoutHours, "720"))
+	rememberMeMaxLifetimeHours, _ := strconv.Atoi(getEnv(rememberMeMaxLifetimeHours, "2160"))
+	dpopProofMaxAgeSeconds, _ := strconv.Atoi(getEnv(dpopProofMaxAgeSeconds, "60"))
 
 	return &jwtConfig{
-		secretKey:                secretKey,
-		accessTokenExpiryMinutes: accessTokenExpiryMinutes,
-		refreshTokenExpiryHours:  refreshTokenExpiryHours,
+		secretKey:                  secretKey,
+		accessTokenExpiryMinutes:   accessTokenExpiryMinutes,
+		refreshTokenExpiryHours:    refreshTokenExpiryHours,
+		sessionMaxLifetimeHours:    sessionMaxLifetimeHours,
+		rememberMeIdleTimeoutHours: rememberMeIdleTimeoutHours,
+		rememberMeMaxLifetimeHours: rememberMeMaxLifetimeHours,
+		dpopProofMaxAgeSeconds:     dpopProofMaxAgeSeconds,
 	}, nil
 }
 
@@ -45,3 +70,19 @@ func (cfg *jwtConfig) AccessTokenExpiryMinutes() int {
 func (cfg *jwtConfig) RefreshTokenExpiryHours() int {
 	return cfg.refreshTokenExpiryHours
 }
+
+func (cfg *jwtConfig) SessionMaxLifetimeHours() int {
+	return cfg.sessionMaxLifetimeHours
+}
+
+func (cfg *jwtConfig) RememberMeIdleTimeoutHours() int {
+	return cfg.rememberMeIdleTimeoutHours
+}
+
+func (cfg *jwtConfig) RememberMeMaxLifetimeHours() int {
+	return cfg.rememberMeMaxLifetimeHours
+}
+
+func (cfg *jwtConfig) DPoPProofMaxAgeSeconds() int {
+	return cfg.dpopProofMaxAgeSeconds
+}
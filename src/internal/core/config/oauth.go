@@ -0,0 +1,70 @@
+package config
+
+import (
+	"strconv"
+)
+
+const (
+	oauthDeviceCodeTTLSeconds      = "OAUTH_DEVICE_CODE_TTL_SECONDS"
+	oauthDevicePollIntervalSeconds = "OAUTH_DEVICE_POLL_INTERVAL_SECONDS"
+	oauthVerificationURI           = "OAUTH_VERIFICATION_URI"
+	oauthTokenEndpointURL          = "OAUTH_TOKEN_ENDPOINT_URL"
+	oauthTokenAudience             = "OAUTH_TOKEN_AUDIENCE"
+)
+
+type OAuthConfig interface {
+	// DeviceCodeTTLSeconds время жизни пары device_code/user_code
+	DeviceCodeTTLSeconds() int
+	// DevicePollIntervalSeconds минимальный интервал опроса token endpoint клиентом
+	DevicePollIntervalSeconds() int
+	// VerificationURI адрес страницы, на которой пользователь вводит user_code
+	VerificationURI() string
+	// TokenEndpointURL публичный адрес token endpoint, ожидаемый в aud у client_assertion
+	TokenEndpointURL() string
+	// TokenAudience идентификатор этого API в aud токенов, полученных через token exchange
+	TokenAudience() string
+}
+
+type oauthConfig struct {
+	deviceCodeTTLSeconds      int
+	devicePollIntervalSeconds int
+	verificationURI           string
+	tokenEndpointURL          string
+	tokenAudience             string
+}
+
+func NewOAuthConfig() (OAuthConfig, error) {
+	deviceCodeTTLSeconds, _ := strconv.Atoi(getEnv(oauthDeviceCodeTTLSeconds, "600"))
+	devicePollIntervalSeconds, _ := strconv.Atoi(getEnv(oauthDevicePollIntervalSeconds, "5"))
+	verificationURI := getEnv(oauthVerificationURI, "http://localhost:8080/device")
+	tokenEndpointURL := getEnv(oauthTokenEndpointURL, "http://localhost:8080/api/v1/oauth/token")
+	tokenAudience := getEnv(oauthTokenAudience, "user-api")
+
+	return &oauthConfig{
+		deviceCodeTTLSeconds:      deviceCodeTTLSeconds,
+		devicePollIntervalSeconds: devicePollIntervalSeconds,
+		verificationURI:           verificationURI,
+		tokenEndpointURL:          tokenEndpointURL,
+		tokenAudience:             tokenAudience,
+	}, nil
+}
+
+func (cfg *oauthConfig) DeviceCodeTTLSeconds() int {
+	return cfg.deviceCodeTTLSeconds
+}
+
+func (cfg *oauthConfig) DevicePollIntervalSeconds() int {
+	return cfg.devicePollIntervalSeconds
+}
+
+func (cfg *oauthConfig) VerificationURI() string {
+	return cfg.verificationURI
+}
+
+func (cfg *oauthConfig) TokenEndpointURL() string {
+	return cfg.tokenEndpointURL
+}
+
+func (cfg *oauthConfig) TokenAudience() string {
+	return cfg.tokenAudience
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xdevspo/go_tmpl_module_app/pkg/saml"
+)
+
+const (
+	samlEntityID          = "SAML_SP_ENTITY_ID"
+	samlACSURL            = "SAML_ACS_URL"
+	samlIdPConfigFile     = "SAML_IDP_CONFIG_FILE"
+	samlRequestTTLSeconds = "SAML_REQUEST_TTL_SECONDS"
+	samlClockSkewSeconds  = "SAML_CLOCK_SKEW_SECONDS"
+)
+
+// SAMLAttributeMapping имена атрибутов утверждения IdP, из которых берутся поля пользователя
+type SAMLAttributeMapping struct {
+	// Email атрибут с email; если не задан, используется NameID
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	// Roles многозначный атрибут с группами пользователя на стороне IdP
+	Roles string `json:"roles"`
+}
+
+// SAMLIdentityProvider настройки одного IdP из файла SAML_IDP_CONFIG_FILE
+type SAMLIdentityProvider struct {
+	Name     string `json:"name"`
+	EntityID string `json:"entity_id"`
+	SSOURL   string `json:"sso_url"`
+	// Certificate PEM-сертификаты подписи; альтернатива - CertificateFile
+	Certificate     string `json:"certificate"`
+	CertificateFile string `json:"certificate_file"`
+	// Domains домены email, пользователи которых входят через этот IdP
+	Domains    []string             `json:"domains"`
+	Attributes SAMLAttributeMapping `json:"attributes"`
+	// RoleMapping соответствие групп IdP ролям приложения
+	RoleMapping map[string]string `json:"role_mapping"`
+	// DefaultRoles роли, назначаемые при JIT-создании пользователя
+	DefaultRoles []string `json:"default_roles"`
+	// JITProvisioning создавать пользователя при первом входе
+	JITProvisioning bool `json:"jit_provisioning"`
+
+	identityProvider *saml.IdentityProvider
+}
+
+// IdentityProvider параметры IdP для проверки ответов
+func (idp *SAMLIdentityProvider) IdentityProvider() *saml.IdentityProvider {
+	return idp.identityProvider
+}
+
+type SAMLConfig interface {
+	// EntityID идентификатор SP, который регистрируется на стороне IdP
+	EntityID() string
+	// ACSURL публичный адрес Assertion Consumer Service
+	ACSURL() string
+	// RequestTTLSeconds время, за которое пользователь должен пройти вход на IdP
+	RequestTTLSeconds() int
+	// ClockSkewSeconds допустимое расхождение часов с IdP
+	ClockSkewSeconds() int
+	// IdentityProviderByDomain IdP для домена email пользователя
+	IdentityProviderByDomain(domain string) (*SAMLIdentityProvider, bool)
+	// IdentityProviderByEntityID IdP по его entityID
+	IdentityProviderByEntityID(entityID string) (*SAMLIdentityProvider, bool)
+}
+
+type samlConfig struct {
+	entityID          string
+	acsURL            string
+	requestTTLSeconds int
+	clockSkewSeconds  int
+	byDomain          map[string]*SAMLIdentityProvider
+	byEntityID        map[string]*SAMLIdentityProvider
+}
+
+type samlIdPFile struct {
+	IdentityProviders []*SAMLIdentityProvider `json:"identity_providers"`
+}
+
+func NewSAMLConfig() (SAMLConfig, error) {
+	entityID := getEnv(samlEntityID, "http://localhost:8080/api/v1/auth/saml/metadata")
+	acsURL := getEnv(samlACSURL, "http://localhost:8080/api/v1/auth/saml/acs")
+	requestTTLSeconds, _ := strconv.Atoi(getEnv(samlRequestTTLSeconds, "600"))
+	clockSkewSeconds, _ := strconv.Atoi(getEnv(samlClockSkewSeconds, "180"))
+
+	cfg := &samlConfig{
+		entityID:          entityID,
+		acsURL:            acsURL,
+		requestTTLSeconds: requestTTLSeconds,
+		clockSkewSeconds:  clockSkewSeconds,
+		byDomain:          make(map[string]*SAMLIdentityProvider),
+		byEntityID:        make(map[string]*SAMLIdentityProvider),
+	}
+
+	// Без файла SAML SSO выключен: ни один домен не привязан к IdP
+	path := getEnv(samlIdPConfigFile, "")
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", samlIdPConfigFile, err)
+	}
+
+	var file samlIdPFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", samlIdPConfigFile, err)
+	}
+
+	for _, idp := range file.IdentityProviders {
+		if err := cfg.addIdentityProvider(idp); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func (cfg *samlConfig) addIdentityProvider(idp *SAMLIdentityProvider) error {
+	if idp.EntityID == "" || idp.SSOURL == "" {
+		return fmt.Errorf("saml idp %q: entity_id and sso_url are required", idp.Name)
+	}
+	if _, exists := cfg.byEntityID[idp.EntityID]; exists {
+		return fmt.Errorf("saml idp %q: duplicate entity_id", idp.EntityID)
+	}
+
+	pemData := []byte(idp.Certificate)
+	if idp.CertificateFile != "" {
+		data, err := os.ReadFile(idp.CertificateFile)
+		if err != nil {
+			return fmt.Errorf("saml idp %q: %w", idp.EntityID, err)
+		}
+		pemData = append(pemData, data...)
+	}
+	certificates, err := saml.ParseCertificates(pemData)
+	if err != nil {
+		return fmt.Errorf("saml idp %q: %w", idp.EntityID, err)
+	}
+
+	idp.identityProvider = &saml.IdentityProvider{
+		EntityID:     idp.EntityID,
+		SSOURL:       idp.SSOURL,
+		Certificates: certificates,
+	}
+
+	cfg.byEntityID[idp.EntityID] = idp
+	for i, domain := range idp.Domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		idp.Domains[i] = domain
+		if _, exists := cfg.byDomain[domain]; exists {
+			return fmt.Errorf("saml domain %q is assigned to several idps", domain)
+		}
+		cfg.byDomain[domain] = idp
+	}
+
+	return nil
+}
+
+func (cfg *samlConfig) EntityID() string {
+	return cfg.entityID
+}
+
+func (cfg *samlConfig) ACSURL() string {
+	return cfg.acsURL
+}
+
+func (cfg *samlConfig) RequestTTLSeconds() int {
+	return cfg.requestTTLSeconds
+}
+
+func (cfg *samlConfig) ClockSkewSeconds() int {
+	return cfg.clockSkewSeconds
+}
+
+func (cfg *samlConfig) IdentityProviderByDomain(domain string) (*SAMLIdentityProvider, bool) {
+	idp, ok := cfg.byDomain[strings.ToLower(domain)]
+	return idp, ok
+}
+
+func (cfg *samlConfig) IdentityProviderByEntityID(entityID string) (*SAMLIdentityProvider, bool) {
+	idp, ok := cfg.byEntityID[entityID]
+	return idp, ok
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	storageDriver    = "STORAGE_DRIVER"
+	storageLocalDir  = "STORAGE_LOCAL_DIR"
+	storagePublicURL = "STORAGE_PUBLIC_URL"
+	s3Endpoint       = "S3_ENDPOINT"
+	s3Region         = "S3_REGION"
+	s3Bucket         = "S3_BUCKET"
+	s3AccessKey      = "S3_ACCESS_KEY"
+	s3SecretKey      = "S3_SECRET_KEY"
+	s3PathStyle      = "S3_PATH_STYLE"
+
+	// StorageDriverLocal файлы хранятся в локальном каталоге и раздаются приложением
+	StorageDriverLocal = "local"
+	// StorageDriverS3 файлы хранятся в S3-совместимом хранилище
+	StorageDriverS3 = "s3"
+)
+
+type StorageConfig interface {
+	// Driver тип хранилища: local или s3
+	Driver() string
+	// LocalDir каталог для драйвера local
+	LocalDir() string
+	// PublicURL базовый адрес ссылок на файлы; для s3 по умолчанию адрес bucket
+	PublicURL() string
+	S3Endpoint() string
+	S3Region() string
+	S3Bucket() string
+	S3AccessKey() string
+	S3SecretKey() string
+	// S3PathStyle адресация bucket через путь, нужна для MinIO
+	S3PathStyle() bool
+}
+
+type storageConfig struct {
+	driver      string
+	localDir    string
+	publicURL   string
+	s3Endpoint  string
+	s3Region    string
+	s3Bucket    string
+	s3AccessKey string
+	s3SecretKey string
+	s3PathStyle bool
+}
+
+func NewStorageConfig() (StorageConfig, error) {
+	driver := getEnv(storageDriver, StorageDriverLocal)
+	if driver != StorageDriverLocal && driver != StorageDriverS3 {
+		return nil, fmt.Errorf("unsupported %s: %s", storageDriver, driver)
+	}
+
+	publicURL := getEnv(storagePublicURL, "")
+	if publicURL == "" && driver == StorageDriverLocal {
+		publicURL = "/files"
+	}
+
+	pathStyle, _ := strconv.ParseBool(getEnv(s3PathStyle, "true"))
+
+	return &storageConfig{
+		driver:      driver,
+		localDir:    getEnv(storageLocalDir, "storage/files"),
+		publicURL:   publicURL,
+		s3Endpoint:  getEnv(s3Endpoint, ""),
+		s3Region:    getEnv(s3Region, "us-east-1"),
+		s3Bucket:    getEnv(s3Bucket, ""),
+		s3AccessKey: getEnv(s3AccessKey, ""),
+		s3SecretKey: getEnv(s3SecretKey, ""),
+		s3PathStyle: pathStyle,
+	}, nil
+}
+
+func (cfg *storageConfig) Driver() string {
+	return cfg.driver
+}
+
+func (cfg *storageConfig) LocalDir() string {
+	return cfg.localDir
+}
+
+func (cfg *storageConfig) PublicURL() string {
+	return cfg.publicURL
+}
+
+func (cfg *storageConfig) S3Endpoint() string {
+	return cfg.s3Endpoint
+}
+
+func (cfg *storageConfig) S3Region() string {
+	return cfg.s3Region
+}
+
+func (cfg *storageConfig) S3Bucket() string {
+	return cfg.s3Bucket
+}
+
+func (cfg *storageConfig) S3AccessKey() string {
+	return cfg.s3AccessKey
+}
+
+func (cfg *storageConfig) S3SecretKey() string {
+	return cfg.s3SecretKey
+}
+
+func (cfg *storageConfig) S3PathStyle() bool {
+	return cfg.s3PathStyle
+}
@@ -0,0 +1,55 @@
+package config
+
+const (
+	tlsCertFile      = "HTTP_TLS_CERT_FILE"
+	tlsKeyFile       = "HTTP_TLS_KEY_FILE"
+	tlsClientCAFile  = "HTTP_TLS_CLIENT_CA_FILE"
+	tlsClientCRLFile = "HTTP_TLS_CLIENT_CRL_FILE"
+)
+
+type TLSConfig interface {
+	// Enabled сервер обслуживает HTTPS: заданы сертификат и ключ
+	Enabled() bool
+	CertFile() string
+	KeyFile() string
+	// ClientCAFile УЦ клиентских сертификатов; если не задан, сертификаты у клиентов не запрашиваются
+	ClientCAFile() string
+	// ClientCRLFile список отзыва клиентских сертификатов; необязателен
+	ClientCRLFile() string
+}
+
+type tlsConfig struct {
+	certFile      string
+	keyFile       string
+	clientCAFile  string
+	clientCRLFile string
+}
+
+func NewTLSConfig() (TLSConfig, error) {
+	return &tlsConfig{
+		certFile:      getEnv(tlsCertFile, ""),
+		keyFile:       getEnv(tlsKeyFile, ""),
+		clientCAFile:  getEnv(tlsClientCAFile, ""),
+		clientCRLFile: getEnv(tlsClientCRLFile, ""),
+	}, nil
+}
+
+func (cfg *tlsConfig) Enabled() bool {
+	return cfg.certFile != "" && cfg.keyFile != ""
+}
+
+func (cfg *tlsConfig) CertFile() string {
+	return cfg.certFile
+}
+
+func (cfg *tlsConfig) KeyFile() string {
+	return cfg.keyFile
+}
+
+func (cfg *tlsConfig) ClientCAFile() string {
+	return cfg.clientCAFile
+}
+
+func (cfg *tlsConfig) ClientCRLFile() string {
+	return cfg.clientCRLFile
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strconv"
+)
+
+const (
+	userRestoreRetentionDays = "USER_RESTORE_RETENTION_DAYS"
+)
+
+type UserConfig interface {
+	// RestoreRetentionDays срок в днях, в течение которого удалённого пользователя можно восстановить
+	RestoreRetentionDays() int
+}
+
+type userConfig struct {
+	restoreRetentionDays int
+}
+
+func NewUserConfig() (UserConfig, error) {
+	restoreRetentionDays, _ := strconv.Atoi(getEnv(userRestoreRetentionDays, "30"))
+
+	return &userConfig{
+		restoreRetentionDays: restoreRetentionDays,
+	}, nil
+}
+
+func (cfg *userConfig) RestoreRetentionDays() int {
+	return cfg.restoreRetentionDays
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	webAuthnRPID                    = "WEBAUTHN_RP_ID"
+	webAuthnRPName                  = "WEBAUTHN_RP_NAME"
+	webAuthnOrigins                 = "WEBAUTHN_ORIGINS"
+	webAuthnChallengeTTLSeconds     = "WEBAUTHN_CHALLENGE_TTL_SECONDS"
+	webAuthnRequireUserVerification = "WEBAUTHN_REQUIRE_USER_VERIFICATION"
+)
+
+type WebAuthnConfig interface {
+	// RPID домен, к которому привязываются passkey (без схемы и порта)
+	RPID() string
+	// RPName название сервиса, которое показывает аутентификатор
+	RPName() string
+	// Origins адреса frontend'а, с которых разрешены церемонии WebAuthn
+	Origins() []string
+	// ChallengeTTLSeconds время жизни challenge регистрации или входа
+	ChallengeTTLSeconds() int
+	// RequireUserVerification требовать PIN или биометрию на аутентификаторе
+	RequireUserVerification() bool
+}
+
+type webAuthnConfig struct {
+	rpID                    string
+	rpName                  string
+	origins                 []string
+	challengeTTLSeconds     int
+	requireUserVerification bool
+}
+
+func NewWebAuthnConfig() (WebAuthnConfig, error) {
+	rpID := getEnv(webAuthnRPID, "localhost")
+	rpName := getEnv(webAuthnRPName, "Go Module App")
+	challengeTTLSeconds, _ := strconv.Atoi(getEnv(webAuthnChallengeTTLSeconds, "300"))
+	requireUserVerification, _ := strconv.ParseBool(getEnv(webAuthnRequireUserVerification, "true"))
+
+	var origins []string
+	for _, origin := range strings.Split(getEnv(webAuthnOrigins, "http://localhost:8080"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return &webAuthnConfig{
+		rpID:                    rpID,
+		rpName:                  rpName,
+		origins:                 origins,
+		challengeTTLSeconds:     challengeTTLSeconds,
+		requireUserVerification: requireUserVerification,
+	}, nil
+}
+
+func (cfg *webAuthnConfig) RPID() string {
+	return cfg.rpID
+}
+
+func (cfg *webAuthnConfig) RPName() string {
+	return cfg.rpName
+}
+
+func (cfg *webAuthnConfig) Origins() []string {
+	return cfg.origins
+}
+
+func (cfg *webAuthnConfig) ChallengeTTLSeconds() int {
+	return cfg.challengeTTLSeconds
+}
+
+func (cfg *webAuthnConfig) RequireUserVerification() bool {
+	return cfg.requireUserVerification
+}
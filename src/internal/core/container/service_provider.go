@@ -2,8 +2,11 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"io"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
@@ -16,18 +19,33 @@ import (
 	authRepoImpl "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/repository/impl"
 	authService "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
 	authServiceImpl "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service/impl"
+	oauthRepo "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+	oauthRepoImpl "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository/impl"
+	oauthService "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
+	oauthServiceImpl "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service/impl"
 	userRepo "github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
 	userRepoPostgres "github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository/postgres"
 	userService "github.com/xdevspo/go_tmpl_module_app/internal/module/user/service"
 	userServiceImpl "github.com/xdevspo/go_tmpl_module_app/internal/module/user/service/impl"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/dpop"
 	"github.com/xdevspo/go_tmpl_module_app/pkg/jwt"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/mtls"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/storage"
 )
 
 type ServiceProvider struct {
-	appConfig  config.AppConfig
-	pgConfig   config.PGConfig
-	jwtConfig  config.JWTConfig
-	httpConfig config.HTTPConfig
+	appConfig   config.AppConfig
+	pgConfig    config.PGConfig
+	jwtConfig   config.JWTConfig
+	httpConfig  config.HTTPConfig
+	oauthConfig config.OAuthConfig
+
+	webAuthnConfig config.WebAuthnConfig
+	samlConfig     config.SAMLConfig
+	tlsConfig      config.TLSConfig
+	userConfig     config.UserConfig
+	exportConfig   config.ExportConfig
+	storageConfig  config.StorageConfig
 
 	logrusLogger *logrus.Logger
 	logger       logger.Logger
@@ -35,11 +53,34 @@ type ServiceProvider struct {
 	dbClient  db.Client
 	txManager db.TxManager
 
-	userRepository         userRepo.UserRepository
-	refreshTokenRepository authRepo.RefreshTokenRepository
-
-	userService userService.UserService
-	authService authService.AuthService
+	dpopVerifier *dpop.Verifier
+
+	storage storage.Storage
+
+	clientCAs                    []*x509.Certificate
+	serverTLSConfig              *tls.Config
+	certificateRevocationChecker *mtls.RevocationChecker
+
+	userRepository                userRepo.UserRepository
+	exportJobRepository           userRepo.ExportJobRepository
+	suspensionRepository          userRepo.SuspensionRepository
+	organizationRepository        userRepo.OrganizationRepository
+	refreshTokenRepository        authRepo.RefreshTokenRepository
+	passkeyRepository             authRepo.PasskeyRepository
+	samlRequestRepository         authRepo.SAMLRequestRepository
+	deviceAuthorizationRepository oauthRepo.DeviceAuthorizationRepository
+	oauthClientRepository         oauthRepo.ClientRepository
+	tokenExchangeRuleRepository   oauthRepo.TokenExchangeRuleRepository
+	certificateMappingRepository  oauthRepo.CertificateMappingRepository
+
+	userService   userService.UserService
+	exportService userService.ExportService
+	authService   authService.AuthService
+	oauthService  oauthService.OAuthService
+	clientService oauthService.ClientService
+
+	passkeyService authService.PasskeyService
+	samlService    authService.SAMLService
 }
 
 func NewServiceProvider() *ServiceProvider {
@@ -105,6 +146,21 @@ func (sp *ServiceProvider) Logger() logger.Logger {
 	return sp.logger
 }
 
+// DPoPVerifier возвращает общую для всех запросов проверку DPoP proof:
+// кэш использованных jti должен быть единым. Кэш хранится в памяти процесса,
+// поэтому повтор proof на другом экземпляре приложения не обнаруживается
+func (sp *ServiceProvider) DPoPVerifier() *dpop.Verifier {
+	if sp.dpopVerifier == nil {
+		maxAge := time.Duration(sp.JWTConfig().DPoPProofMaxAgeSeconds()) * time.Second
+		verifier := dpop.NewVerifier(maxAge, dpop.NewMemoryReplayCache())
+		if err := verifier.SetTrustedProxies(sp.HTTPConfig().TrustedProxies()); err != nil {
+			sp.logger.Fatalf("failed to configure dpop trusted proxies: %s", err.Error())
+		}
+		sp.dpopVerifier = verifier
+	}
+	return sp.dpopVerifier
+}
+
 // LogrusLogger возвращает оригинальный logrus логгер (для обратной совместимости)
 func (sp *ServiceProvider) LogrusLogger() *logrus.Logger {
 	return sp.logrusLogger
@@ -173,6 +229,139 @@ func (sp *ServiceProvider) HTTPConfig() config.HTTPConfig {
 	return sp.httpConfig
 }
 
+func (sp *ServiceProvider) OAuthConfig() config.OAuthConfig {
+	if sp.oauthConfig == nil {
+		cfg, err := config.NewOAuthConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get oauth config: %s", err.Error())
+		}
+
+		sp.oauthConfig = cfg
+	}
+
+	return sp.oauthConfig
+}
+
+func (sp *ServiceProvider) WebAuthnConfig() config.WebAuthnConfig {
+	if sp.webAuthnConfig == nil {
+		cfg, err := config.NewWebAuthnConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get webauthn config: %s", err.Error())
+		}
+
+		sp.webAuthnConfig = cfg
+	}
+
+	return sp.webAuthnConfig
+}
+
+func (sp *ServiceProvider) SAMLConfig() config.SAMLConfig {
+	if sp.samlConfig == nil {
+		cfg, err := config.NewSAMLConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get saml config: %s", err.Error())
+		}
+
+		sp.samlConfig = cfg
+	}
+
+	return sp.samlConfig
+}
+
+func (sp *ServiceProvider) UserConfig() config.UserConfig {
+	if sp.userConfig == nil {
+		cfg, err := config.NewUserConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get user config: %s", err.Error())
+		}
+
+		sp.userConfig = cfg
+	}
+
+	return sp.userConfig
+}
+
+func (sp *ServiceProvider) ExportConfig() config.ExportConfig {
+	if sp.exportConfig == nil {
+		cfg, err := config.NewExportConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get export config: %s", err.Error())
+		}
+
+		sp.exportConfig = cfg
+	}
+
+	return sp.exportConfig
+}
+
+func (sp *ServiceProvider) StorageConfig() config.StorageConfig {
+	if sp.storageConfig == nil {
+		cfg, err := config.NewStorageConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get storage config: %s", err.Error())
+		}
+
+		sp.storageConfig = cfg
+	}
+
+	return sp.storageConfig
+}
+
+func (sp *ServiceProvider) TLSConfig() config.TLSConfig {
+	if sp.tlsConfig == nil {
+		cfg, err := config.NewTLSConfig()
+		if err != nil {
+			sp.logger.Fatalf("failed to get tls config: %s", err.Error())
+		}
+
+		sp.tlsConfig = cfg
+	}
+
+	return sp.tlsConfig
+}
+
+// ClientCAs возвращает УЦ клиентских сертификатов; пусто, если mTLS не настроен
+func (sp *ServiceProvider) ClientCAs() []*x509.Certificate {
+	if sp.clientCAs == nil && sp.TLSConfig().ClientCAFile() != "" {
+		cas, err := mtls.LoadCertificates(sp.TLSConfig().ClientCAFile())
+		if err != nil {
+			sp.logger.Fatalf("failed to load client ca: %s", err.Error())
+		}
+
+		sp.clientCAs = cas
+	}
+
+	return sp.clientCAs
+}
+
+// ServerTLSConfig возвращает настройки TLS сервера; nil, если сервер работает по HTTP
+func (sp *ServiceProvider) ServerTLSConfig() *tls.Config {
+	if sp.serverTLSConfig == nil && sp.TLSConfig().Enabled() {
+		cfg, err := mtls.ServerConfig(sp.TLSConfig().CertFile(), sp.TLSConfig().KeyFile(), sp.ClientCAs())
+		if err != nil {
+			sp.logger.Fatalf("failed to create tls config: %s", err.Error())
+		}
+
+		sp.serverTLSConfig = cfg
+	}
+
+	return sp.serverTLSConfig
+}
+
+// CertificateRevocationChecker возвращает проверку клиентских сертификатов по CRL; nil, если CRL не задан
+func (sp *ServiceProvider) CertificateRevocationChecker() *mtls.RevocationChecker {
+	if sp.certificateRevocationChecker == nil && sp.TLSConfig().ClientCRLFile() != "" {
+		checker, err := mtls.NewRevocationChecker(sp.TLSConfig().ClientCRLFile(), sp.ClientCAs())
+		if err != nil {
+			sp.logger.Fatalf("failed to load client crl: %s", err.Error())
+		}
+
+		sp.certificateRevocationChecker = checker
+	}
+
+	return sp.certificateRevocationChecker
+}
+
 func (sp *ServiceProvider) DBClient(ctx context.Context) db.Client {
 	if sp.dbClient == nil {
 		dbClient, err := pg.New(ctx, sp.PGConfig().DSN(), sp.Logger())
@@ -200,6 +389,34 @@ func (sp *ServiceProvider) TxManager(ctx context.Context) db.TxManager {
 	return sp.txManager
 }
 
+// Storage возвращает файловое хранилище, выбранное в STORAGE_DRIVER
+func (sp *ServiceProvider) Storage() storage.Storage {
+	if sp.storage == nil {
+		cfg := sp.StorageConfig()
+
+		switch cfg.Driver() {
+		case config.StorageDriverS3:
+			s3, err := storage.NewS3(storage.S3Options{
+				Endpoint:  cfg.S3Endpoint(),
+				Region:    cfg.S3Region(),
+				Bucket:    cfg.S3Bucket(),
+				AccessKey: cfg.S3AccessKey(),
+				SecretKey: cfg.S3SecretKey(),
+				PathStyle: cfg.S3PathStyle(),
+				PublicURL: cfg.PublicURL(),
+			})
+			if err != nil {
+				sp.logger.Fatalf("failed to create s3 storage: %s", err.Error())
+			}
+			sp.storage = s3
+		default:
+			sp.storage = storage.NewLocal(cfg.LocalDir(), cfg.PublicURL())
+		}
+	}
+
+	return sp.storage
+}
+
 func (sp *ServiceProvider) UserRepository(ctx context.Context) userRepo.UserRepository {
 	if sp.userRepository == nil {
 		sp.userRepository = userRepoPostgres.NewRepository(sp.DBClient(ctx), sp.TxManager(ctx), sp.Logger())
@@ -208,6 +425,30 @@ func (sp *ServiceProvider) UserRepository(ctx context.Context) userRepo.UserRepo
 	return sp.userRepository
 }
 
+func (sp *ServiceProvider) ExportJobRepository(ctx context.Context) userRepo.ExportJobRepository {
+	if sp.exportJobRepository == nil {
+		sp.exportJobRepository = userRepoPostgres.NewExportJobRepository(sp.DBClient(ctx), sp.Logger())
+	}
+
+	return sp.exportJobRepository
+}
+
+func (sp *ServiceProvider) SuspensionRepository(ctx context.Context) userRepo.SuspensionRepository {
+	if sp.suspensionRepository == nil {
+		sp.suspensionRepository = userRepoPostgres.NewSuspensionRepository(sp.DBClient(ctx), sp.Logger())
+	}
+
+	return sp.suspensionRepository
+}
+
+func (sp *ServiceProvider) OrganizationRepository(ctx context.Context) userRepo.OrganizationRepository {
+	if sp.organizationRepository == nil {
+		sp.organizationRepository = userRepoPostgres.NewOrganizationRepository(sp.DBClient(ctx), sp.Logger())
+	}
+
+	return sp.organizationRepository
+}
+
 func (sp *ServiceProvider) RefreshTokenRepository(ctx context.Context) authRepo.RefreshTokenRepository {
 	if sp.refreshTokenRepository == nil {
 		sp.refreshTokenRepository = authRepoImpl.NewRefreshTokenRepository(sp, sp.DBClient(ctx).DB())
@@ -215,9 +456,60 @@ func (sp *ServiceProvider) RefreshTokenRepository(ctx context.Context) authRepo.
 	return sp.refreshTokenRepository
 }
 
+func (sp *ServiceProvider) PasskeyRepository(ctx context.Context) authRepo.PasskeyRepository {
+	if sp.passkeyRepository == nil {
+		sp.passkeyRepository = authRepoImpl.NewPasskeyRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.passkeyRepository
+}
+
+func (sp *ServiceProvider) SAMLRequestRepository(ctx context.Context) authRepo.SAMLRequestRepository {
+	if sp.samlRequestRepository == nil {
+		sp.samlRequestRepository = authRepoImpl.NewSAMLRequestRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.samlRequestRepository
+}
+
+func (sp *ServiceProvider) DeviceAuthorizationRepository(ctx context.Context) oauthRepo.DeviceAuthorizationRepository {
+	if sp.deviceAuthorizationRepository == nil {
+		sp.deviceAuthorizationRepository = oauthRepoImpl.NewDeviceAuthorizationRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.deviceAuthorizationRepository
+}
+
+func (sp *ServiceProvider) OAuthClientRepository(ctx context.Context) oauthRepo.ClientRepository {
+	if sp.oauthClientRepository == nil {
+		sp.oauthClientRepository = oauthRepoImpl.NewClientRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.oauthClientRepository
+}
+
+func (sp *ServiceProvider) TokenExchangeRuleRepository(ctx context.Context) oauthRepo.TokenExchangeRuleRepository {
+	if sp.tokenExchangeRuleRepository == nil {
+		sp.tokenExchangeRuleRepository = oauthRepoImpl.NewTokenExchangeRuleRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.tokenExchangeRuleRepository
+}
+
+func (sp *ServiceProvider) CertificateMappingRepository(ctx context.Context) oauthRepo.CertificateMappingRepository {
+	if sp.certificateMappingRepository == nil {
+		sp.certificateMappingRepository = oauthRepoImpl.NewCertificateMappingRepository(sp, sp.DBClient(ctx).DB())
+	}
+	return sp.certificateMappingRepository
+}
+
 func (sp *ServiceProvider) UserService(ctx context.Context) userService.UserService {
 	if sp.userService == nil {
-		sp.userService = userServiceImpl.NewUserService(sp.UserRepository(ctx), sp.Logger(), sp.TxManager(ctx))
+		sp.userService = userServiceImpl.NewUserService(
+			sp.UserRepository(ctx),
+			sp.Logger(),
+			sp.TxManager(ctx),
+			sp.RefreshTokenRepository(ctx),
+			sp.UserConfig(),
+			sp.Storage(),
+			sp.SuspensionRepository(ctx),
+			sp.OrganizationRepository(ctx),
+		)
 	}
 	return sp.userService
 }
@@ -230,3 +522,51 @@ func (sp *ServiceProvider) AuthService(ctx context.Context) authService.AuthServ
 	}
 	return sp.authService
 }
+
+func (sp *ServiceProvider) PasskeyService(ctx context.Context) authService.PasskeyService {
+	if sp.passkeyService == nil {
+		sp.passkeyService = authServiceImpl.NewPasskeyService(sp.WebAuthnConfig(), sp)
+	}
+	return sp.passkeyService
+}
+
+func (sp *ServiceProvider) SAMLService(ctx context.Context) authService.SAMLService {
+	if sp.samlService == nil {
+		sp.samlService = authServiceImpl.NewSAMLService(sp.SAMLConfig(), sp)
+	}
+	return sp.samlService
+}
+
+func (sp *ServiceProvider) OAuthService(ctx context.Context) oauthService.OAuthService {
+	if sp.oauthService == nil {
+		jwtConfig := sp.JWTConfig()
+		jwtManager := jwt.NewManager(jwtConfig.SecretKey(), jwtConfig.AccessTokenExpiryMinutes())
+		sp.oauthService = oauthServiceImpl.NewOAuthService(sp.OAuthConfig(), sp, jwtManager)
+	}
+	return sp.oauthService
+}
+
+func (sp *ServiceProvider) ClientService(ctx context.Context) oauthService.ClientService {
+	if sp.clientService == nil {
+		sp.clientService = oauthServiceImpl.NewClientService(sp.OAuthConfig(), sp)
+	}
+	return sp.clientService
+}
+
+func (sp *ServiceProvider) ExportService(ctx context.Context) userService.ExportService {
+	if sp.exportService == nil {
+		signingKey := sp.ExportConfig().SigningKey()
+		if signingKey == "" {
+			signingKey = sp.JWTConfig().SecretKey()
+		}
+
+		sp.exportService = userServiceImpl.NewExportService(
+			sp.UserRepository(ctx),
+			sp.ExportJobRepository(ctx),
+			sp.Logger(),
+			sp.ExportConfig(),
+			signingKey,
+		)
+	}
+	return sp.exportService
+}
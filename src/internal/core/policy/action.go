@@ -0,0 +1,25 @@
+package policy
+
+// CheckAction проверяет действие над ресурсом как разрешение "<prefix>:<action>", поэтому новое
+// действие не требует отдельной ветки в политике. Для действий из overrides вместо разрешения
+// по умолчанию требуются все перечисленные разрешения. Шаблоны ("users:*", "*") учитывает Principal
+func CheckAction(principal Principal, prefix, action string, overrides map[string][]string) bool {
+	if principal == nil || action == "" {
+		return false
+	}
+
+	required, ok := overrides[action]
+	if !ok {
+		required = []string{prefix + ":" + action}
+	}
+	if len(required) == 0 {
+		return false
+	}
+
+	for _, permission := range required {
+		if !principal.HasPermission(permission) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package policy
+
+// Общие названия атрибутов субъектов и ресурсов
+const (
+	AttrID             = "id"
+	AttrOwnerID        = "owner_id"
+	AttrDataRole       = "data_role"
+	AttrOrganizationID = "organization_id"
+)
+
+// Attributes атрибуты субъекта или ресурса. Отсутствующий атрибут и пустое значение
+// равнозначны и никогда не совпадают с правилами доступа
+type Attributes map[string]string
+
+// Get возвращает значение атрибута или пустую строку
+func (a Attributes) Get(name string) string {
+	if a == nil {
+		return ""
+	}
+	return a[name]
+}
+
+// IsPlatform сообщает, что субъект не относится ни к одной организации (уровень платформы)
+func IsPlatform(principal Principal) bool {
+	return principal != nil && principal.Attributes().Get(AttrOrganizationID) == ""
+}
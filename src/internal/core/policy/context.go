@@ -0,0 +1,31 @@
+package policy
+
+import "context"
+
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст с субъектом запроса
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает субъект запроса или nil
+func PrincipalFromContext(ctx context.Context) Principal {
+	principal, _ := ctx.Value(principalKey{}).(Principal)
+	return principal
+}
+
+// NotHeld возвращает разрешения, которые субъект запроса из контекста не может передать другим
+// (роли, пользователю, клиенту), так как не имеет их сам. Шаблон передаётся, только если он покрыт
+// разрешениями субъекта целиком. Без субъекта запроса не передаётся ничего
+func NotHeld(ctx context.Context, permissions ...string) []string {
+	principal := PrincipalFromContext(ctx)
+
+	var denied []string
+	for _, permission := range permissions {
+		if principal == nil || !principal.HasPermission(permission) {
+			denied = append(denied, permission)
+		}
+	}
+	return denied
+}
@@ -2,10 +2,23 @@ package policy
 
 import (
 	"context"
-
-	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
+// Principal субъект запроса, права которого проверяют политики:
+// пользователь или машинный клиент (OAuth2 client credentials)
+type Principal interface {
+	HasRole(roleName string) bool
+	HasPermission(permissionName string) bool
+	HasAnyPermission(permissions ...string) bool
+	// Attributes атрибуты субъекта для проверок по атрибутам (ABAC): ID, data_role и т.д.
+	Attributes() Attributes
+}
+
 type Policy interface {
-	Check(ctx context.Context, user *model.User, resource string, action string) bool
+	Check(ctx context.Context, principal Principal, resource string, action string) bool
+	// CheckResource проверяет действие над конкретным ресурсом с учётом его атрибутов
+	CheckResource(ctx context.Context, principal Principal, resource string, action string, attrs Attributes) bool
+	// Scope возвращает ограничение выборки ресурсов, доступных субъекту для действия.
+	// Nil означает отсутствие ограничений
+	Scope(ctx context.Context, principal Principal, resource string, action string) *Scope
 }
@@ -0,0 +1,22 @@
+package policy
+
+import (
+	"context"
+)
+
+// IsOwner проверяет, что субъект является владельцем ресурса: ID субъекта совпадает
+// с атрибутом owner_id ресурса
+func IsOwner(principal Principal, attrs Attributes) bool {
+	if principal == nil {
+		return false
+	}
+
+	id := principal.Attributes().Get(AttrID)
+	return id != "" && id == attrs.Get(AttrOwnerID)
+}
+
+// SelfOr правило "владелец или разрешение": владельцу ресурса действие доступно всегда,
+// остальным - если его разрешает политика (например, при наличии "users:update")
+func SelfOr(ctx context.Context, p Policy, principal Principal, resource, action string, attrs Attributes) bool {
+	return IsOwner(principal, attrs) || CheckScoped(ctx, p, principal, resource, action, attrs)
+}
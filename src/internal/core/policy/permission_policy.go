@@ -2,33 +2,47 @@ package policy
 
 import (
 	"context"
-
-	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"slices"
 )
 
+// PermissionResourceName название ресурса каталога разрешений и разрешений ролей
+const PermissionResourceName = "permission"
+
+// RestrictedPermissions разрешения на опасные действия, которые не покрываются шаблонами вроде "users:*"
+// и "*:purge". Раньше их давало только "admin" (теперь "*"), и "users:full", переименованное
+// в "users:*", не должно молча расширяться до них. Передаются в permission.CompileRestricted
+var RestrictedPermissions = []string{"users:update-restricted", "users:purge"}
+
 type PermissionPolicy struct{}
 
 func NewPermissionPolicy() *PermissionPolicy {
 	return &PermissionPolicy{}
 }
 
-func (p *PermissionPolicy) Check(ctx context.Context, user *model.User, resource string, action string) bool {
-	if user.HasAnyPermission("full", "permissions:full") {
-		return true
-	}
+// permissionActionPermissions действия, для которых требуются разрешения с другими названиями
+var permissionActionPermissions = map[string][]string{
+	// Замена набора разрешений роли может как добавлять, так и снимать разрешения
+	"replace": {"permissions:assign", "permissions:revoke"},
+}
 
-	switch action {
-	case "create":
-		return user.HasPermission("permissions:create")
-	case "view":
-		return user.HasPermission("permissions:view")
-	case "delete":
-		return user.HasPermission("permissions:delete")
-	case "assign":
-		return user.HasPermission("permissions:assign")
-	case "revoke":
-		return user.HasPermission("permissions:revoke")
+// catalogActions действия над каталогом разрешений. Каталог общий для всех организаций,
+// поэтому изменять его могут только субъекты уровня платформы
+var catalogActions = []string{"create", "update", "delete"}
+
+// Check требует разрешение "permissions:<action>"
+func (p *PermissionPolicy) Check(ctx context.Context, principal Principal, resource string, action string) bool {
+	if slices.Contains(catalogActions, action) && !IsPlatform(principal) {
+		return false
 	}
+	return CheckAction(principal, "permissions", action, permissionActionPermissions)
+}
+
+// CheckResource не зависит от атрибутов ресурса: достаточно права на действие
+func (p *PermissionPolicy) CheckResource(ctx context.Context, principal Principal, resource string, action string, attrs Attributes) bool {
+	return p.Check(ctx, principal, resource, action)
+}
 
-	return false
+// Scope выборка не ограничивается
+func (p *PermissionPolicy) Scope(ctx context.Context, principal Principal, resource string, action string) *Scope {
+	return nil
 }
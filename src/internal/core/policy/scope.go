@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+)
+
+// Scope ограничение выборки ресурсов для субъекта. Ресурс доступен, если совпадает хотя бы
+// с одним правилом, а правило совпадает, когда равны все перечисленные в нём атрибуты.
+// Scope без правил не пропускает ничего; отсутствие ограничений обозначается nil
+type Scope struct {
+	Rules []Attributes `json:"rules"`
+}
+
+// DenyAll возвращает ограничение, под которое не попадает ни один ресурс
+func DenyAll() *Scope {
+	return &Scope{Rules: []Attributes{}}
+}
+
+// Allows проверяет, попадает ли ресурс с указанными атрибутами в ограничение
+func (s *Scope) Allows(attrs Attributes) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, rule := range s.Rules {
+		if ruleMatches(rule, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply добавляет ограничение в условия запроса. columns сопоставляет атрибуты с колонками;
+// правило с атрибутом без колонки проверить нельзя, поэтому оно ничего не пропускает
+func (s *Scope) Apply(conds *db.Conditions, columns map[string]string) {
+	if s == nil {
+		return
+	}
+
+	rules := make([]string, 0, len(s.Rules))
+	args := make([]any, 0, len(s.Rules))
+	for _, rule := range s.Rules {
+		if len(rule) == 0 {
+			continue
+		}
+
+		parts := make([]string, 0, len(rule))
+		ruleArgs := make([]any, 0, len(rule))
+		// Порядок атрибутов фиксирован, чтобы текст запроса не менялся между вызовами
+		for _, name := range slices.Sorted(maps.Keys(rule)) {
+			value := rule[name]
+			column, ok := columns[name]
+			if !ok || value == "" {
+				parts = nil
+				break
+			}
+			parts = append(parts, column+" = ?")
+			ruleArgs = append(ruleArgs, value)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		rules = append(rules, "("+strings.Join(parts, " AND ")+")")
+		args = append(args, ruleArgs...)
+	}
+
+	if len(rules) == 0 {
+		conds.Add("FALSE")
+		return
+	}
+	conds.Add("("+strings.Join(rules, " OR ")+")", args...)
+}
+
+func ruleMatches(rule, attrs Attributes) bool {
+	if len(rule) == 0 {
+		return false
+	}
+	for name, value := range rule {
+		if value == "" || attrs.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckScoped реализация CheckResource для политик с ограничением выборки: действие должно
+// быть разрешено, а ресурс - попадать в ограничение субъекта
+func CheckScoped(ctx context.Context, p Policy, principal Principal, resource, action string, attrs Attributes) bool {
+	if !p.Check(ctx, principal, resource, action) {
+		return false
+	}
+	return p.Scope(ctx, principal, resource, action).Allows(attrs)
+}
@@ -7,18 +7,47 @@ import (
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
 	authRepo "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/repository"
 	authService "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
+	oauthRepo "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+	oauthService "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
 	userRepo "github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
 	userService "github.com/xdevspo/go_tmpl_module_app/internal/module/user/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/dpop"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/mtls"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/storage"
 )
 
 // ServiceProvider defines the interface for accessing services
 type ServiceProvider interface {
 	Logger() logger.Logger
+	DPoPVerifier() *dpop.Verifier
 	AppConfig() config.AppConfig
 	JWTConfig() config.JWTConfig
 	HTTPConfig() config.HTTPConfig
+	OAuthConfig() config.OAuthConfig
+	WebAuthnConfig() config.WebAuthnConfig
+	SAMLConfig() config.SAMLConfig
+	TLSConfig() config.TLSConfig
+	UserConfig() config.UserConfig
+	ExportConfig() config.ExportConfig
+	StorageConfig() config.StorageConfig
+	Storage() storage.Storage
+	CertificateRevocationChecker() *mtls.RevocationChecker
 	UserRepository(ctx context.Context) userRepo.UserRepository
 	UserService(ctx context.Context) userService.UserService
+	ExportJobRepository(ctx context.Context) userRepo.ExportJobRepository
+	SuspensionRepository(ctx context.Context) userRepo.SuspensionRepository
+	OrganizationRepository(ctx context.Context) userRepo.OrganizationRepository
+	ExportService(ctx context.Context) userService.ExportService
 	AuthService(ctx context.Context) authService.AuthService
 	RefreshTokenRepository(ctx context.Context) authRepo.RefreshTokenRepository
+	PasskeyRepository(ctx context.Context) authRepo.PasskeyRepository
+	PasskeyService(ctx context.Context) authService.PasskeyService
+	SAMLRequestRepository(ctx context.Context) authRepo.SAMLRequestRepository
+	SAMLService(ctx context.Context) authService.SAMLService
+	DeviceAuthorizationRepository(ctx context.Context) oauthRepo.DeviceAuthorizationRepository
+	OAuthService(ctx context.Context) oauthService.OAuthService
+	OAuthClientRepository(ctx context.Context) oauthRepo.ClientRepository
+	ClientService(ctx context.Context) oauthService.ClientService
+	TokenExchangeRuleRepository(ctx context.Context) oauthRepo.TokenExchangeRuleRepository
+	CertificateMappingRepository(ctx context.Context) oauthRepo.CertificateMappingRepository
 }
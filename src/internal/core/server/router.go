@@ -3,14 +3,20 @@ package server
 import (
 	"context"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
 	container "github.com/xdevspo/go_tmpl_module_app/internal/core/container"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/i18n"
 	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
 	authHandlers "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/handler"
+	oauthHandlers "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/handler"
+	oauthPolicy "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/policy"
 	userHandlers "github.com/xdevspo/go_tmpl_module_app/internal/module/user/handler"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 	userPolicy "github.com/xdevspo/go_tmpl_module_app/internal/module/user/policy"
 	"github.com/xdevspo/go_tmpl_module_app/pkg/jwt"
 )
@@ -26,6 +32,9 @@ func SetupRouter(ctx context.Context, sp *container.ServiceProvider) *gin.Engine
 	}
 
 	router := gin.Default()
+	if err := router.SetTrustedProxies(sp.HTTPConfig().TrustedProxies()); err != nil {
+		logger.WithError(err).Fatal("Failed to configure trusted proxies")
+	}
 
 	router.Use(middleware.RequestLoggerWithLogger(logger))
 
@@ -42,10 +51,18 @@ func SetupRouter(ctx context.Context, sp *container.ServiceProvider) *gin.Engine
 	})
 
 	authHandler := authHandlers.NewAuthHandler(authService, sp)
+	passkeyHandler := authHandlers.NewPasskeyHandler(sp.PasskeyService(ctx), sp)
+	samlHandler := authHandlers.NewSAMLHandler(sp.SAMLService(ctx), sp)
+	oauthHandler := oauthHandlers.NewOAuthHandler(sp.OAuthService(ctx), sp)
+	clientHandler := oauthHandlers.NewClientHandler(sp.ClientService(ctx), sp)
 
 	userService := sp.UserService(ctx)
 	userHandler := userHandlers.NewUserHandler(userService, sp)
 
+	// Ссылки на аватары в ответах API строятся хранилищем
+	userModel.SetAvatarURLResolver(sp.Storage().URL)
+	registerLocalStorageRoute(router, sp.StorageConfig())
+
 	jwtConfig := sp.JWTConfig()
 	jwtManager := jwt.NewManager(jwtConfig.SecretKey(), jwtConfig.AccessTokenExpiryMinutes())
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager, sp)
@@ -59,11 +76,29 @@ func SetupRouter(ctx context.Context, sp *container.ServiceProvider) *gin.Engine
 	apiV1 := router.Group("/api/v1")
 
 	auth := apiV1.Group("/auth")
-	authHandler.RegisterPublicRoutes(auth)
+
+	// Публичные маршруты работают до аутентификации, поэтому явно отключают row-level security
+	authPublic := auth.Group("")
+	authPublic.Use(middleware.WithoutRowSecurity())
+	authHandler.RegisterPublicRoutes(authPublic)
+	passkeyHandler.RegisterPublicRoutes(authPublic.Group("/passkeys"))
+	samlHandler.RegisterRoutes(authPublic.Group("/saml"))
 
 	authProtected := auth.Group("")
 	authProtected.Use(authMiddleware.Authenticate())
 	authHandler.RegisterProtectedRoutes(authProtected)
+	passkeyHandler.RegisterProtectedRoutes(authProtected.Group("/passkeys"))
+
+	oauth := apiV1.Group("/oauth")
+
+	oauthPublic := oauth.Group("")
+	oauthPublic.Use(middleware.WithoutRowSecurity())
+	oauthHandler.RegisterPublicRoutes(oauthPublic)
+
+	oauthProtected := oauth.Group("")
+	oauthProtected.Use(authMiddleware.Authenticate())
+	oauthHandler.RegisterProtectedRoutes(oauthProtected)
+	clientHandler.RegisterClientRoutes(oauthProtected.Group("/clients"), policyMiddleware)
 
 	usersGroup := apiV1.Group("/users")
 	usersGroup.Use(authMiddleware.Authenticate())
@@ -72,13 +107,48 @@ func SetupRouter(ctx context.Context, sp *container.ServiceProvider) *gin.Engine
 	userHandler.RegisterUserRoutes(usersGroup, policyMiddleware)
 	userHandler.RegisterUserPermissionRoutes(usersGroup, policyMiddleware)
 	userHandler.RegisterUserRoleRoutes(usersGroup, policyMiddleware)
+	userHandler.RegisterExportDownloadRoutes(apiV1.Group("/users/exports"))
+
+	rolesGroup := apiV1.Group("/roles")
+	rolesGroup.Use(authMiddleware.Authenticate())
+	userHandler.RegisterRoleRoutes(rolesGroup, policyMiddleware)
+
+	permissionsGroup := apiV1.Group("/permissions")
+	permissionsGroup.Use(authMiddleware.Authenticate())
+	userHandler.RegisterPermissionRoutes(permissionsGroup, policyMiddleware)
+
+	organizationsGroup := apiV1.Group("/organizations")
+	organizationsGroup.Use(authMiddleware.Authenticate())
+	userHandler.RegisterOrganizationRoutes(organizationsGroup, policyMiddleware)
 
 	return router
 }
 
+// registerLocalStorageRoute раздаёт файлы локального хранилища по пути из STORAGE_PUBLIC_URL.
+// Листинг каталогов отключён; для S3 файлы отдаёт само хранилище
+func registerLocalStorageRoute(router *gin.Engine, cfg config.StorageConfig) {
+	if cfg.Driver() != config.StorageDriverLocal {
+		return
+	}
+
+	publicURL, err := url.Parse(cfg.PublicURL())
+	if err != nil {
+		return
+	}
+
+	path := strings.TrimSuffix(publicURL.Path, "/")
+	if path == "" {
+		return
+	}
+
+	router.Static(path, cfg.LocalDir())
+}
+
 // registerModulePolicies регистрирует политики всех модулей в центральной фабрике
 func registerModulePolicies(factory *corepolicy.PolicyFactory) {
+	factory.RegisterPolicy(corepolicy.PermissionResourceName, corepolicy.NewPermissionPolicy())
 	userPolicy.RegisterInFactory(factory)
+	oauthPolicy.RegisterInFactory(factory)
 
 	// Здесь можно добавить регистрацию политик других модулей
 	// somemodule.RegisterInFactory(factory)
@@ -0,0 +1,157 @@
+// Package tenant хранит организацию (арендатора), в рамках которой выполняется запрос,
+// и строит условия выборки, изолирующие данные организаций друг от друга
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+)
+
+// HeaderName заголовок, которым суперадминистратор платформы выбирает организацию
+const HeaderName = "X-Organization-ID"
+
+// SuperadminPermission разрешение суперадминистратора платформы. Действует только для субъектов
+// без организации, поэтому шаблон "*" в роли организации суперадминистратором не делает
+const SuperadminPermission = "platform:superadmin"
+
+var (
+	// ErrForeignOrganization субъект запросил организацию, к которой не относится
+	ErrForeignOrganization = errors.New("tenant: access to another organization")
+	// ErrInvalidHeader значение заголовка организации не является UUID
+	ErrInvalidHeader = errors.New("tenant: invalid organization header")
+)
+
+// Tenant арендатор запроса
+type Tenant struct {
+	// OrganizationID организация запроса; nil - уровень платформы (данные без организации)
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	// Superadmin суперадминистратор платформы. Без выбранной организации видит данные всех организаций
+	Superadmin bool `json:"superadmin,omitempty"`
+}
+
+// CrossTenant сообщает, что выборка не ограничивается организацией
+func (t *Tenant) CrossTenant() bool {
+	return t == nil || (t.Superadmin && t.OrganizationID == nil)
+}
+
+// Allows проверяет, доступна ли арендатору запись организации organizationID
+func (t *Tenant) Allows(organizationID *uuid.UUID) bool {
+	if t.CrossTenant() {
+		return true
+	}
+	if t.OrganizationID == nil || organizationID == nil {
+		return t.OrganizationID == nil && organizationID == nil
+	}
+	return *t.OrganizationID == *organizationID
+}
+
+// ManagesShared сообщает, может ли арендатор изменять общие записи платформы (например, глобальные роли)
+func (t *Tenant) ManagesShared() bool {
+	return t == nil || t.Superadmin || t.OrganizationID == nil
+}
+
+// Session возвращает параметры сессии БД, с которыми политики row-level security
+// повторяют ограничения Filter на уровне базы данных
+func (t *Tenant) Session(userID string, roles []string) *db.Session {
+	s := &db.Session{UserID: userID, Roles: roles}
+	switch {
+	case t.CrossTenant():
+		s.TenantID = db.SessionTenantAll
+	case t.OrganizationID == nil:
+		s.TenantID = db.SessionTenantPlatform
+	default:
+		s.TenantID = t.OrganizationID.String()
+	}
+	return s
+}
+
+type contextKey struct{}
+
+// WithContext возвращает контекст с арендатором
+func WithContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext возвращает арендатора запроса. Nil означает системный контекст без арендатора
+// (фоновые задачи, вход в систему): такие запросы организацией не ограничиваются
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(contextKey{}).(*Tenant)
+	return t
+}
+
+// OrganizationID возвращает организацию запроса или nil
+func OrganizationID(ctx context.Context) *uuid.UUID {
+	if t := FromContext(ctx); t != nil {
+		return t.OrganizationID
+	}
+	return nil
+}
+
+// IsSuperadmin проверяет, является ли субъект суперадминистратором платформы
+func IsSuperadmin(principal policy.Principal) bool {
+	return policy.IsPlatform(principal) && principal.HasPermission(SuperadminPermission)
+}
+
+// Resolve определяет арендатора по субъекту и значению заголовка HeaderName. Пользователь организации
+// всегда работает в своей организации; выбрать другую организацию заголовком может только суперадминистратор
+func Resolve(principal policy.Principal, header string) (*Tenant, error) {
+	if principal == nil {
+		return nil, ErrForeignOrganization
+	}
+	superadmin := IsSuperadmin(principal)
+
+	var requested *uuid.UUID
+	if header != "" {
+		id, err := uuid.Parse(header)
+		if err != nil {
+			return nil, ErrInvalidHeader
+		}
+		requested = &id
+	}
+
+	if own := principal.Attributes().Get(policy.AttrOrganizationID); own != "" {
+		id, err := uuid.Parse(own)
+		if err != nil {
+			return nil, err
+		}
+		if requested != nil && *requested != id {
+			return nil, ErrForeignOrganization
+		}
+		return &Tenant{OrganizationID: &id}, nil
+	}
+
+	if requested != nil && !superadmin {
+		return nil, ErrForeignOrganization
+	}
+	return &Tenant{OrganizationID: requested, Superadmin: superadmin}, nil
+}
+
+// Filter ограничивает выборку записями организации арендатора из контекста.
+// column - колонка organization_id; запись без организации относится к уровню платформы
+func Filter(ctx context.Context, conds *db.Conditions, column string) {
+	t := FromContext(ctx)
+	switch {
+	case t.CrossTenant():
+	case t.OrganizationID == nil:
+		conds.Add(column + " IS NULL")
+	default:
+		conds.Add(column+" = ?", *t.OrganizationID)
+	}
+}
+
+// FilterShared ограничивает выборку записями организации арендатора и общими записями платформы
+// (organization_id IS NULL), например глобальными ролями
+func FilterShared(ctx context.Context, conds *db.Conditions, column string) {
+	t := FromContext(ctx)
+	switch {
+	case t.CrossTenant():
+	case t.OrganizationID == nil:
+		conds.Add(column + " IS NULL")
+	default:
+		conds.Add("("+column+" IS NULL OR "+column+" = ?)", *t.OrganizationID)
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"net/http"
 	"strings"
@@ -9,9 +10,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	oauthModel "github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/dpop"
 	"github.com/xdevspo/go_tmpl_module_app/pkg/jwt"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/mtls"
 )
 
 // Константы для ключей контекста
@@ -22,15 +29,17 @@ const (
 
 // Middleware предоставляет middleware для аутентификации
 type AuthMiddleware struct {
-	jwtManager *jwt.Manager
-	sp         provider.ServiceProvider
+	jwtManager   *jwt.Manager
+	dpopVerifier *dpop.Verifier
+	sp           provider.ServiceProvider
 }
 
 // NewMiddleware создает новый экземпляр middleware для аутентификации
 func NewAuthMiddleware(jwtManager *jwt.Manager, sp provider.ServiceProvider) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtManager: jwtManager,
-		sp:         sp,
+		jwtManager:   jwtManager,
+		dpopVerifier: sp.DPoPVerifier(),
+		sp:           sp,
 	}
 }
 
@@ -39,7 +48,17 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		m.sp.Logger().Info("Starting authentication middleware")
 
-		tokenString, err := m.extractToken(c)
+		// Без заголовка Authorization сервисный клиент может предъявить TLS-сертификат.
+		// Если заголовок есть, действует обычная проверка токена
+		if c.GetHeader("Authorization") == "" {
+			cert, err := mtls.PeerCertificate(c.Request)
+			if cert != nil || err != nil {
+				m.authenticateCertificate(c, cert, err)
+				return
+			}
+		}
+
+		scheme, tokenString, err := m.extractToken(c)
 		if err != nil {
 			m.sp.Logger().WithError(err).Warn("Failed to extract token from request")
 			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", err, nil))
@@ -55,6 +74,29 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		// Токен, выпущенный для другого сервиса, не дает доступа к этому API.
+		// Делегированные токены этому API не выдаются, поэтому отклоняются и они
+		if !claims.IsIntendedFor(m.sp.OAuthConfig().TokenAudience()) || claims.Act != nil {
+			m.sp.Logger().WithField("audience", claims.Audience).Warn("Token audience mismatch")
+			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+			c.Abort()
+			return
+		}
+
+		if err := m.verifyDPoP(c, scheme, tokenString, claims); err != nil {
+			m.sp.Logger().WithError(err).Warn("DPoP verification failed")
+			c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", err, nil))
+			c.Abort()
+			return
+		}
+
+		if claims.IsClientToken() {
+			m.authenticateClient(c, claims)
+			return
+		}
+
 		m.sp.Logger().WithField("user_id", claims.UserID).Info("Token validated successfully")
 
 		userID, err := uuid.Parse(claims.UserID)
@@ -65,7 +107,10 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		user, err := m.sp.UserService(c.Request.Context()).GetByID(c.Request.Context(), userID)
+		// Субъект ещё не известен, поэтому пользователь загружается без ограничений row-level security.
+		// Параметры сессии субъекта устанавливает setTenant
+		lookupCtx := db.WithoutRowSecurity(c.Request.Context())
+		user, err := m.sp.UserService(lookupCtx).GetByID(lookupCtx, userID)
 		if err != nil {
 			m.sp.Logger().WithError(err).WithField("user_id", userID).Error("Failed to get user by ID")
 			apperrors.ResponseWithError(c, err)
@@ -82,6 +127,26 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		// Токены отключённой учётной записи перестают действовать сразу, не дожидаясь истечения срока
+		if !user.Active {
+			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("user.inactive", nil, nil))
+			c.Abort()
+			return
+		}
+		if user.Suspended {
+			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("user.suspended", nil, nil))
+			c.Abort()
+			return
+		}
+
+		// Токен пользователя организации действует только пока пользователь в ней состоит
+		if claims.OrganizationID != "" && (user.OrganizationID == nil || user.OrganizationID.String() != claims.OrganizationID) {
+			m.sp.Logger().WithField("user_id", user.ID).Warn("Token organization mismatch")
+			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("tenant.mismatch", nil, nil))
+			c.Abort()
+			return
+		}
+
 		m.sp.Logger().WithFields(logrus.Fields{
 			"user":    user,
 			"user_id": user.ID,
@@ -90,29 +155,184 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 
 		c.Set("user", user)
 		c.Set("userId", user.ID)
+		c.Set("principal", user)
 		c.Set("claims", claims)
 		ctx := context.WithValue(c.Request.Context(), UserContextKey, user)
 		c.Request = c.Request.WithContext(ctx)
 
+		if !m.setTenant(c, user, user.ID.String(), user.RoleNames()) {
+			return
+		}
+
 		m.sp.Logger().Info("User data set in context. Auth middleware complete.")
 
 		c.Next()
 	}
 }
 
-// extractToken извлекает JWT токен из заголовка Authorization
-func (m *AuthMiddleware) extractToken(c *gin.Context) (string, error) {
+// authenticateClient устанавливает в контекст сервисного клиента, которому выдан токен client_credentials
+func (m *AuthMiddleware) authenticateClient(c *gin.Context, claims *jwt.UserClaims) {
+	ctx := c.Request.Context()
+
+	clientID, err := uuid.Parse(claims.ClientID)
+	if err != nil {
+		m.sp.Logger().WithError(err).Error("Invalid client ID format in token")
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", err, nil))
+		c.Abort()
+		return
+	}
+
+	client, err := m.sp.ClientService(ctx).GetByID(ctx, clientID)
+	if err != nil {
+		m.sp.Logger().WithError(err).WithField("client_id", clientID).Error("Failed to get OAuth client by ID")
+		apperrors.ResponseWithError(c, err)
+		c.Abort()
+		return
+	}
+
+	// Отключенный или удаленный клиент теряет доступ сразу, не дожидаясь истечения токена
+	if client == nil || !client.Active {
+		m.sp.Logger().WithField("client_id", clientID).Warn("OAuth client not found or inactive")
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+		c.Abort()
+		return
+	}
+
+	principal := oauthModel.NewClientPrincipal(client, strings.Fields(claims.Scope))
+
+	c.Set("client", client)
+	c.Set("principal", principal)
+	c.Set("claims", claims)
+
+	if !m.setTenant(c, principal, "", nil) {
+		return
+	}
+
+	m.sp.Logger().WithField("client_id", client.ID).Info("Client data set in context. Auth middleware complete.")
+
+	c.Next()
+}
+
+// authenticateCertificate устанавливает в контекст сервисного клиента, сопоставленного клиентскому сертификату.
+// Каждая попытка записывается в журнал аудита с данными сертификата и результатом
+func (m *AuthMiddleware) authenticateCertificate(c *gin.Context, cert *x509.Certificate, certErr error) {
+	ctx := c.Request.Context()
+
+	audit := logrus.Fields{
+		"event":       "mtls_authentication",
+		"remote_addr": c.ClientIP(),
+		"method":      c.Request.Method,
+		"path":        c.Request.URL.Path,
+	}
+	if cert != nil {
+		audit["cert_subject"] = cert.Subject.String()
+		audit["cert_issuer"] = cert.Issuer.String()
+		audit["cert_serial"] = cert.SerialNumber.String()
+		audit["cert_fingerprint"] = mtls.Fingerprint(cert)
+	}
+
+	if certErr != nil {
+		audit["result"] = "denied"
+		m.sp.Logger().WithFields(audit).WithError(certErr).Warn("Client certificate authentication denied")
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", certErr, nil))
+		c.Abort()
+		return
+	}
+
+	client, identity, err := m.sp.ClientService(ctx).AuthenticateCertificate(ctx, cert)
+	if identity != nil {
+		audit["identity_type"] = identity.Type
+		audit["identity_value"] = identity.Value
+	}
+
+	if err != nil {
+		audit["result"] = "denied"
+		m.sp.Logger().WithFields(audit).WithError(err).Warn("Client certificate authentication denied")
+		apperrors.ResponseWithError(c, err)
+		c.Abort()
+		return
+	}
+
+	audit["result"] = "granted"
+	audit["client_id"] = client.ID
+	m.sp.Logger().WithFields(audit).Info("Client certificate authentication granted")
+
+	principal := oauthModel.NewClientPrincipal(client, client.Scopes)
+
+	c.Set("client", client)
+	c.Set("principal", principal)
+	c.Set("certificate", cert)
+
+	if !m.setTenant(c, principal, "", nil) {
+		return
+	}
+
+	c.Next()
+}
+
+// setTenant определяет арендатора запроса по субъекту и заголовку X-Organization-ID
+// и сохраняет его в контексте запроса вместе с субъектом и параметрами сессии БД для политик row-level security.
+// userID и roles передаются только для пользователей. При отказе запрос прерывается
+func (m *AuthMiddleware) setTenant(c *gin.Context, principal policy.Principal, userID string, roles []string) bool {
+	t, err := tenant.Resolve(principal, c.GetHeader(tenant.HeaderName))
+	if err != nil {
+		m.sp.Logger().WithError(err).Warn("Failed to resolve request organization")
+		switch {
+		case errors.Is(err, tenant.ErrInvalidHeader):
+			apperrors.ResponseWithError(c, apperrors.BadRequestError("tenant.invalid_header", err, nil))
+		default:
+			apperrors.ResponseWithError(c, apperrors.ForbiddenError("tenant.access_denied", err, nil))
+		}
+		c.Abort()
+		return false
+	}
+
+	ctx := tenant.WithContext(c.Request.Context(), t)
+	ctx = policy.WithPrincipal(ctx, principal)
+	ctx = db.WithSession(ctx, t.Session(userID, roles))
+	c.Request = c.Request.WithContext(ctx)
+	return true
+}
+
+// extractToken извлекает схему авторизации (Bearer или DPoP) и JWT токен из заголовка Authorization
+func (m *AuthMiddleware) extractToken(c *gin.Context) (string, string, error) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		return "", errors.New("отсутствует заголовок Authorization")
+		return "", "", errors.New("отсутствует заголовок Authorization")
 	}
 
 	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return "", errors.New("неверный формат токена")
+	if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "DPoP") {
+		return "", "", errors.New("неверный формат токена")
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// verifyDPoP проверяет, что токен, привязанный к ключу, предъявлен со схемой DPoP
+// и свежим proof, подписанным этим ключом (RFC 9449, раздел 7.1)
+func (m *AuthMiddleware) verifyDPoP(c *gin.Context, scheme, tokenString string, claims *jwt.UserClaims) error {
+	if !claims.IsDPoPBound() {
+		if scheme == "DPoP" {
+			return errors.New("токен не привязан к ключу DPoP")
+		}
+		return nil
+	}
+
+	if scheme != "DPoP" {
+		return errors.New("токен, привязанный к ключу DPoP, нельзя предъявлять как Bearer")
+	}
+
+	proof, err := m.dpopVerifier.VerifyRequest(c.Request, tokenString)
+	if err != nil {
+		return err
+	}
+
+	if proof.JKT != claims.Cnf.JKT {
+		return errors.New("proof подписан другим ключом")
 	}
 
-	return parts[1], nil
+	return nil
 }
 
 // RefreshToken проверяет и обновляет токен
@@ -127,18 +347,27 @@ func (m *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 			return
 		}
 
+		dpopJKT, err := m.dpopVerifier.OptionalKey(c.Request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_dpop_proof"})
+			return
+		}
+
 		ctx := context.WithValue(c.Request.Context(), RequestKey, c.Request)
 
-		tokenPair, err := m.sp.AuthService(ctx).RefreshToken(ctx, req.RefreshToken)
+		tokenPair, err := m.sp.AuthService(ctx).RefreshToken(ctx, req.RefreshToken, dpopJKT)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "недействительный токен обновления"})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"accessToken":     tokenPair.Token,
-			"refreshToken":    tokenPair.RefreshToken,
-			"accessExpiresAt": tokenPair.AccessExpiresAt,
+			"accessToken":      tokenPair.Token,
+			"refreshToken":     tokenPair.RefreshToken,
+			"tokenType":        tokenPair.TokenType,
+			"accessExpiresAt":  tokenPair.AccessExpiresAt,
+			"refreshExpiresAt": tokenPair.RefreshExpiresAt,
+			"sessionExpiresAt": tokenPair.SessionExpiresAt,
 		})
 	}
 }
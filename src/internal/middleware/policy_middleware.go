@@ -4,9 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
-	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
+// ResourceResolver находит ресурс по параметрам маршрута и возвращает его атрибуты.
+// Для несуществующего ресурса возвращает nil без ошибки: субъект с правом на действие получит 404,
+// без права - 403, чтобы существование ресурса не раскрывалось
+type ResourceResolver func(c *gin.Context) (policy.Attributes, error)
+
 type PolicyMiddleware struct {
 	policyFactory *policy.PolicyFactory
 }
@@ -17,40 +21,110 @@ func NewPolicyMiddleware(policyFactory *policy.PolicyFactory) *PolicyMiddleware
 
 func (m *PolicyMiddleware) RequirePermission(resource string, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		user, exists := c.Get("user")
-		if !exists {
-			apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
-			c.Abort()
+		principal, resourcePolicy, ok := m.prepare(c, resource)
+		if !ok {
+			return
+		}
+
+		if !resourcePolicy.Check(c.Request.Context(), principal, resource, action) {
+			abortForbidden(c, resource, action)
 			return
 		}
 
-		currentUser, ok := user.(*model.User)
+		c.Next()
+	}
+}
+
+// RequireResourcePermission проверяет действие над конкретным ресурсом, найденным resolve
+// по параметрам маршрута, с учётом его атрибутов (например, правило "владелец или разрешение").
+// Если действие в целом разрешено, но ресурс вне ограничения субъекта, отвечает 404
+func (m *PolicyMiddleware) RequireResourcePermission(resource string, action string, resolve ResourceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, resourcePolicy, ok := m.prepare(c, resource)
 		if !ok {
-			apperrors.ResponseWithError(c, apperrors.InternalServerError("errors.internal", nil, nil))
-			c.Abort()
 			return
 		}
 
-		policy, err := m.policyFactory.ForResource(resource)
+		attrs, err := resolve(c)
 		if err != nil {
-			apperrors.ResponseWithError(c, apperrors.ForbiddenError("errors.forbidden", err, map[string]interface{}{
-				"message":  "политика доступа не найдена",
-				"resource": resource,
-			}))
+			apperrors.ResponseWithError(c, err)
 			c.Abort()
 			return
 		}
 
-		if !policy.Check(c.Request.Context(), currentUser, resource, action) {
-			apperrors.ResponseWithError(c, apperrors.ForbiddenError("errors.forbidden", nil, map[string]interface{}{
-				"message":  "недостаточно прав для выполнения операции",
+		ctx := c.Request.Context()
+		allowedAction := resourcePolicy.Check(ctx, principal, resource, action)
+		if attrs == nil {
+			// Ресурс не найден: обработчик не вызывается, иначе он работал бы без проверки атрибутов
+			if !allowedAction {
+				abortForbidden(c, resource, action)
+				return
+			}
+			apperrors.ResponseWithError(c, apperrors.NotFoundError("errors.not_found", nil, map[string]interface{}{
 				"resource": resource,
-				"action":   action,
 			}))
 			c.Abort()
 			return
 		}
 
+		if !resourcePolicy.CheckResource(ctx, principal, resource, action, attrs) {
+			if allowedAction {
+				apperrors.ResponseWithError(c, apperrors.NotFoundError("errors.not_found", nil, map[string]interface{}{
+					"resource": resource,
+				}))
+			} else {
+				apperrors.ResponseWithError(c, forbiddenError(resource, action))
+			}
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// prepare берёт субъект из контекста и политику ресурса. При ошибке отвечает клиенту и прерывает запрос
+func (m *PolicyMiddleware) prepare(c *gin.Context, resource string) (policy.Principal, policy.Policy, bool) {
+	// Субъектом может быть пользователь или сервисный клиент
+	value, exists := c.Get("principal")
+	if !exists {
+		value, exists = c.Get("user")
+	}
+	if !exists {
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+		c.Abort()
+		return nil, nil, false
+	}
+
+	principal, ok := value.(policy.Principal)
+	if !ok {
+		apperrors.ResponseWithError(c, apperrors.InternalServerError("errors.internal", nil, nil))
+		c.Abort()
+		return nil, nil, false
+	}
+
+	resourcePolicy, err := m.policyFactory.ForResource(resource)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.ForbiddenError("errors.forbidden", err, map[string]interface{}{
+			"message":  "политика доступа не найдена",
+			"resource": resource,
+		}))
+		c.Abort()
+		return nil, nil, false
+	}
+
+	return principal, resourcePolicy, true
+}
+
+func abortForbidden(c *gin.Context, resource, action string) {
+	apperrors.ResponseWithError(c, forbiddenError(resource, action))
+	c.Abort()
+}
+
+func forbiddenError(resource, action string) error {
+	return apperrors.ForbiddenError("errors.forbidden", nil, map[string]interface{}{
+		"message":  "недостаточно прав для выполнения операции",
+		"resource": resource,
+		"action":   action,
+	})
+}
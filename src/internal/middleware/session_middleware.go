@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+)
+
+// WithoutRowSecurity отключает политики row-level security для публичных маршрутов (вход, регистрация,
+// обновление токенов): субъекта и организации у таких запросов ещё нет, а без параметров сессии
+// политики не возвращают строк. Подключается только к группам без аутентификации
+func WithoutRowSecurity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(db.WithoutRowSecurity(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -10,6 +10,7 @@ import (
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
 	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
 	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
@@ -48,8 +49,9 @@ func (h *AuthHandler) HandleRegister(c *gin.Context) {
 }
 
 type loginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	RememberMe bool   `json:"remember_me"`
 }
 
 func (h *AuthHandler) handleLogin(c *gin.Context) {
@@ -59,7 +61,16 @@ func (h *AuthHandler) handleLogin(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	dpopJKT, err := h.sp.DPoPVerifier().OptionalKey(c.Request)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("token.invalid_dpop_proof", err, nil))
+		return
+	}
+
+	resp, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, authModel.SessionOptions{
+		RememberMe: req.RememberMe,
+		DPoPJKT:    dpopJKT,
+	})
 	if err != nil {
 		apperrors.ResponseWithError(c, err)
 		return
@@ -91,8 +102,9 @@ func (h *AuthHandler) HandleLogout(c *gin.Context) {
 // Login обрабатывает запрос на вход в систему
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
+		Email      string `json:"email" binding:"required,email"`
+		Password   string `json:"password" binding:"required"`
+		RememberMe bool   `json:"remember_me"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -100,11 +112,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// DPoP необязателен: клиент, передавший proof, получает токены, привязанные к его ключу
+	dpopJKT, err := h.sp.DPoPVerifier().OptionalKey(c.Request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_dpop_proof"})
+		return
+	}
+
 	// Добавляем HTTP запрос в контекст для получения IP и User-Agent
 	ctx := c.Request.Context()
 	ctx = context.WithValue(ctx, middleware.RequestKey, c.Request)
 
-	authResponse, err := h.sp.AuthService(ctx).Login(ctx, req.Email, req.Password)
+	authResponse, err := h.sp.AuthService(ctx).Login(ctx, req.Email, req.Password, authModel.SessionOptions{
+		RememberMe: req.RememberMe,
+		DPoPJKT:    dpopJKT,
+	})
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверные учетные данные"})
 		return
@@ -135,17 +157,6 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusOK, authResponse)
 }
 
-// GetMe возвращает информацию о текущем пользователе
-func (h *AuthHandler) GetMe(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не найден в контексте"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"user": user})
-}
-
 // Logout обрабатывает запрос на выход из системы с отзывом токена
 func (h *AuthHandler) Logout(c *gin.Context) {
 	var req struct {
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// PasskeyHandler обрабатывает HTTP-запросы регистрации и входа по passkey
+type PasskeyHandler struct {
+	passkeyService service.PasskeyService
+	sp             provider.ServiceProvider
+}
+
+// NewPasskeyHandler создаёт новый экземпляр PasskeyHandler
+func NewPasskeyHandler(passkeyService service.PasskeyService, sp provider.ServiceProvider) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+		sp:             sp,
+	}
+}
+
+// LoginOptions выдает параметры для navigator.credentials.get()
+func (h *PasskeyHandler) LoginOptions(c *gin.Context) {
+	var req authModel.PasskeyLoginOptionsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apperrors.ResponseWithError(c, err)
+			return
+		}
+	}
+
+	options, err := h.passkeyService.BeginLogin(c.Request.Context(), req.Email)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, options)
+}
+
+// Login завершает вход по passkey и возвращает пару токенов
+func (h *PasskeyHandler) Login(c *gin.Context) {
+	var req authModel.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	// DPoP необязателен: клиент, передавший proof, получает токены, привязанные к его ключу
+	dpopJKT, err := h.sp.DPoPVerifier().OptionalKey(c.Request)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("token.invalid_dpop_proof", err, nil))
+		return
+	}
+
+	// Добавляем HTTP запрос в контекст для получения IP и User-Agent
+	ctx := context.WithValue(c.Request.Context(), middleware.RequestKey, c.Request)
+
+	authResponse, err := h.passkeyService.FinishLogin(ctx, &req, authModel.SessionOptions{
+		RememberMe: req.RememberMe,
+		DPoPJKT:    dpopJKT,
+	})
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authResponse)
+}
+
+// RegistrationOptions выдает параметры для navigator.credentials.create()
+func (h *PasskeyHandler) RegistrationOptions(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	options, err := h.passkeyService.BeginRegistration(c.Request.Context(), currentUser)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, options)
+}
+
+// Register сохраняет passkey, созданный аутентификатором
+func (h *PasskeyHandler) Register(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req authModel.PasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	credential, err := h.passkeyService.FinishRegistration(c.Request.Context(), currentUser, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.passkey.registered", credential)
+}
+
+// ListPasskeys возвращает passkey текущего пользователя
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.passkeyService.ListCredentials(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, credentials)
+}
+
+// RenamePasskey изменяет название passkey текущего пользователя
+func (h *PasskeyHandler) RenamePasskey(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parsePasskeyID(c)
+	if !ok {
+		return
+	}
+
+	var req authModel.RenamePasskeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	credential, err := h.passkeyService.RenameCredential(c.Request.Context(), currentUser.ID, id, req.Name)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.passkey.renamed", credential)
+}
+
+// DeletePasskey удаляет passkey текущего пользователя
+func (h *PasskeyHandler) DeletePasskey(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parsePasskeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.passkeyService.DeleteCredential(c.Request.Context(), currentUser.ID, id); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.passkey.deleted", nil)
+}
+
+// getCurrentUser извлекает аутентифицированного пользователя из контекста
+func getCurrentUser(c *gin.Context) (*userModel.User, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+		return nil, false
+	}
+
+	currentUser, ok := user.(*userModel.User)
+	if !ok {
+		apperrors.ResponseWithError(c, apperrors.InternalServerError("errors.internal", nil, nil))
+		return nil, false
+	}
+
+	return currentUser, true
+}
+
+func parsePasskeyID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// GetMe возвращает профиль текущего пользователя
+func (h *AuthHandler) GetMe(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", userModel.UserETag(currentUser.Version))
+	api.SuccessResponse(c, userModel.ProfileFromUser(currentUser))
+}
+
+// UpdateMe частично обновляет профиль текущего пользователя по JSON Merge Patch (RFC 7396).
+// If-Match необязателен: без него изменения применяются к последней версии
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != userModel.MergePatchContentType && contentType != gin.MIMEJSON {
+		apperrors.ResponseWithError(c, apperrors.NewAppError(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "user.invalid_patch", nil, map[string]any{
+			"content_type": contentType,
+			"expected":     userModel.MergePatchContentType,
+		}))
+		return
+	}
+
+	expectedVersion := 0
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		version, ok := userModel.ParseUserETag(ifMatch)
+		if !ok {
+			apperrors.ResponseWithError(c, apperrors.ConflictError("user.version_conflict", nil, map[string]any{
+				"if_match": ifMatch,
+			}))
+			return
+		}
+		expectedVersion = version
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.invalid_patch", err, nil))
+		return
+	}
+
+	patch, err := userModel.ParseProfileMergePatch(body)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.invalid_patch", err, map[string]any{
+			"message": err.Error(),
+		}))
+		return
+	}
+	if err := apperrors.ValidateRequest(patch); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	profile, err := h.sp.UserService(c.Request.Context()).UpdateOwnProfile(c.Request.Context(), currentUser.ID, expectedVersion, patch)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", userModel.UserETag(profile.Version))
+	api.SuccessResponse(c, profile)
+}
+
+// ChangeMyPassword изменяет пароль текущего пользователя
+func (h *AuthHandler) ChangeMyPassword(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		OldPassword string `json:"old_password" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=8,max=72"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	if err := h.sp.UserService(c.Request.Context()).ChangePassword(c.Request.Context(), currentUser.ID, req.OldPassword, req.NewPassword); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.user.password_changed", nil)
+}
+
+// DeleteMe отключает учётную запись текущего пользователя и завершает все его сессии
+func (h *AuthHandler) DeleteMe(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sp.UserService(c.Request.Context()).DeactivateAccount(c.Request.Context(), currentUser.ID, c.ClientIP()); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.user.deactivated", nil)
+}
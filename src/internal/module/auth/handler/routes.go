@@ -19,6 +19,31 @@ func (h *AuthHandler) RegisterProtectedRoutes(group *gin.RouterGroup) {
 	// Защищенные маршруты
 	group.POST("/logout", h.Logout)
 	group.GET("/me", h.GetMe)
+	group.PATCH("/me", h.UpdateMe)
+	group.POST("/me/password", h.ChangeMyPassword)
+	group.DELETE("/me", h.DeleteMe)
+}
+
+// RegisterPublicRoutes регистрирует маршруты входа по passkey
+func (h *PasskeyHandler) RegisterPublicRoutes(group *gin.RouterGroup) {
+	group.POST("/login/options", h.LoginOptions)
+	group.POST("/login", h.Login)
+}
+
+// RegisterProtectedRoutes регистрирует маршруты управления passkey текущего пользователя
+func (h *PasskeyHandler) RegisterProtectedRoutes(group *gin.RouterGroup) {
+	group.POST("/register/options", h.RegistrationOptions)
+	group.POST("/register", h.Register)
+	group.GET("", h.ListPasskeys)
+	group.PATCH("/:id", h.RenamePasskey)
+	group.DELETE("/:id", h.DeletePasskey)
+}
+
+// RegisterRoutes регистрирует маршруты SAML SSO; все они публичные
+func (h *SAMLHandler) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/metadata", h.Metadata)
+	group.GET("/login", h.Login)
+	group.POST("/acs", h.ACS)
 }
 
 // RefreshTokenEndpoint обрабатывает запрос на обновление токена
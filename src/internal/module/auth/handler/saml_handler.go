@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
+)
+
+// SAMLHandler обрабатывает HTTP-запросы входа через SAML IdP
+type SAMLHandler struct {
+	samlService service.SAMLService
+	sp          provider.ServiceProvider
+}
+
+// NewSAMLHandler создаёт новый экземпляр SAMLHandler
+func NewSAMLHandler(samlService service.SAMLService, sp provider.ServiceProvider) *SAMLHandler {
+	return &SAMLHandler{
+		samlService: samlService,
+		sp:          sp,
+	}
+}
+
+// Metadata отдает метаданные SP для настройки IdP
+func (h *SAMLHandler) Metadata(c *gin.Context) {
+	metadata, err := h.samlService.Metadata()
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// Login перенаправляет браузер на IdP, обслуживающий домен email пользователя
+func (h *SAMLHandler) Login(c *gin.Context) {
+	var req authModel.SAMLLoginRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	redirectURL, err := h.samlService.BeginLogin(c.Request.Context(), req.Email, req.RememberMe)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// ACS принимает ответ IdP (привязка HTTP-POST) и возвращает пару токенов
+func (h *SAMLHandler) ACS(c *gin.Context) {
+	var req authModel.SAMLACSRequest
+	if err := c.ShouldBind(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	// Добавляем HTTP запрос в контекст для получения IP и User-Agent
+	ctx := context.WithValue(c.Request.Context(), middleware.RequestKey, c.Request)
+
+	authResponse, err := h.samlService.FinishLogin(ctx, req.SAMLResponse)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, authResponse)
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/webauthn"
+)
+
+// Церемонии WebAuthn, для которых выдается challenge
+const (
+	CeremonyRegistration   = "registration"
+	CeremonyAuthentication = "authentication"
+)
+
+// PasskeyCredential passkey пользователя (таблица webauthn_credentials)
+type PasskeyCredential struct {
+	ID             uuid.UUID                `json:"id"`
+	UserID         uuid.UUID                `json:"userId"`
+	CredentialID   webauthn.URLEncodedBytes `json:"credentialId"`
+	PublicKey      []byte                   `json:"-"`
+	Algorithm      int64                    `json:"algorithm"`
+	SignCount      uint32                   `json:"-"`
+	Transports     []string                 `json:"transports"`
+	Name           string                   `json:"name"`
+	AAGUID         uuid.UUID                `json:"aaguid"`
+	BackupEligible bool                     `json:"backupEligible"`
+	BackupState    bool                     `json:"backupState"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	LastUsedAt     *time.Time               `json:"lastUsedAt,omitempty"`
+}
+
+// Descriptor ссылка на passkey для excludeCredentials и allowCredentials
+func (c *PasskeyCredential) Descriptor() webauthn.CredentialDescriptor {
+	return webauthn.CredentialDescriptor{
+		Type:       "public-key",
+		ID:         c.CredentialID,
+		Transports: c.Transports,
+	}
+}
+
+// WebAuthnChallenge выданный challenge церемонии регистрации или входа
+type WebAuthnChallenge struct {
+	Challenge []byte
+	// UserID пользователь церемонии; nil при входе без указания email
+	UserID    *uuid.UUID
+	Ceremony  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// PasskeyRegistrationRequest запрос на завершение регистрации passkey
+type PasskeyRegistrationRequest struct {
+	Name       string                        `json:"name" binding:"max=255"`
+	Credential webauthn.RegistrationResponse `json:"credential" binding:"required"`
+}
+
+// PasskeyLoginOptionsRequest запрос параметров входа.
+// Без email аутентификатор предлагает пользователю выбрать passkey сам
+type PasskeyLoginOptionsRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// PasskeyLoginRequest запрос на вход по passkey
+type PasskeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential" binding:"required"`
+	RememberMe bool                       `json:"remember_me"`
+}
+
+// RenamePasskeyRequest запрос на переименование passkey
+type RenamePasskeyRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
@@ -19,6 +19,11 @@ type RefreshToken struct {
 	RevokedByIP      string     `json:"revokedByIp,omitempty"`
 	ReplacedByToken  string     `json:"replacedByToken,omitempty"`
 	DeviceIdentifier string     `json:"deviceIdentifier,omitempty"`
+	SessionStartedAt time.Time  `json:"sessionStartedAt"`
+	SessionExpiresAt time.Time  `json:"sessionExpiresAt"`
+	RememberMe       bool       `json:"rememberMe"`
+	// DPoPJKT отпечаток ключа DPoP, к которому привязана сессия
+	DPoPJKT string `json:"-"`
 }
 
 // IsExpired проверяет, истек ли срок действия токена
@@ -26,9 +31,14 @@ func (rt *RefreshToken) IsExpired() bool {
 	return rt.ExpiresAt.Before(time.Now())
 }
 
-// IsActive проверяет, активен ли токен (не отозван и не истек)
+// IsSessionExpired проверяет, истек ли абсолютный срок жизни сессии
+func (rt *RefreshToken) IsSessionExpired() bool {
+	return rt.SessionExpiresAt.Before(time.Now())
+}
+
+// IsActive проверяет, активен ли токен (не отозван, не истек и сессия не завершена)
 func (rt *RefreshToken) IsActive() bool {
-	return !rt.Revoked && !rt.IsExpired()
+	return !rt.Revoked && !rt.IsExpired() && !rt.IsSessionExpired()
 }
 
 // Revoke отзывает токен
@@ -12,6 +12,10 @@ type AuthResponse struct {
 	TokenType       string    `json:"token_type"`
 	ExpiresIn       int64     `json:"expires_in"`
 	AccessExpiresAt time.Time `json:"accessExpiresAt,omitempty"`
+	// RefreshExpiresAt момент, после которого refresh token перестанет приниматься без ротации
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt,omitempty"`
+	// SessionExpiresAt абсолютный срок жизни сессии, не продлевается при ротации
+	SessionExpiresAt time.Time `json:"sessionExpiresAt,omitempty"`
 }
 
 type RegisterResponse struct {
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// SAMLRequest отправленный IdP AuthnRequest (таблица saml_requests)
+type SAMLRequest struct {
+	ID          string
+	IdPEntityID string
+	RememberMe  bool
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+// SAMLLoginRequest параметры начала входа через SAML
+type SAMLLoginRequest struct {
+	Email      string `form:"email" binding:"required,email"`
+	RememberMe bool   `form:"remember_me"`
+}
+
+// SAMLACSRequest ответ IdP, доставленный браузером по привязке HTTP-POST
+type SAMLACSRequest struct {
+	SAMLResponse string `form:"SAMLResponse" binding:"required"`
+	RelayState   string `form:"RelayState"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// SessionOptions параметры сессии, создаваемой при входе в систему
+type SessionOptions struct {
+	// RememberMe выбирает длинный профиль сессии вместо короткого
+	RememberMe bool
+	// DeviceIdentifier привязывает сессию к известной записи устройства.
+	// Если не задан, идентификатор вычисляется из HTTP-запроса
+	DeviceIdentifier string
+	// DPoPJKT отпечаток ключа DPoP клиента. Если задан, все токены сессии
+	// привязываются к этому ключу и без proof не принимаются
+	DPoPJKT string
+}
@@ -0,0 +1,310 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/repository"
+)
+
+type passkeyRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewPasskeyRepository создает новый экземпляр репозитория passkey
+func NewPasskeyRepository(sp provider.ServiceProvider, db db.DB) repository.PasskeyRepository {
+	return &passkeyRepository{
+		sp:   sp,
+		db:   db,
+		name: "PasskeyRepository",
+	}
+}
+
+const passkeyColumns = `id, user_id, credential_id, public_key, algorithm, sign_count, transports, name,
+			       aaguid, backup_eligible, backup_state, created_at, last_used_at`
+
+// Create сохраняет новый passkey
+func (r *passkeyRepository) Create(ctx context.Context, credential *model.PasskeyCredential) error {
+	const op = "PasskeyRepository.Create"
+	if credential == nil {
+		return apperrors.InternalServerError("passkey.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Create",
+		QueryRaw: `
+			INSERT INTO webauthn_credentials
+			(id, user_id, credential_id, public_key, algorithm, sign_count, transports, name,
+			 aaguid, backup_eligible, backup_state, created_at)
+			VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`,
+	}
+
+	if credential.ID == uuid.Nil {
+		credential.ID = uuid.New()
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		credential.ID,
+		credential.UserID,
+		[]byte(credential.CredentialID),
+		credential.PublicKey,
+		credential.Algorithm,
+		int64(credential.SignCount),
+		credential.Transports,
+		credential.Name,
+		credential.AAGUID,
+		credential.BackupEligible,
+		credential.BackupState,
+		credential.CreatedAt,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to create passkey", op))
+		return apperrors.InternalServerError("passkey.create_error", err, nil)
+	}
+
+	return nil
+}
+
+// FindByCredentialID находит passkey по идентификатору учетных данных аутентификатора
+func (r *passkeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*model.PasskeyCredential, error) {
+	const op = "PasskeyRepository.FindByCredentialID"
+
+	q := db.Query{
+		Name:     r.name + ".FindByCredentialID",
+		QueryRaw: `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE credential_id = $1`,
+	}
+
+	credential, err := r.scan(r.db.QueryRowContext(ctx, q, credentialID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get passkey", op))
+		return nil, apperrors.InternalServerError("passkey.get_error", err, nil)
+	}
+
+	return credential, nil
+}
+
+// FindByUserAndID находит passkey пользователя
+func (r *passkeyRepository) FindByUserAndID(ctx context.Context, userID, id uuid.UUID) (*model.PasskeyCredential, error) {
+	const op = "PasskeyRepository.FindByUserAndID"
+
+	q := db.Query{
+		Name:     r.name + ".FindByUserAndID",
+		QueryRaw: `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE id = $1 AND user_id = $2`,
+	}
+
+	credential, err := r.scan(r.db.QueryRowContext(ctx, q, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get passkey", op))
+		return nil, apperrors.InternalServerError("passkey.get_error", err, nil)
+	}
+
+	return credential, nil
+}
+
+// FindByUser возвращает все passkey пользователя
+func (r *passkeyRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.PasskeyCredential, error) {
+	const op = "PasskeyRepository.FindByUser"
+
+	q := db.Query{
+		Name:     r.name + ".FindByUser",
+		QueryRaw: `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at`,
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get passkeys", op))
+		return nil, apperrors.InternalServerError("passkey.get_error", err, nil)
+	}
+	defer rows.Close()
+
+	credentials := make([]*model.PasskeyCredential, 0)
+	for rows.Next() {
+		credential, err := r.scan(rows)
+		if err != nil {
+			r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to scan passkey", op))
+			return nil, apperrors.InternalServerError("passkey.scan_error", err, nil)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: rows error", op))
+		return nil, apperrors.InternalServerError("passkey.rows_error", err, nil)
+	}
+
+	return credentials, nil
+}
+
+// UpdateName переименовывает passkey
+func (r *passkeyRepository) UpdateName(ctx context.Context, id uuid.UUID, name string) error {
+	const op = "PasskeyRepository.UpdateName"
+
+	q := db.Query{
+		Name:     r.name + ".UpdateName",
+		QueryRaw: `UPDATE webauthn_credentials SET name = $1 WHERE id = $2`,
+	}
+
+	if _, err := r.db.ExecContext(ctx, q, name, id); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to rename passkey", op))
+		return apperrors.InternalServerError("passkey.update_error", err, nil)
+	}
+
+	return nil
+}
+
+// UpdateUsage сохраняет счетчик подписей и время последнего входа
+func (r *passkeyRepository) UpdateUsage(ctx context.Context, id uuid.UUID, signCount uint32, backupState bool, usedAt time.Time) error {
+	const op = "PasskeyRepository.UpdateUsage"
+
+	q := db.Query{
+		Name: r.name + ".UpdateUsage",
+		QueryRaw: `
+			UPDATE webauthn_credentials
+			SET sign_count = $1, backup_state = $2, last_used_at = $3
+			WHERE id = $4
+		`,
+	}
+
+	if _, err := r.db.ExecContext(ctx, q, int64(signCount), backupState, usedAt, id); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to update passkey usage", op))
+		return apperrors.InternalServerError("passkey.update_error", err, nil)
+	}
+
+	return nil
+}
+
+// Delete удаляет passkey пользователя
+func (r *passkeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	const op = "PasskeyRepository.Delete"
+
+	q := db.Query{
+		Name:     r.name + ".Delete",
+		QueryRaw: `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, id, userID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to delete passkey", op))
+		return false, apperrors.InternalServerError("passkey.delete_error", err, nil)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SaveChallenge сохраняет выданный challenge
+func (r *passkeyRepository) SaveChallenge(ctx context.Context, challenge *model.WebAuthnChallenge) error {
+	const op = "PasskeyRepository.SaveChallenge"
+
+	// Попутно вычищаем challenge, которые уже нельзя использовать
+	cleanup := db.Query{
+		Name:     r.name + ".DeleteExpiredChallenges",
+		QueryRaw: `DELETE FROM webauthn_challenges WHERE expires_at < $1`,
+	}
+	if _, err := r.db.ExecContext(ctx, cleanup, time.Now()); err != nil {
+		r.sp.Logger().WithError(err).Warn(fmt.Sprintf("%s: unable to delete expired challenges", op))
+	}
+
+	q := db.Query{
+		Name: r.name + ".SaveChallenge",
+		QueryRaw: `
+			INSERT INTO webauthn_challenges (challenge, user_id, ceremony, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		challenge.Challenge,
+		challenge.UserID,
+		challenge.Ceremony,
+		challenge.ExpiresAt,
+		challenge.CreatedAt,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to save challenge", op))
+		return apperrors.InternalServerError("passkey.challenge_error", err, nil)
+	}
+
+	return nil
+}
+
+// ConsumeChallenge удаляет и возвращает действующий challenge церемонии; nil, если его нет
+func (r *passkeyRepository) ConsumeChallenge(ctx context.Context, challenge []byte, ceremony string) (*model.WebAuthnChallenge, error) {
+	const op = "PasskeyRepository.ConsumeChallenge"
+
+	// Удаление и чтение одним запросом: challenge нельзя использовать дважды даже при параллельных запросах
+	q := db.Query{
+		Name: r.name + ".ConsumeChallenge",
+		QueryRaw: `
+			DELETE FROM webauthn_challenges
+			WHERE challenge = $1 AND ceremony = $2 AND expires_at > $3
+			RETURNING challenge, user_id, ceremony, expires_at, created_at
+		`,
+	}
+
+	var result model.WebAuthnChallenge
+	err := r.db.QueryRowContext(ctx, q, challenge, ceremony, time.Now()).Scan(
+		&result.Challenge,
+		&result.UserID,
+		&result.Ceremony,
+		&result.ExpiresAt,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to consume challenge", op))
+		return nil, apperrors.InternalServerError("passkey.challenge_error", err, nil)
+	}
+
+	return &result, nil
+}
+
+// scan считывает passkey из строки результата
+func (r *passkeyRepository) scan(row pgx.Row) (*model.PasskeyCredential, error) {
+	var credential model.PasskeyCredential
+	var credentialID []byte
+	var signCount int64
+
+	err := row.Scan(
+		&credential.ID,
+		&credential.UserID,
+		&credentialID,
+		&credential.PublicKey,
+		&credential.Algorithm,
+		&signCount,
+		&credential.Transports,
+		&credential.Name,
+		&credential.AAGUID,
+		&credential.BackupEligible,
+		&credential.BackupState,
+		&credential.CreatedAt,
+		&credential.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	credential.CredentialID = credentialID
+	credential.SignCount = uint32(signCount)
+
+	return &credential, nil
+}
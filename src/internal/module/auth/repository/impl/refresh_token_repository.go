@@ -41,9 +41,10 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *model.Refres
 		Name: r.name + ".Create",
 		QueryRaw: `
 			INSERT INTO refresh_tokens 
-			(id, user_id, token, expires_at, created_at, created_by_ip, device_identifier)
+			(id, user_id, token, expires_at, created_at, created_by_ip, device_identifier,
+			 session_started_at, session_expires_at, remember_me, dpop_jkt)
 			VALUES 
-			($1, $2, $3, $4, $5, $6, $7)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		`,
 	}
 
@@ -59,6 +60,10 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *model.Refres
 		token.CreatedAt,
 		token.CreatedByIP,
 		token.DeviceIdentifier,
+		token.SessionStartedAt,
+		token.SessionExpiresAt,
+		token.RememberMe,
+		token.DPoPJKT,
 	)
 	if err != nil {
 		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to create refresh token", op))
@@ -79,7 +84,8 @@ func (r *refreshTokenRepository) GetByToken(ctx context.Context, tokenStr string
 		Name: r.name + ".GetByToken",
 		QueryRaw: `
 			SELECT id, user_id, token, expires_at, revoked, created_at, created_by_ip,
-			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier
+			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier,
+			       session_started_at, session_expires_at, remember_me, dpop_jkt
 			FROM refresh_tokens
 			WHERE token = $1
 		`,
@@ -100,6 +106,10 @@ func (r *refreshTokenRepository) GetByToken(ctx context.Context, tokenStr string
 		&token.RevokedByIP,
 		&token.ReplacedByToken,
 		&token.DeviceIdentifier,
+		&token.SessionStartedAt,
+		&token.SessionExpiresAt,
+		&token.RememberMe,
+		&token.DPoPJKT,
 	)
 
 	if err != nil {
@@ -124,7 +134,8 @@ func (r *refreshTokenRepository) GetActiveByUserID(ctx context.Context, userID u
 		Name: r.name + ".GetActiveByUserID",
 		QueryRaw: `
 			SELECT id, user_id, token, expires_at, revoked, created_at, created_by_ip,
-			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier
+			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier,
+			       session_started_at, session_expires_at, remember_me, dpop_jkt
 			FROM refresh_tokens
 			WHERE user_id = $1 AND revoked = false AND expires_at > $2
 		`,
@@ -152,6 +163,10 @@ func (r *refreshTokenRepository) GetActiveByUserID(ctx context.Context, userID u
 			&token.RevokedByIP,
 			&token.ReplacedByToken,
 			&token.DeviceIdentifier,
+			&token.SessionStartedAt,
+			&token.SessionExpiresAt,
+			&token.RememberMe,
+			&token.DPoPJKT,
 		)
 		if err != nil {
 			r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to scan refresh token", op))
@@ -224,9 +239,11 @@ func (r *refreshTokenRepository) RevokeAllUserTokens(ctx context.Context, userID
 	return nil
 }
 
-// DeleteExpired удаляет все истекшие токены
+// DeleteExpired удаляет все истекшие токены. Очистка касается токенов всех пользователей,
+// поэтому политики row-level security для неё явно отключаются
 func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
 	const op = "RefreshTokenRepository.DeleteExpired"
+	ctx = db.WithoutRowSecurity(ctx)
 
 	q := db.Query{
 		Name: r.name + ".DeleteExpired",
@@ -332,7 +349,8 @@ func (r *refreshTokenRepository) GetByDeviceIdentifier(ctx context.Context, user
 		Name: r.name + ".GetByDeviceIdentifier",
 		QueryRaw: `
 			SELECT id, user_id, token, expires_at, revoked, created_at, created_by_ip,
-			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier
+			       revoked_at, revoked_by_ip, replaced_by_token, device_identifier,
+			       session_started_at, session_expires_at, remember_me, dpop_jkt
 			FROM refresh_tokens
 			WHERE user_id = $1 AND device_identifier = $2 AND revoked = false AND expires_at > $3
 			ORDER BY created_at DESC
@@ -355,6 +373,10 @@ func (r *refreshTokenRepository) GetByDeviceIdentifier(ctx context.Context, user
 		&token.RevokedByIP,
 		&token.ReplacedByToken,
 		&token.DeviceIdentifier,
+		&token.SessionStartedAt,
+		&token.SessionExpiresAt,
+		&token.RememberMe,
+		&token.DPoPJKT,
 	)
 
 	if err != nil {
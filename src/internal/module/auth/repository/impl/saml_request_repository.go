@@ -0,0 +1,98 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/repository"
+)
+
+type samlRequestRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewSAMLRequestRepository создает новый экземпляр репозитория запросов SAML
+func NewSAMLRequestRepository(sp provider.ServiceProvider, db db.DB) repository.SAMLRequestRepository {
+	return &samlRequestRepository{
+		sp:   sp,
+		db:   db,
+		name: "SAMLRequestRepository",
+	}
+}
+
+// Save сохраняет отправленный запрос
+func (r *samlRequestRepository) Save(ctx context.Context, request *model.SAMLRequest) error {
+	const op = "SAMLRequestRepository.Save"
+
+	// Попутно вычищаем запросы, на которые уже нельзя ответить
+	cleanup := db.Query{
+		Name:     r.name + ".DeleteExpired",
+		QueryRaw: `DELETE FROM saml_requests WHERE expires_at < $1`,
+	}
+	if _, err := r.db.ExecContext(ctx, cleanup, time.Now()); err != nil {
+		r.sp.Logger().WithError(err).Warn(fmt.Sprintf("%s: unable to delete expired requests", op))
+	}
+
+	q := db.Query{
+		Name: r.name + ".Save",
+		QueryRaw: `
+			INSERT INTO saml_requests (id, idp_entity_id, remember_me, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		request.ID,
+		request.IdPEntityID,
+		request.RememberMe,
+		request.ExpiresAt,
+		request.CreatedAt,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to save request", op))
+		return apperrors.InternalServerError("saml.request_error", err, nil)
+	}
+
+	return nil
+}
+
+// Consume удаляет и возвращает действующий запрос
+func (r *samlRequestRepository) Consume(ctx context.Context, id string) (*model.SAMLRequest, error) {
+	const op = "SAMLRequestRepository.Consume"
+
+	q := db.Query{
+		Name: r.name + ".Consume",
+		QueryRaw: `
+			DELETE FROM saml_requests
+			WHERE id = $1 AND expires_at > $2
+			RETURNING id, idp_entity_id, remember_me, expires_at, created_at
+		`,
+	}
+
+	var request model.SAMLRequest
+	err := r.db.QueryRowContext(ctx, q, id, time.Now()).Scan(
+		&request.ID,
+		&request.IdPEntityID,
+		&request.RememberMe,
+		&request.ExpiresAt,
+		&request.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to consume request", op))
+		return nil, apperrors.InternalServerError("saml.request_error", err, nil)
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+)
+
+// PasskeyRepository определяет интерфейс для операций с passkey и challenge WebAuthn
+type PasskeyRepository interface {
+	// Create сохраняет новый passkey
+	Create(ctx context.Context, credential *model.PasskeyCredential) error
+
+	// FindByCredentialID находит passkey по идентификатору учетных данных аутентификатора
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*model.PasskeyCredential, error)
+
+	// FindByUserAndID находит passkey пользователя
+	FindByUserAndID(ctx context.Context, userID, id uuid.UUID) (*model.PasskeyCredential, error)
+
+	// FindByUser возвращает все passkey пользователя
+	FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.PasskeyCredential, error)
+
+	// UpdateName переименовывает passkey
+	UpdateName(ctx context.Context, id uuid.UUID, name string) error
+
+	// UpdateUsage сохраняет счетчик подписей и время последнего входа
+	UpdateUsage(ctx context.Context, id uuid.UUID, signCount uint32, backupState bool, usedAt time.Time) error
+
+	// Delete удаляет passkey пользователя
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+
+	// SaveChallenge сохраняет выданный challenge
+	SaveChallenge(ctx context.Context, challenge *model.WebAuthnChallenge) error
+
+	// ConsumeChallenge удаляет и возвращает действующий challenge церемонии; nil, если его нет
+	ConsumeChallenge(ctx context.Context, challenge []byte, ceremony string) (*model.WebAuthnChallenge, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+)
+
+// SAMLRequestRepository определяет интерфейс для хранения ожидающих ответа AuthnRequest
+type SAMLRequestRepository interface {
+	// Save сохраняет отправленный запрос
+	Save(ctx context.Context, request *model.SAMLRequest) error
+
+	// Consume удаляет и возвращает действующий запрос; nil, если запроса нет или он истек.
+	// Повторная отправка того же ответа IdP поэтому отклоняется
+	Consume(ctx context.Context, id string) (*model.SAMLRequest, error)
+}
@@ -39,10 +39,10 @@ func (s *authService) Register(ctx context.Context, req *userModel.CreateUserReq
 		return nil, err
 	}
 
-	return s.generateToken(ctx, createdUser, getClientIP(ctx))
+	return s.generateToken(ctx, createdUser, getClientIP(ctx), s.newSession(authModel.SessionOptions{}))
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (*authModel.AuthResponse, error) {
+func (s *authService) Login(ctx context.Context, email, password string, opts authModel.SessionOptions) (*authModel.AuthResponse, error) {
 	userService := s.sp.UserService(ctx)
 	user, err := userService.ValidateCredentials(ctx, email, password)
 	if err != nil {
@@ -53,10 +53,78 @@ func (s *authService) Login(ctx context.Context, email, password string) (*authM
 	// или оставить их активными - зависит от требований безопасности
 	// s.RevokeAllUserTokens(ctx, user.ID, getClientIP(ctx))
 
-	return s.generateToken(ctx, user, getClientIP(ctx))
+	return s.generateToken(ctx, user, getClientIP(ctx), s.newSession(opts))
 }
 
-func (s *authService) generateToken(ctx context.Context, user *userModel.User, ipAddress string) (*authModel.AuthResponse, error) {
+// IssueTokens выдает пару токенов пользователю, аутентифицированному вне Login
+func (s *authService) IssueTokens(ctx context.Context, user *userModel.User, opts authModel.SessionOptions) (*authModel.AuthResponse, error) {
+	if user == nil {
+		return nil, apperrors.InternalServerError("user.is_nil", nil, nil)
+	}
+
+	return s.generateToken(ctx, user, getClientIP(ctx), s.newSession(opts))
+}
+
+// session описывает временные рамки сессии, к которой привязана цепочка refresh токенов
+type session struct {
+	startedAt   time.Time
+	expiresAt   time.Time
+	idleTimeout time.Duration
+	rememberMe  bool
+	deviceID    string
+	dpopJKT     string
+}
+
+// refreshExpiresAt вычисляет срок действия очередного refresh токена:
+// скользящее окно бездействия, но не дальше абсолютного срока жизни сессии
+func (sess session) refreshExpiresAt(now time.Time) time.Time {
+	expiresAt := now.Add(sess.idleTimeout)
+	if expiresAt.After(sess.expiresAt) {
+		return sess.expiresAt
+	}
+	return expiresAt
+}
+
+// sessionProfile возвращает время бездействия и абсолютный срок жизни для профиля сессии
+func (s *authService) sessionProfile(rememberMe bool) (idleTimeout, maxLifetime time.Duration) {
+	if rememberMe {
+		return time.Duration(s.cfg.RememberMeIdleTimeoutHours()) * time.Hour,
+			time.Duration(s.cfg.RememberMeMaxLifetimeHours()) * time.Hour
+	}
+	return time.Duration(s.cfg.RefreshTokenExpiryHours()) * time.Hour,
+		time.Duration(s.cfg.SessionMaxLifetimeHours()) * time.Hour
+}
+
+// newSession открывает новую сессию с профилем, выбранным при входе
+func (s *authService) newSession(opts authModel.SessionOptions) session {
+	idleTimeout, maxLifetime := s.sessionProfile(opts.RememberMe)
+	now := time.Now()
+
+	return session{
+		startedAt:   now,
+		expiresAt:   now.Add(maxLifetime),
+		idleTimeout: idleTimeout,
+		rememberMe:  opts.RememberMe,
+		deviceID:    opts.DeviceIdentifier,
+		dpopJKT:     opts.DPoPJKT,
+	}
+}
+
+// continueSession восстанавливает сессию из ротируемого refresh токена
+func (s *authService) continueSession(token *authModel.RefreshToken) session {
+	idleTimeout, _ := s.sessionProfile(token.RememberMe)
+
+	return session{
+		startedAt:   token.SessionStartedAt,
+		expiresAt:   token.SessionExpiresAt,
+		idleTimeout: idleTimeout,
+		rememberMe:  token.RememberMe,
+		deviceID:    token.DeviceIdentifier,
+		dpopJKT:     token.DPoPJKT,
+	}
+}
+
+func (s *authService) generateToken(ctx context.Context, user *userModel.User, ipAddress string, sess session) (*authModel.AuthResponse, error) {
 	// Вычисляем время истечения токена
 	expiresAt := time.Now().Add(time.Duration(s.cfg.AccessTokenExpiryMinutes()) * time.Minute)
 
@@ -90,16 +158,22 @@ func (s *authService) generateToken(ctx context.Context, user *userModel.User, i
 	}
 
 	// Генерируем access token
-	accessToken, err := s.jwtManager.GenerateToken(user.ID.String(), roleNames, permissionNames)
+	tokenOptions := []pkgJwt.TokenOption{pkgJwt.WithDPoPKey(sess.dpopJKT)}
+	if user.OrganizationID != nil {
+		tokenOptions = append(tokenOptions, pkgJwt.WithOrganization(user.OrganizationID.String()))
+	}
+	accessToken, err := s.jwtManager.GenerateToken(user.ID.String(), roleNames, permissionNames, tokenOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	// Генерируем refresh token (с более длительным сроком действия)
-	refreshExpiresAt := time.Now().Add(time.Duration(s.cfg.RefreshTokenExpiryHours()) * time.Hour)
+	// Генерируем refresh token: срок действия сдвигается при каждой ротации,
+	// но не выходит за абсолютный срок жизни сессии
+	refreshExpiresAt := sess.refreshExpiresAt(time.Now())
 	refreshClaims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"type":    "refresh",
+		"jti":     uuid.New().String(),
 		"exp":     refreshExpiresAt.Unix(),
 	}
 
@@ -111,24 +185,35 @@ func (s *authService) generateToken(ctx context.Context, user *userModel.User, i
 	}
 
 	// Сохраняем refresh token в базу данных
-	if err := s.saveRefreshToken(ctx, user.ID, refreshTokenString, refreshExpiresAt, ipAddress); err != nil {
+	if err := s.saveRefreshToken(ctx, user.ID, refreshTokenString, refreshExpiresAt, ipAddress, sess); err != nil {
 		s.sp.Logger().WithError(err).Error("Failed to save refresh token to database")
 		return nil, fmt.Errorf("failed to save refresh token: %w", err)
 	}
 
+	// Привязанный к ключу токен предъявляется со схемой DPoP (RFC 9449, раздел 5)
+	tokenType := "Bearer"
+	if sess.dpopJKT != "" {
+		tokenType = "DPoP"
+	}
+
 	return &authModel.AuthResponse{
-		Token:           accessToken,
-		RefreshToken:    refreshTokenString,
-		TokenType:       "Bearer",
-		ExpiresIn:       int64(s.cfg.AccessTokenExpiryMinutes() * 60), // в секундах
-		AccessExpiresAt: expiresAt,
+		Token:            accessToken,
+		RefreshToken:     refreshTokenString,
+		TokenType:        tokenType,
+		ExpiresIn:        int64(s.cfg.AccessTokenExpiryMinutes() * 60), // в секундах
+		AccessExpiresAt:  expiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+		SessionExpiresAt: sess.expiresAt,
 	}, nil
 }
 
 // saveRefreshToken сохраняет refresh token в базу данных
-func (s *authService) saveRefreshToken(ctx context.Context, userID uuid.UUID, tokenString string, expiresAt time.Time, ipAddress string) error {
+func (s *authService) saveRefreshToken(ctx context.Context, userID uuid.UUID, tokenString string, expiresAt time.Time, ipAddress string, sess session) error {
 	const maxTokensPerUser = 3
-	deviceID := getDeviceIdentifier(ctx)
+	deviceID := sess.deviceID
+	if deviceID == "" {
+		deviceID = getDeviceIdentifier(ctx)
+	}
 
 	// Получаем репозиторий
 	tokenRepository := s.sp.RefreshTokenRepository(ctx)
@@ -162,6 +247,10 @@ func (s *authService) saveRefreshToken(ctx context.Context, userID uuid.UUID, to
 		CreatedAt:        time.Now(),
 		CreatedByIP:      ipAddress,
 		DeviceIdentifier: deviceID,
+		SessionStartedAt: sess.startedAt,
+		SessionExpiresAt: sess.expiresAt,
+		RememberMe:       sess.rememberMe,
+		DPoPJKT:          sess.dpopJKT,
 	}
 
 	return tokenRepository.Create(ctx, refreshToken)
@@ -186,7 +275,7 @@ func getDeviceIdentifier(ctx context.Context) string {
 }
 
 // RefreshToken обновляет access token используя refresh token
-func (s *authService) RefreshToken(ctx context.Context, refreshTokenString string) (*authModel.AuthResponse, error) {
+func (s *authService) RefreshToken(ctx context.Context, refreshTokenString string, dpopJKT string) (*authModel.AuthResponse, error) {
 	const op = "AuthService.RefreshToken"
 
 	// Проверяем токен в базе данных
@@ -201,11 +290,21 @@ func (s *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 		return nil, apperrors.UnauthorizedError("token.not_found", errors.New("refresh token not found"), nil)
 	}
 
+	// Абсолютный срок жизни сессии не продлевается ротацией
+	if storedToken.IsSessionExpired() {
+		return nil, apperrors.UnauthorizedError("token.session_expired", errors.New("session lifetime exceeded"), nil)
+	}
+
 	// Проверяем, что токен активен
 	if !storedToken.IsActive() {
 		return nil, apperrors.UnauthorizedError("token.inactive", errors.New("refresh token is inactive"), nil)
 	}
 
+	// Refresh токен сессии, привязанной к ключу DPoP, без proof этим ключом бесполезен
+	if storedToken.DPoPJKT != "" && storedToken.DPoPJKT != dpopJKT {
+		return nil, apperrors.UnauthorizedError("token.dpop_key_mismatch", errors.New("dpop proof key does not match session"), nil)
+	}
+
 	// Получаем пользователя
 	user, err := s.sp.UserService(ctx).GetByID(ctx, storedToken.UserID)
 	if err != nil {
@@ -218,11 +317,17 @@ func (s *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 			"id": storedToken.UserID.String(),
 		})
 	}
+	if !user.Active {
+		return nil, apperrors.UnauthorizedError("user.inactive", nil, nil)
+	}
+	if user.Suspended {
+		return nil, apperrors.UnauthorizedError("user.suspended", nil, nil)
+	}
 
 	// Отзываем текущий токен
 	ipAddress := getClientIP(ctx)
-	// Генерируем новые токены
-	authResponse, err := s.generateToken(ctx, user, ipAddress)
+	// Генерируем новые токены в рамках той же сессии
+	authResponse, err := s.generateToken(ctx, user, ipAddress, s.continueSession(storedToken))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/webauthn"
+)
+
+// defaultPasskeyName имя passkey, если пользователь его не задал
+const defaultPasskeyName = "Passkey"
+
+type passkeyService struct {
+	cfg config.WebAuthnConfig
+	sp  provider.ServiceProvider
+	rp  *webauthn.RelyingParty
+}
+
+func NewPasskeyService(cfg config.WebAuthnConfig, sp provider.ServiceProvider) service.PasskeyService {
+	return &passkeyService{
+		cfg: cfg,
+		sp:  sp,
+		rp: webauthn.New(webauthn.Config{
+			RPID:                    cfg.RPID(),
+			RPName:                  cfg.RPName(),
+			Origins:                 cfg.Origins(),
+			Timeout:                 time.Duration(cfg.ChallengeTTLSeconds()) * time.Second,
+			RequireUserVerification: cfg.RequireUserVerification(),
+		}),
+	}
+}
+
+// BeginRegistration выдает challenge регистрации нового passkey
+func (s *passkeyService) BeginRegistration(ctx context.Context, user *userModel.User) (*webauthn.CreationOptions, error) {
+	if user == nil {
+		return nil, apperrors.InternalServerError("user.is_nil", nil, nil)
+	}
+
+	credentials, err := s.sp.PasskeyRepository(ctx).FindByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		exclude = append(exclude, credential.Descriptor())
+	}
+
+	challenge, err := s.issueChallenge(ctx, &user.ID, authModel.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if displayName == "" {
+		displayName = user.Email
+	}
+
+	return s.rp.CreationOptions(challenge, webauthn.UserEntity{
+		ID:          userHandle(user.ID),
+		Name:        user.Email,
+		DisplayName: displayName,
+	}, exclude), nil
+}
+
+// FinishRegistration проверяет ответ аутентификатора и сохраняет passkey
+func (s *passkeyService) FinishRegistration(ctx context.Context, user *userModel.User, req *authModel.PasskeyRegistrationRequest) (*authModel.PasskeyCredential, error) {
+	if user == nil {
+		return nil, apperrors.InternalServerError("user.is_nil", nil, nil)
+	}
+
+	challenge, err := s.consumeChallenge(ctx, req.Credential.Response.ClientDataJSON, authModel.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UserID == nil || *challenge.UserID != user.ID {
+		return nil, apperrors.BadRequestError("passkey.challenge_invalid", nil, nil)
+	}
+
+	verified, err := s.rp.VerifyRegistration(challenge.Challenge, &req.Credential)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrVerification) {
+			return nil, apperrors.BadRequestError("passkey.verification_failed", err, nil)
+		}
+		return nil, apperrors.InternalServerError("passkey.verification_failed", err, nil)
+	}
+
+	repo := s.sp.PasskeyRepository(ctx)
+	existing, err := repo.FindByCredentialID(ctx, verified.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.ConflictError("passkey.already_registered", nil, nil)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	aaguid, err := uuid.FromBytes(verified.AAGUID)
+	if err != nil {
+		aaguid = uuid.Nil
+	}
+
+	transports := verified.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+
+	credential := &authModel.PasskeyCredential{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		CredentialID:   verified.ID,
+		PublicKey:      verified.PublicKey,
+		Algorithm:      verified.Algorithm,
+		SignCount:      verified.SignCount,
+		Transports:     transports,
+		Name:           name,
+		AAGUID:         aaguid,
+		BackupEligible: verified.BackupEligible,
+		BackupState:    verified.BackupState,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := repo.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// BeginLogin выдает challenge входа.
+// Для неизвестного email возвращается пустой список passkey, чтобы ответ не выдавал наличие аккаунта
+func (s *passkeyService) BeginLogin(ctx context.Context, email string) (*webauthn.RequestOptions, error) {
+	var userID *uuid.UUID
+	allow := make([]webauthn.CredentialDescriptor, 0)
+
+	if email != "" {
+		user, err := s.sp.UserService(ctx).GetByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+
+		if user != nil {
+			credentials, err := s.sp.PasskeyRepository(ctx).FindByUser(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, credential := range credentials {
+				allow = append(allow, credential.Descriptor())
+			}
+			userID = &user.ID
+		}
+	}
+
+	challenge, err := s.issueChallenge(ctx, userID, authModel.CeremonyAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rp.RequestOptions(challenge, allow), nil
+}
+
+// FinishLogin проверяет подпись аутентификатора и выдает пару токенов
+func (s *passkeyService) FinishLogin(ctx context.Context, req *authModel.PasskeyLoginRequest, opts authModel.SessionOptions) (*authModel.AuthResponse, error) {
+	challenge, err := s.consumeChallenge(ctx, req.Credential.Response.ClientDataJSON, authModel.CeremonyAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := s.sp.PasskeyRepository(ctx)
+	credential, err := repo.FindByCredentialID(ctx, req.Credential.RawID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, apperrors.UnauthorizedError("passkey.verification_failed", nil, nil)
+	}
+
+	// userHandle обязателен для discoverable credentials и должен указывать на владельца passkey
+	if len(req.Credential.Response.UserHandle) > 0 &&
+		!bytes.Equal(req.Credential.Response.UserHandle, userHandle(credential.UserID)) {
+		return nil, apperrors.UnauthorizedError("passkey.verification_failed", nil, nil)
+	}
+	if challenge.UserID != nil && *challenge.UserID != credential.UserID {
+		return nil, apperrors.UnauthorizedError("passkey.verification_failed", nil, nil)
+	}
+
+	result, err := s.rp.VerifyAssertion(challenge.Challenge, credential.PublicKey, credential.SignCount, &req.Credential)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrVerification) {
+			s.sp.Logger().WithError(err).Warn("passkey assertion rejected")
+			return nil, apperrors.UnauthorizedError("passkey.verification_failed", err, nil)
+		}
+		return nil, apperrors.InternalServerError("passkey.verification_failed", err, nil)
+	}
+
+	user, err := s.sp.UserService(ctx).GetByID(ctx, credential.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Active || user.Suspended {
+		return nil, apperrors.UnauthorizedError("passkey.verification_failed", nil, nil)
+	}
+
+	if err := repo.UpdateUsage(ctx, credential.ID, result.SignCount, result.BackupState, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.sp.AuthService(ctx).IssueTokens(ctx, user, opts)
+}
+
+// ListCredentials возвращает passkey пользователя
+func (s *passkeyService) ListCredentials(ctx context.Context, userID uuid.UUID) ([]*authModel.PasskeyCredential, error) {
+	return s.sp.PasskeyRepository(ctx).FindByUser(ctx, userID)
+}
+
+// RenameCredential переименовывает passkey пользователя
+func (s *passkeyService) RenameCredential(ctx context.Context, userID, id uuid.UUID, name string) (*authModel.PasskeyCredential, error) {
+	repo := s.sp.PasskeyRepository(ctx)
+
+	credential, err := repo.FindByUserAndID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, apperrors.NotFoundError("passkey.not_found", nil, nil)
+	}
+
+	if err := repo.UpdateName(ctx, id, name); err != nil {
+		return nil, err
+	}
+	credential.Name = name
+
+	return credential, nil
+}
+
+// DeleteCredential удаляет passkey пользователя
+func (s *passkeyService) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	deleted, err := s.sp.PasskeyRepository(ctx).Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperrors.NotFoundError("passkey.not_found", nil, nil)
+	}
+	return nil
+}
+
+// issueChallenge генерирует и сохраняет challenge церемонии
+func (s *passkeyService) issueChallenge(ctx context.Context, userID *uuid.UUID, ceremony string) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, apperrors.InternalServerError("passkey.challenge_error", err, nil)
+	}
+
+	now := time.Now()
+	err = s.sp.PasskeyRepository(ctx).SaveChallenge(ctx, &authModel.WebAuthnChallenge{
+		Challenge: challenge,
+		UserID:    userID,
+		Ceremony:  ceremony,
+		ExpiresAt: now.Add(time.Duration(s.cfg.ChallengeTTLSeconds()) * time.Second),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// consumeChallenge находит по clientDataJSON выданный challenge и погашает его
+func (s *passkeyService) consumeChallenge(ctx context.Context, clientDataJSON []byte, ceremony string) (*authModel.WebAuthnChallenge, error) {
+	raw, err := webauthn.ChallengeFromClientData(clientDataJSON)
+	if err != nil {
+		return nil, apperrors.BadRequestError("passkey.challenge_invalid", err, nil)
+	}
+
+	challenge, err := s.sp.PasskeyRepository(ctx).ConsumeChallenge(ctx, raw, ceremony)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, apperrors.BadRequestError("passkey.challenge_invalid", nil, nil)
+	}
+
+	return challenge, nil
+}
+
+// userHandle идентификатор пользователя для аутентификатора: байты UUID без персональных данных
+func userHandle(id uuid.UUID) []byte {
+	return id[:]
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/auth/service"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/saml"
+)
+
+type samlService struct {
+	cfg  config.SAMLConfig
+	sp   provider.ServiceProvider
+	saml *saml.ServiceProvider
+}
+
+func NewSAMLService(cfg config.SAMLConfig, sp provider.ServiceProvider) service.SAMLService {
+	return &samlService{
+		cfg: cfg,
+		sp:  sp,
+		saml: &saml.ServiceProvider{
+			EntityID:  cfg.EntityID(),
+			ACSURL:    cfg.ACSURL(),
+			ClockSkew: time.Duration(cfg.ClockSkewSeconds()) * time.Second,
+		},
+	}
+}
+
+// Metadata возвращает метаданные SP
+func (s *samlService) Metadata() ([]byte, error) {
+	metadata, err := s.saml.Metadata()
+	if err != nil {
+		return nil, apperrors.InternalServerError("saml.metadata_error", err, nil)
+	}
+	return metadata, nil
+}
+
+// BeginLogin сохраняет AuthnRequest и возвращает адрес перенаправления на IdP
+func (s *samlService) BeginLogin(ctx context.Context, email string, rememberMe bool) (string, error) {
+	idp, ok := s.cfg.IdentityProviderByDomain(emailDomain(email))
+	if !ok {
+		return "", apperrors.NotFoundError("saml.idp_not_found", nil, nil)
+	}
+
+	requestID, err := saml.NewRequestID()
+	if err != nil {
+		return "", apperrors.InternalServerError("saml.request_error", err, nil)
+	}
+
+	redirectURL, err := s.saml.RedirectURL(idp.IdentityProvider(), requestID, "")
+	if err != nil {
+		return "", apperrors.InternalServerError("saml.request_error", err, nil)
+	}
+
+	now := time.Now()
+	err = s.sp.SAMLRequestRepository(ctx).Save(ctx, &authModel.SAMLRequest{
+		ID:          requestID,
+		IdPEntityID: idp.EntityID,
+		RememberMe:  rememberMe,
+		ExpiresAt:   now.Add(time.Duration(s.cfg.RequestTTLSeconds()) * time.Second),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return redirectURL, nil
+}
+
+// FinishLogin проверяет ответ IdP и выдает пару токенов
+func (s *samlService) FinishLogin(ctx context.Context, samlResponse string) (*authModel.AuthResponse, error) {
+	response, err := saml.ParseResponse(samlResponse)
+	if err != nil {
+		return nil, apperrors.BadRequestError("saml.invalid_response", err, nil)
+	}
+
+	// Ответ принимается только на выданный нами и еще не использованный запрос
+	request, err := s.sp.SAMLRequestRepository(ctx).Consume(ctx, response.InResponseTo)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, apperrors.BadRequestError("saml.request_not_found", nil, nil)
+	}
+
+	idp, ok := s.cfg.IdentityProviderByEntityID(request.IdPEntityID)
+	if !ok {
+		return nil, apperrors.UnauthorizedError("saml.idp_not_found", nil, nil)
+	}
+
+	assertion, err := s.saml.ValidateResponse(response, idp.IdentityProvider(), request.ID)
+	if err != nil {
+		if errors.Is(err, saml.ErrInvalidResponse) {
+			s.sp.Logger().WithError(err).Warn("saml response rejected")
+			return nil, apperrors.UnauthorizedError("saml.invalid_response", err, nil)
+		}
+		return nil, apperrors.InternalServerError("saml.invalid_response", err, nil)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(assertion.NameID))
+	if idp.Attributes.Email != "" {
+		email = strings.ToLower(strings.TrimSpace(assertion.Attribute(idp.Attributes.Email)))
+	}
+	// IdP вправе удостоверять только пользователей своих доменов
+	if email == "" || !slices.Contains(idp.Domains, emailDomain(email)) {
+		return nil, apperrors.ForbiddenError("saml.domain_mismatch", nil, nil)
+	}
+
+	user, err := s.syncUser(ctx, idp, assertion, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sp.AuthService(ctx).IssueTokens(ctx, user, authModel.SessionOptions{
+		RememberMe: request.RememberMe,
+	})
+}
+
+// syncUser находит пользователя по email или создает его (JIT) и переносит атрибуты IdP
+func (s *samlService) syncUser(ctx context.Context, idp *config.SAMLIdentityProvider, assertion *saml.Assertion, email string) (*userModel.User, error) {
+	userService := s.sp.UserService(ctx)
+
+	availableRoles, err := userService.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roleIDs := make(map[string]int, len(availableRoles))
+	for _, role := range availableRoles {
+		roleIDs[role.Name] = role.ID
+	}
+
+	roles := mappedRoles(idp, assertion)
+	firstName := assertion.Attribute(idp.Attributes.FirstName)
+	lastName := assertion.Attribute(idp.Attributes.LastName)
+
+	user, err := userService.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		if !idp.JITProvisioning {
+			return nil, apperrors.UnauthorizedError("saml.user_not_provisioned", nil, nil)
+		}
+		return s.provisionUser(ctx, email, firstName, lastName, append(roles, idp.DefaultRoles...), roleIDs)
+	}
+
+	if !user.Active {
+		return nil, apperrors.UnauthorizedError("saml.user_inactive", nil, nil)
+	}
+	if user.Suspended {
+		return nil, apperrors.UnauthorizedError("user.suspended", nil, nil)
+	}
+
+	if (firstName != "" && firstName != user.FirstName) || (lastName != "" && lastName != user.LastName) {
+		if firstName != "" {
+			user.FirstName = firstName
+		}
+		if lastName != "" {
+			user.LastName = lastName
+		}
+		if err := userService.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	// Роли из IdP только добавляются: роли, выданные в приложении вручную, не снимаются
+	for _, roleName := range roles {
+		roleID, exists := roleIDs[roleName]
+		if !exists || user.HasRole(roleName) {
+			continue
+		}
+		if err := userService.AssignRole(ctx, user.ID, roleID); err != nil {
+			return nil, err
+		}
+	}
+
+	return userService.GetByID(ctx, user.ID)
+}
+
+// provisionUser создает пользователя при первом входе через IdP
+func (s *samlService) provisionUser(ctx context.Context, email, firstName, lastName string, roles []string, roleIDs map[string]int) (*userModel.User, error) {
+	// Пароль случайный и никому не известен: пользователь входит только через IdP
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, apperrors.InternalServerError("saml.provisioning_error", err, nil)
+	}
+
+	req := &userModel.CreateUserRequest{
+		Email:                email,
+		FirstName:            firstName,
+		LastName:             lastName,
+		Password:             hex.EncodeToString(password),
+		PasswordConfirmation: hex.EncodeToString(password),
+		Active:               1,
+	}
+	for _, roleName := range roles {
+		if _, exists := roleIDs[roleName]; !exists {
+			continue
+		}
+		if slices.ContainsFunc(req.Roles, func(r userModel.RoleRequest) bool { return r.Name == roleName }) {
+			continue
+		}
+		req.Roles = append(req.Roles, userModel.RoleRequest{Name: roleName})
+	}
+
+	userService := s.sp.UserService(ctx)
+	user, err := userService.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email подтвержден IdP
+	if err := userService.ConfirmEmail(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.sp.Logger().WithField("user_id", user.ID).Info("user provisioned via saml")
+
+	return userService.GetByID(ctx, user.ID)
+}
+
+// mappedRoles переводит группы из утверждения в роли приложения
+func mappedRoles(idp *config.SAMLIdentityProvider, assertion *saml.Assertion) []string {
+	if idp.Attributes.Roles == "" {
+		return nil
+	}
+
+	var roles []string
+	for _, group := range assertion.Attributes[idp.Attributes.Roles] {
+		if role, ok := idp.RoleMapping[group]; ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// emailDomain возвращает домен email в нижнем регистре
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
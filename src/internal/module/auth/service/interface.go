@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
 	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/webauthn"
 )
 
 // AuthService defines the interface for authentication operations
@@ -13,11 +14,18 @@ type AuthService interface {
 	// Register creates a new user account and returns authentication response
 	Register(ctx context.Context, req *userModel.CreateUserRequest) (*authModel.AuthResponse, error)
 
-	// Login authenticates a user and returns authentication response
-	Login(ctx context.Context, email, password string) (*authModel.AuthResponse, error)
+	// Login authenticates a user and returns authentication response.
+	// opts selects the session profile (short or "remember me")
+	Login(ctx context.Context, email, password string, opts authModel.SessionOptions) (*authModel.AuthResponse, error)
 
-	// RefreshToken refreshes an access token using a refresh token
-	RefreshToken(ctx context.Context, refreshToken string) (*authModel.AuthResponse, error)
+	// IssueTokens issues an access/refresh pair for an already authenticated user,
+	// e.g. after an approved OAuth2 device authorization
+	IssueTokens(ctx context.Context, user *userModel.User, opts authModel.SessionOptions) (*authModel.AuthResponse, error)
+
+	// RefreshToken refreshes an access token using a refresh token.
+	// The idle window slides on every rotation, the absolute session lifetime does not.
+	// dpopJKT is the key thumbprint of the presented DPoP proof, empty if none
+	RefreshToken(ctx context.Context, refreshToken string, dpopJKT string) (*authModel.AuthResponse, error)
 
 	// RevokeToken отзывает указанный refresh токен
 	RevokeToken(ctx context.Context, tokenStr string, ipAddress string) error
@@ -28,3 +36,42 @@ type AuthService interface {
 	// GetRefreshTokens возвращает все активные токены пользователя
 	GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]authModel.RefreshToken, error)
 }
+
+// PasskeyService defines the interface for passkey (WebAuthn) registration and login
+type PasskeyService interface {
+	// BeginRegistration issues a registration challenge for the current user
+	BeginRegistration(ctx context.Context, user *userModel.User) (*webauthn.CreationOptions, error)
+
+	// FinishRegistration verifies the authenticator response and stores the new passkey
+	FinishRegistration(ctx context.Context, user *userModel.User, req *authModel.PasskeyRegistrationRequest) (*authModel.PasskeyCredential, error)
+
+	// BeginLogin issues a login challenge. With an empty email the authenticator
+	// offers the user's discoverable passkeys
+	BeginLogin(ctx context.Context, email string) (*webauthn.RequestOptions, error)
+
+	// FinishLogin verifies the assertion and returns the standard authentication response
+	FinishLogin(ctx context.Context, req *authModel.PasskeyLoginRequest, opts authModel.SessionOptions) (*authModel.AuthResponse, error)
+
+	// ListCredentials returns the passkeys of the user
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]*authModel.PasskeyCredential, error)
+
+	// RenameCredential changes the friendly name of the user's passkey
+	RenameCredential(ctx context.Context, userID, id uuid.UUID, name string) (*authModel.PasskeyCredential, error)
+
+	// DeleteCredential removes the user's passkey
+	DeleteCredential(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// SAMLService defines the interface for SP-initiated SAML 2.0 single sign-on
+type SAMLService interface {
+	// Metadata returns the SP metadata document for registering the app at an IdP
+	Metadata() ([]byte, error)
+
+	// BeginLogin selects the IdP by the email domain and returns the URL
+	// the browser has to be redirected to
+	BeginLogin(ctx context.Context, email string, rememberMe bool) (string, error)
+
+	// FinishLogin validates the IdP response, provisions or updates the user
+	// and returns the standard authentication response
+	FinishLogin(ctx context.Context, samlResponse string) (*authModel.AuthResponse, error)
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
+)
+
+// ClientHandler обрабатывает HTTP-запросы управления OAuth2 клиентами
+type ClientHandler struct {
+	clientService service.ClientService
+	sp            provider.ServiceProvider
+}
+
+// NewClientHandler создаёт новый экземпляр ClientHandler
+func NewClientHandler(clientService service.ClientService, sp provider.ServiceProvider) *ClientHandler {
+	return &ClientHandler{
+		clientService: clientService,
+		sp:            sp,
+	}
+}
+
+// ListClients возвращает список клиентов
+func (h *ClientHandler) ListClients(c *gin.Context) {
+	clients, err := h.clientService.List(c.Request.Context())
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, clients)
+}
+
+// GetClient возвращает клиента по ID
+func (h *ClientHandler) GetClient(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.clientService.GetClientOrFail(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, client)
+}
+
+// CreateClient регистрирует клиента и единожды возвращает его секрет
+func (h *ClientHandler) CreateClient(c *gin.Context) {
+	var req model.CreateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	client, err := h.clientService.Create(c.Request.Context(), &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	api.ActionSuccessResponse(c, "response.oauth_client.created", client)
+}
+
+// UpdateClient изменяет параметры клиента
+func (h *ClientHandler) UpdateClient(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	client, err := h.clientService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.updated", client)
+}
+
+// DeleteClient удаляет клиента
+func (h *ClientHandler) DeleteClient(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.clientService.Delete(c.Request.Context(), id); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.deleted", nil)
+}
+
+// RotateSecret перевыпускает секрет клиента
+func (h *ClientHandler) RotateSecret(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.clientService.RotateSecret(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	api.ActionSuccessResponse(c, "response.oauth_client.secret_rotated", client)
+}
+
+// ListExchangeRules возвращает правила обмена токенов клиента
+func (h *ClientHandler) ListExchangeRules(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.clientService.ListExchangeRules(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, rules)
+}
+
+// SaveExchangeRule создает или заменяет правило обмена токенов для получателя
+func (h *ClientHandler) SaveExchangeRule(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SaveTokenExchangeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	rule, err := h.clientService.SaveExchangeRule(c.Request.Context(), id, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.exchange_rule_saved", rule)
+}
+
+// DeleteExchangeRule удаляет правило обмена токенов
+func (h *ClientHandler) DeleteExchangeRule(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	if err := h.clientService.DeleteExchangeRule(c.Request.Context(), id, ruleID); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.exchange_rule_deleted", nil)
+}
+
+// ListCertificateMappings возвращает привязки клиентских сертификатов
+func (h *ClientHandler) ListCertificateMappings(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	mappings, err := h.clientService.ListCertificateMappings(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, mappings)
+}
+
+// CreateCertificateMapping привязывает клиентский сертификат к клиенту
+func (h *ClientHandler) CreateCertificateMapping(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateCertificateMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	mapping, err := h.clientService.CreateCertificateMapping(c.Request.Context(), id, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.certificate_mapping_created", mapping)
+}
+
+// DeleteCertificateMapping удаляет привязку клиентского сертификата
+func (h *ClientHandler) DeleteCertificateMapping(c *gin.Context) {
+	id, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	mappingID, err := uuid.Parse(c.Param("mappingId"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	if err := h.clientService.DeleteCertificateMapping(c.Request.Context(), id, mappingID); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.oauth_client.certificate_mapping_deleted", nil)
+}
+
+// parseClientID извлекает ID клиента из URL, при ошибке отправляет ответ
+func parseClientID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
+	userModel "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// OAuthHandler обрабатывает HTTP-запросы OAuth2 endpoints
+type OAuthHandler struct {
+	oauthService service.OAuthService
+	sp           provider.ServiceProvider
+}
+
+// NewOAuthHandler создаёт новый экземпляр OAuthHandler
+func NewOAuthHandler(oauthService service.OAuthService, sp provider.ServiceProvider) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		sp:           sp,
+	}
+}
+
+// DeviceCode выдает device_code и user_code для устройства без браузера
+func (h *OAuthHandler) DeviceCode(c *gin.Context) {
+	var req model.DeviceCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.oauthError(c, model.NewOAuthError(model.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	resp, err := h.oauthService.RequestDeviceCode(c.Request.Context(), &req)
+	if err != nil {
+		h.oauthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
+// Token обрабатывает запросы к token endpoint для всех поддерживаемых grant type
+func (h *OAuthHandler) Token(c *gin.Context) {
+	var req model.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.oauthError(c, model.NewOAuthError(model.ErrCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	// client_secret_basic: учетные данные в заголовке Authorization, закодированные form-urlencoded (RFC 6749, раздел 2.3.1)
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		id, idErr := url.QueryUnescape(clientID)
+		secret, secretErr := url.QueryUnescape(clientSecret)
+		if idErr != nil || secretErr != nil {
+			h.oauthError(c, model.NewOAuthError(model.ErrCodeInvalidClient, "malformed Authorization header"))
+			return
+		}
+		if req.ClientSecret != "" || (req.ClientID != "" && req.ClientID != id) {
+			h.oauthError(c, model.NewOAuthError(model.ErrCodeInvalidRequest, "multiple client authentication methods used"))
+			return
+		}
+		req.ClientID = id
+		req.ClientSecret = secret
+		req.BasicAuth = true
+	}
+
+	dpopJKT, err := h.sp.DPoPVerifier().OptionalKey(c.Request)
+	if err != nil {
+		h.oauthError(c, model.NewOAuthError(model.ErrCodeInvalidDPoPProof, err.Error()))
+		return
+	}
+	req.DPoPJKT = dpopJKT
+
+	// Добавляем HTTP запрос в контекст для получения IP и User-Agent
+	ctx := context.WithValue(c.Request.Context(), middleware.RequestKey, c.Request)
+
+	resp, err := h.oauthService.Token(ctx, &req)
+	if err != nil {
+		h.oauthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApproveDevice подтверждает или отклоняет запрос устройства от имени текущего пользователя
+func (h *OAuthHandler) ApproveDevice(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+		return
+	}
+
+	currentUser, ok := user.(*userModel.User)
+	if !ok {
+		apperrors.ResponseWithError(c, apperrors.InternalServerError("errors.internal", nil, nil))
+		return
+	}
+
+	var req model.DeviceApproveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	if err := h.oauthService.ResolveDeviceAuthorization(c.Request.Context(), req.UserCode, currentUser.ID, !req.Deny); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	message := "response.oauth.device_approved"
+	if req.Deny {
+		message = "response.oauth.device_denied"
+	}
+
+	api.ActionSuccessResponse(c, message, nil)
+}
+
+// oauthError отправляет ошибку в формате RFC 6749, который ожидают OAuth2 клиенты
+func (h *OAuthHandler) oauthError(c *gin.Context, err error) {
+	var oauthErr *model.OAuthError
+	if !errors.As(err, &oauthErr) {
+		h.sp.Logger().WithError(err).Error("OAuth endpoint failed")
+		oauthErr = &model.OAuthError{
+			Status: http.StatusInternalServerError,
+			Code:   model.ErrCodeServerError,
+		}
+	}
+
+	if oauthErr.Status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(oauthErr.Status, oauthErr)
+}
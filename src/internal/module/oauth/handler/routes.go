@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/policy"
+)
+
+// RegisterPublicRoutes регистрирует публичные маршруты OAuth2
+func (h *OAuthHandler) RegisterPublicRoutes(group *gin.RouterGroup) {
+	group.POST("/device/code", h.DeviceCode)
+	group.POST("/token", h.Token)
+}
+
+// RegisterProtectedRoutes регистрирует маршруты OAuth2, требующие аутентификации пользователя
+func (h *OAuthHandler) RegisterProtectedRoutes(group *gin.RouterGroup) {
+	group.POST("/device/approve", h.ApproveDevice)
+}
+
+// RegisterClientRoutes регистрирует маршруты управления OAuth2 клиентами
+func (h *ClientHandler) RegisterClientRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
+	group.GET("", policyMiddleware.RequirePermission(policy.ClientResourceName, "view"), h.ListClients)
+	group.POST("", policyMiddleware.RequirePermission(policy.ClientResourceName, "create"), h.CreateClient)
+	group.GET("/:id", policyMiddleware.RequirePermission(policy.ClientResourceName, "view"), h.GetClient)
+	group.PUT("/:id", policyMiddleware.RequirePermission(policy.ClientResourceName, "update"), h.UpdateClient)
+	group.DELETE("/:id", policyMiddleware.RequirePermission(policy.ClientResourceName, "delete"), h.DeleteClient)
+	group.POST("/:id/rotate-secret", policyMiddleware.RequirePermission(policy.ClientResourceName, "rotate-secret"), h.RotateSecret)
+
+	group.GET("/:id/exchange-rules", policyMiddleware.RequirePermission(policy.ClientResourceName, "view"), h.ListExchangeRules)
+	group.PUT("/:id/exchange-rules", policyMiddleware.RequirePermission(policy.ClientResourceName, "update"), h.SaveExchangeRule)
+	group.DELETE("/:id/exchange-rules/:ruleId", policyMiddleware.RequirePermission(policy.ClientResourceName, "update"), h.DeleteExchangeRule)
+
+	group.GET("/:id/certificate-mappings", policyMiddleware.RequirePermission(policy.ClientResourceName, "view"), h.ListCertificateMappings)
+	group.POST("/:id/certificate-mappings", policyMiddleware.RequirePermission(policy.ClientResourceName, "update"), h.CreateCertificateMapping)
+	group.DELETE("/:id/certificate-mappings/:mappingId", policyMiddleware.RequirePermission(policy.ClientResourceName, "update"), h.DeleteCertificateMapping)
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CertificateMapping сопоставляет идентификатор клиентского TLS-сертификата сервисному клиенту
+type CertificateMapping struct {
+	ID            uuid.UUID `json:"id"`
+	ClientID      uuid.UUID `json:"clientId"`
+	IdentityType  string    `json:"identityType"`
+	IdentityValue string    `json:"identityValue"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// CreateCertificateMappingRequest запрос на привязку сертификата к клиенту
+type CreateCertificateMappingRequest struct {
+	IdentityType  string `json:"identityType" binding:"required,oneof=spiffe uri dns email subject"`
+	IdentityValue string `json:"identityValue" binding:"required,max=1024"`
+}
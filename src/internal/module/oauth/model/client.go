@@ -0,0 +1,120 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/permission"
+)
+
+// Способы аутентификации клиента на token endpoint (RFC 7591, раздел 2)
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+	AuthMethodPrivateKeyJWT     = "private_key_jwt"
+)
+
+// Client конфиденциальный OAuth2 клиент - сервисная учетная запись
+type Client struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	AuthMethod      string     `json:"authMethod"`
+	SecretHash      string     `json:"-"`
+	PublicKey       string     `json:"publicKey,omitempty"`
+	Scopes          []string   `json:"scopes"`
+	Active          bool       `json:"active"`
+	SecretRotatedAt *time.Time `json:"secretRotatedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+
+	// OrganizationID организация клиента; nil - клиент уровня платформы
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+}
+
+// UsesSecret проверяет, аутентифицируется ли клиент с помощью client_secret
+func (c *Client) UsesSecret() bool {
+	return c.AuthMethod == AuthMethodClientSecretBasic || c.AuthMethod == AuthMethodClientSecretPost
+}
+
+// AllowsScope проверяет, может ли клиент запросить указанное разрешение
+func (c *Client) AllowsScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// ClientPrincipal машинный субъект запроса, аутентифицированный access token'ом клиента.
+// Права ограничены разрешениями, выданными в конкретном токене
+type ClientPrincipal struct {
+	Client *Client
+	Scopes []string
+
+	grants *permission.Matcher
+}
+
+// NewClientPrincipal создает субъект запроса для клиента с разрешениями из токена
+func NewClientPrincipal(client *Client, tokenScopes []string) *ClientPrincipal {
+	scopes := make([]string, 0, len(tokenScopes))
+	for _, scope := range tokenScopes {
+		// Разрешение могло быть отозвано у клиента после выдачи токена
+		if client.AllowsScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &ClientPrincipal{
+		Client: client,
+		Scopes: scopes,
+		grants: permission.CompileRestricted(corepolicy.RestrictedPermissions, scopes...),
+	}
+}
+
+// HasRole у машинных клиентов нет ролей
+func (p *ClientPrincipal) HasRole(roleName string) bool {
+	return false
+}
+
+// HasPermission проверяет, покрывают ли разрешения токена клиента требуемое (с учётом шаблонов)
+func (p *ClientPrincipal) HasPermission(permissionName string) bool {
+	if p.grants == nil {
+		return permission.CompileRestricted(corepolicy.RestrictedPermissions, p.Scopes...).Allows(permissionName)
+	}
+	return p.grants.Allows(permissionName)
+}
+
+// HasAnyPermission проверяет наличие хотя бы одного из разрешений
+func (p *ClientPrincipal) HasAnyPermission(permissions ...string) bool {
+	return slices.ContainsFunc(permissions, p.HasPermission)
+}
+
+// Attributes у клиента есть идентификатор и организация: ограничения по data_role к нему не применимы
+func (p *ClientPrincipal) Attributes() corepolicy.Attributes {
+	attrs := corepolicy.Attributes{corepolicy.AttrID: p.Client.ID.String()}
+	if p.Client.OrganizationID != nil {
+		attrs[corepolicy.AttrOrganizationID] = p.Client.OrganizationID.String()
+	}
+	return attrs
+}
+
+// CreateClientRequest запрос на регистрацию клиента
+type CreateClientRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	AuthMethod string   `json:"auth_method" binding:"required,oneof=client_secret_basic client_secret_post private_key_jwt"`
+	PublicKey  string   `json:"public_key"`
+	Scopes     []string `json:"scopes"`
+}
+
+// UpdateClientRequest запрос на изменение клиента
+type UpdateClientRequest struct {
+	Name      *string  `json:"name,omitempty"`
+	PublicKey *string  `json:"public_key,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Active    *bool    `json:"active,omitempty"`
+}
+
+// ClientWithSecret ответ на создание клиента или перевыпуск секрета.
+// Секрет в открытом виде возвращается только один раз
+type ClientWithSecret struct {
+	*Client
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
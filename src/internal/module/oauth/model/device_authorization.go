@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Статусы запроса авторизации устройства
+const (
+	DeviceStatusPending  = "pending"
+	DeviceStatusApproved = "approved"
+	DeviceStatusDenied   = "denied"
+	DeviceStatusConsumed = "consumed"
+)
+
+// DeviceAuthorization представляет запрос авторизации устройства (RFC 8628)
+type DeviceAuthorization struct {
+	ID             uuid.UUID  `json:"id"`
+	DeviceCodeHash string     `json:"-"`
+	UserCode       string     `json:"userCode"`
+	ClientID       string     `json:"clientId"`
+	Scope          string     `json:"scope"`
+	Status         string     `json:"status"`
+	UserID         *uuid.UUID `json:"userId,omitempty"`
+	PollInterval   int        `json:"pollInterval"`
+	LastPolledAt   *time.Time `json:"lastPolledAt,omitempty"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	ApprovedAt     *time.Time `json:"approvedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// IsExpired проверяет, истек ли срок действия кодов
+func (da *DeviceAuthorization) IsExpired() bool {
+	return da.ExpiresAt.Before(time.Now())
+}
+
+// PolledTooSoon проверяет, опрашивает ли клиент token endpoint чаще разрешенного интервала
+func (da *DeviceAuthorization) PolledTooSoon(now time.Time) bool {
+	if da.LastPolledAt == nil {
+		return false
+	}
+	return now.Before(da.LastPolledAt.Add(time.Duration(da.PollInterval) * time.Second))
+}
+
+// DeviceIdentifier возвращает идентификатор устройства, к которому привязываются выданные токены
+func (da *DeviceAuthorization) DeviceIdentifier() string {
+	return "oauth-device:" + da.ID.String()
+}
+
+// DeviceCodeRequest запрос на получение кодов устройства
+type DeviceCodeRequest struct {
+	ClientID string `form:"client_id" json:"client_id" binding:"required"`
+	Scope    string `form:"scope" json:"scope"`
+}
+
+// DeviceCodeResponse ответ device authorization endpoint (RFC 8628, раздел 3.2)
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceApproveRequest решение пользователя по запросу устройства
+type DeviceApproveRequest struct {
+	UserCode string `json:"user_code" binding:"required"`
+	// Deny отклоняет запрос вместо подтверждения
+	Deny bool `json:"deny"`
+}
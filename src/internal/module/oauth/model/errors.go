@@ -0,0 +1,49 @@
+package model
+
+import (
+	"net/http"
+)
+
+// Коды ошибок OAuth2 (RFC 6749, раздел 5.2, RFC 8628, раздел 3.5, RFC 8693, раздел 2.2.2 и RFC 9449, раздел 5)
+const (
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeInvalidClient        = "invalid_client"
+	ErrCodeInvalidGrant         = "invalid_grant"
+	ErrCodeUnsupportedGrantType = "unsupported_grant_type"
+	ErrCodeInvalidScope         = "invalid_scope"
+	ErrCodeInvalidTarget        = "invalid_target"
+	ErrCodeInvalidDPoPProof     = "invalid_dpop_proof"
+	ErrCodeAuthorizationPending = "authorization_pending"
+	ErrCodeSlowDown             = "slow_down"
+	ErrCodeAccessDenied         = "access_denied"
+	ErrCodeExpiredToken         = "expired_token"
+	ErrCodeServerError          = "server_error"
+)
+
+// OAuthError ошибка token endpoint в формате, который ожидают OAuth2 клиенты
+type OAuthError struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+// NewOAuthError создает ошибку OAuth2 со статусом 400
+func NewOAuthError(code, description string) *OAuthError {
+	status := http.StatusBadRequest
+	if code == ErrCodeInvalidClient {
+		status = http.StatusUnauthorized
+	}
+
+	return &OAuthError{
+		Status:      status,
+		Code:        code,
+		Description: description,
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+)
+
+// Поддерживаемые типы grant
+const (
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// ClientAssertionTypeJWTBearer тип client_assertion для private_key_jwt (RFC 7523)
+const ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// TokenRequest запрос к token endpoint
+type TokenRequest struct {
+	GrantType           string `form:"grant_type" json:"grant_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	ClientSecret        string `form:"client_secret" json:"client_secret"`
+	ClientAssertionType string `form:"client_assertion_type" json:"client_assertion_type"`
+	ClientAssertion     string `form:"client_assertion" json:"client_assertion"`
+	Scope               string `form:"scope" json:"scope"`
+	DeviceCode          string `form:"device_code" json:"device_code"`
+	SubjectToken        string `form:"subject_token" json:"subject_token"`
+	SubjectTokenType    string `form:"subject_token_type" json:"subject_token_type"`
+	RequestedTokenType  string `form:"requested_token_type" json:"requested_token_type"`
+	Audience            string `form:"audience" json:"audience"`
+
+	// BasicAuth учетные данные клиента переданы в заголовке Authorization (client_secret_basic)
+	BasicAuth bool `form:"-" json:"-"`
+	// DPoPJKT отпечаток ключа из проверенного DPoP proof; выданные токены привязываются к нему
+	DPoPJKT string `form:"-" json:"-"`
+}
+
+// TokenResponse успешный ответ token endpoint (RFC 6749, раздел 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// IssuedTokenType тип выданного токена, только для token exchange (RFC 8693, раздел 2.2.1)
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+}
+
+// TokenResponseFromAuth преобразует ответ модуля аутентификации в формат OAuth2
+func TokenResponseFromAuth(resp *authModel.AuthResponse, scope string) *TokenResponse {
+	return &TokenResponse{
+		AccessToken:  resp.Token,
+		TokenType:    resp.TokenType,
+		ExpiresIn:    resp.ExpiresIn,
+		RefreshToken: resp.RefreshToken,
+		Scope:        scope,
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы токенов (RFC 8693, раздел 3)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// TokenExchangeRule разрешает клиенту обменивать токены пользователей
+// на токены для указанного получателя в пределах заданных разрешений
+type TokenExchangeRule struct {
+	ID        uuid.UUID `json:"id"`
+	ClientID  uuid.UUID `json:"clientId"`
+	Audience  string    `json:"audience"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AllowsScope проверяет, может ли токен для получателя содержать указанное разрешение
+func (r *TokenExchangeRule) AllowsScope(scope string) bool {
+	return slices.Contains(r.Scopes, scope)
+}
+
+// SaveTokenExchangeRuleRequest запрос на создание или изменение правила обмена для получателя
+type SaveTokenExchangeRuleRequest struct {
+	Audience string   `json:"audience" binding:"required,max=255"`
+	Scopes   []string `json:"scopes" binding:"required,min=1"`
+}
@@ -0,0 +1,37 @@
+package policy
+
+import (
+	"context"
+
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+)
+
+// Название ресурса, используемое в маршрутах при проверке доступа
+const ClientResourceName = "oauth-client"
+
+type ClientPolicy struct{}
+
+func NewClientPolicy() *ClientPolicy {
+	return &ClientPolicy{}
+}
+
+// Check требует разрешение "oauth-clients:<action>". Клиенты регистрируются на уровне платформы,
+// поэтому пользователям организаций управление ими недоступно
+func (p *ClientPolicy) Check(ctx context.Context, principal corepolicy.Principal, resource string, action string) bool {
+	return corepolicy.IsPlatform(principal) && corepolicy.CheckAction(principal, "oauth-clients", action, nil)
+}
+
+// CheckResource не зависит от атрибутов ресурса: достаточно права на действие
+func (p *ClientPolicy) CheckResource(ctx context.Context, principal corepolicy.Principal, resource string, action string, attrs corepolicy.Attributes) bool {
+	return p.Check(ctx, principal, resource, action)
+}
+
+// Scope выборка не ограничивается
+func (p *ClientPolicy) Scope(ctx context.Context, principal corepolicy.Principal, resource string, action string) *corepolicy.Scope {
+	return nil
+}
+
+// RegisterInFactory регистрирует политики OAuth модуля в центральной фабрике политик
+func RegisterInFactory(factory *corepolicy.PolicyFactory) {
+	factory.RegisterPolicy(ClientResourceName, NewClientPolicy())
+}
@@ -0,0 +1,47 @@
+package policy
+
+import (
+	"context"
+
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+)
+
+// TokenExchangePolicy решает, может ли клиент получить токен пользователя для стороннего сервиса
+type TokenExchangePolicy struct{}
+
+func NewTokenExchangePolicy() *TokenExchangePolicy {
+	return &TokenExchangePolicy{}
+}
+
+// Check разрешает обмен, если у клиента есть правило для получателя, покрывающее все запрошенные
+// разрешения, и сам пользователь обладает каждым из них: делегирование не расширяет права пользователя
+func (p *TokenExchangePolicy) Check(ctx context.Context, client *model.Client, rule *model.TokenExchangeRule, subject corepolicy.Principal, scopes []string) bool {
+	if client == nil || !client.Active || rule == nil || rule.ClientID != client.ID {
+		return false
+	}
+
+	if len(scopes) == 0 {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if !rule.AllowsScope(scope) || !subject.HasPermission(scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DefaultScopes разрешения токена, если клиент не запросил их явно:
+// все разрешения правила, которыми обладает пользователь
+func (p *TokenExchangePolicy) DefaultScopes(rule *model.TokenExchangeRule, subject corepolicy.Principal) []string {
+	scopes := make([]string, 0, len(rule.Scopes))
+	for _, scope := range rule.Scopes {
+		if subject.HasPermission(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+)
+
+// CertificateMappingRepository определяет интерфейс для операций с привязками клиентских сертификатов
+type CertificateMappingRepository interface {
+	// Create сохраняет привязку
+	Create(ctx context.Context, mapping *model.CertificateMapping) error
+
+	// Delete удаляет привязку клиента
+	Delete(ctx context.Context, clientID, mappingID uuid.UUID) (bool, error)
+
+	// FindByClient возвращает все привязки клиента
+	FindByClient(ctx context.Context, clientID uuid.UUID) ([]*model.CertificateMapping, error)
+
+	// FindByIdentity находит привязку по идентификатору сертификата
+	FindByIdentity(ctx context.Context, identityType, identityValue string) (*model.CertificateMapping, error)
+}
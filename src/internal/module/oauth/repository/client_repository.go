@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+)
+
+// ClientRepository определяет интерфейс для операций с OAuth2 клиентами
+type ClientRepository interface {
+	// Create сохраняет нового клиента
+	Create(ctx context.Context, client *model.Client) error
+
+	// Update обновляет название, ключ, разрешения и активность клиента
+	Update(ctx context.Context, client *model.Client) error
+
+	// Delete удаляет клиента
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// FindByID находит клиента по идентификатору
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Client, error)
+
+	// FindAll возвращает всех клиентов
+	FindAll(ctx context.Context) ([]*model.Client, error)
+
+	// UpdateSecret сохраняет хеш нового секрета клиента
+	UpdateSecret(ctx context.Context, id uuid.UUID, secretHash string, rotatedAt time.Time) error
+
+	// RegisterAssertion запоминает jti использованного client_assertion.
+	// Возвращает false, если assertion с таким jti уже предъявлялся
+	RegisterAssertion(ctx context.Context, jti string, clientID uuid.UUID, expiresAt time.Time) (bool, error)
+}
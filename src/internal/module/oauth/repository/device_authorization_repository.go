@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+)
+
+// DeviceAuthorizationRepository определяет интерфейс для операций с запросами авторизации устройств
+type DeviceAuthorizationRepository interface {
+	// Create сохраняет новый запрос авторизации устройства
+	Create(ctx context.Context, authorization *model.DeviceAuthorization) error
+
+	// GetByDeviceCodeHash находит запрос по хешу device_code
+	GetByDeviceCodeHash(ctx context.Context, deviceCodeHash string) (*model.DeviceAuthorization, error)
+
+	// GetPendingByUserCode находит ожидающий подтверждения запрос по user_code
+	GetPendingByUserCode(ctx context.Context, userCode string) (*model.DeviceAuthorization, error)
+
+	// UpdatePoll фиксирует очередной опрос token endpoint и текущий интервал опроса
+	UpdatePoll(ctx context.Context, id uuid.UUID, polledAt time.Time, pollInterval int) error
+
+	// Resolve переводит ожидающий запрос в статус approved или denied.
+	// Возвращает false, если запрос уже не находится в статусе pending
+	Resolve(ctx context.Context, id uuid.UUID, status string, userID uuid.UUID) (bool, error)
+
+	// Consume помечает подтвержденный запрос использованным.
+	// Возвращает false, если запрос уже был использован
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// DeleteExpired удаляет запросы с истекшим сроком действия
+	DeleteExpired(ctx context.Context) error
+}
@@ -0,0 +1,164 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+)
+
+type certificateMappingRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewCertificateMappingRepository создает новый экземпляр репозитория привязок клиентских сертификатов
+func NewCertificateMappingRepository(sp provider.ServiceProvider, db db.DB) repository.CertificateMappingRepository {
+	return &certificateMappingRepository{
+		sp:   sp,
+		db:   db,
+		name: "CertificateMappingRepository",
+	}
+}
+
+// Create сохраняет привязку
+func (r *certificateMappingRepository) Create(ctx context.Context, mapping *model.CertificateMapping) error {
+	const op = "CertificateMappingRepository.Create"
+	if mapping == nil {
+		return apperrors.InternalServerError("certificate_mapping.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Create",
+		QueryRaw: `
+			INSERT INTO client_certificate_mappings (id, client_id, identity_type, identity_value, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`,
+	}
+
+	if mapping.ID == uuid.Nil {
+		mapping.ID = uuid.New()
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		mapping.ID,
+		mapping.ClientID,
+		mapping.IdentityType,
+		mapping.IdentityValue,
+		mapping.CreatedAt,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to create certificate mapping", op))
+		return apperrors.InternalServerError("certificate_mapping.create_error", err, nil)
+	}
+
+	return nil
+}
+
+// Delete удаляет привязку клиента
+func (r *certificateMappingRepository) Delete(ctx context.Context, clientID, mappingID uuid.UUID) (bool, error) {
+	const op = "CertificateMappingRepository.Delete"
+
+	q := db.Query{
+		Name:     r.name + ".Delete",
+		QueryRaw: `DELETE FROM client_certificate_mappings WHERE id = $1 AND client_id = $2`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, mappingID, clientID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to delete certificate mapping", op))
+		return false, apperrors.InternalServerError("certificate_mapping.delete_error", err, nil)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindByClient возвращает все привязки клиента
+func (r *certificateMappingRepository) FindByClient(ctx context.Context, clientID uuid.UUID) ([]*model.CertificateMapping, error) {
+	const op = "CertificateMappingRepository.FindByClient"
+
+	q := db.Query{
+		Name: r.name + ".FindByClient",
+		QueryRaw: `
+			SELECT id, client_id, identity_type, identity_value, created_at
+			FROM client_certificate_mappings
+			WHERE client_id = $1
+			ORDER BY created_at
+		`,
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, clientID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get certificate mappings", op))
+		return nil, apperrors.InternalServerError("certificate_mapping.get_error", err, nil)
+	}
+	defer rows.Close()
+
+	mappings := make([]*model.CertificateMapping, 0)
+	for rows.Next() {
+		mapping, err := r.scan(rows)
+		if err != nil {
+			r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to scan certificate mapping", op))
+			return nil, apperrors.InternalServerError("certificate_mapping.scan_error", err, nil)
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: rows error", op))
+		return nil, apperrors.InternalServerError("certificate_mapping.rows_error", err, nil)
+	}
+
+	return mappings, nil
+}
+
+// FindByIdentity находит привязку по идентификатору сертификата
+func (r *certificateMappingRepository) FindByIdentity(ctx context.Context, identityType, identityValue string) (*model.CertificateMapping, error) {
+	const op = "CertificateMappingRepository.FindByIdentity"
+
+	q := db.Query{
+		Name: r.name + ".FindByIdentity",
+		QueryRaw: `
+			SELECT id, client_id, identity_type, identity_value, created_at
+			FROM client_certificate_mappings
+			WHERE identity_type = $1 AND identity_value = $2
+		`,
+	}
+
+	mapping, err := r.scan(r.db.QueryRowContext(ctx, q, identityType, identityValue))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get certificate mapping", op))
+		return nil, apperrors.InternalServerError("certificate_mapping.get_error", err, nil)
+	}
+
+	return mapping, nil
+}
+
+// scan считывает привязку из строки результата
+func (r *certificateMappingRepository) scan(row pgx.Row) (*model.CertificateMapping, error) {
+	var mapping model.CertificateMapping
+
+	err := row.Scan(
+		&mapping.ID,
+		&mapping.ClientID,
+		&mapping.IdentityType,
+		&mapping.IdentityValue,
+		&mapping.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mapping, nil
+}
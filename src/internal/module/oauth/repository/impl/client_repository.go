@@ -0,0 +1,266 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+)
+
+// clientColumns колонки клиента в порядке, ожидаемом scan
+const clientColumns = `id, name, auth_method, COALESCE(secret_hash, ''), COALESCE(public_key, ''),
+			       scopes, active, secret_rotated_at, created_at, updated_at, organization_id`
+
+type clientRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewClientRepository создает новый экземпляр репозитория OAuth2 клиентов
+func NewClientRepository(sp provider.ServiceProvider, db db.DB) repository.ClientRepository {
+	return &clientRepository{
+		sp:   sp,
+		db:   db,
+		name: "ClientRepository",
+	}
+}
+
+// Create сохраняет нового клиента
+func (r *clientRepository) Create(ctx context.Context, client *model.Client) error {
+	const op = "ClientRepository.Create"
+	if client == nil {
+		return apperrors.InternalServerError("oauth_client.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Create",
+		QueryRaw: `
+			INSERT INTO oauth_clients
+			(id, name, auth_method, secret_hash, public_key, scopes, active, secret_rotated_at, created_at, updated_at, organization_id)
+			VALUES
+			($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11)
+		`,
+	}
+
+	if client.ID == uuid.Nil {
+		client.ID = uuid.New()
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		client.ID,
+		client.Name,
+		client.AuthMethod,
+		client.SecretHash,
+		client.PublicKey,
+		client.Scopes,
+		client.Active,
+		client.SecretRotatedAt,
+		client.CreatedAt,
+		client.UpdatedAt,
+		client.OrganizationID,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to create oauth client", op))
+		return apperrors.InternalServerError("oauth_client.create_error", err, nil)
+	}
+
+	return nil
+}
+
+// Update обновляет название, ключ, разрешения и активность клиента
+func (r *clientRepository) Update(ctx context.Context, client *model.Client) error {
+	const op = "ClientRepository.Update"
+	if client == nil {
+		return apperrors.InternalServerError("oauth_client.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Update",
+		QueryRaw: `
+			UPDATE oauth_clients
+			SET name = $1, public_key = NULLIF($2, ''), scopes = $3, active = $4
+			WHERE id = $5
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q, client.Name, client.PublicKey, client.Scopes, client.Active, client.ID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to update oauth client", op))
+		return apperrors.InternalServerError("oauth_client.update_error", err, nil)
+	}
+
+	return nil
+}
+
+// Delete удаляет клиента
+func (r *clientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	const op = "ClientRepository.Delete"
+
+	q := db.Query{
+		Name:     r.name + ".Delete",
+		QueryRaw: `DELETE FROM oauth_clients WHERE id = $1`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to delete oauth client", op))
+		return apperrors.InternalServerError("oauth_client.delete_error", err, nil)
+	}
+
+	return nil
+}
+
+// FindByID находит клиента по идентификатору в организации арендатора из контекста
+func (r *clientRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Client, error) {
+	const op = "ClientRepository.FindByID"
+
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	tenant.Filter(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name: r.name + ".FindByID",
+		QueryRaw: `
+			SELECT ` + clientColumns + `
+			FROM oauth_clients` + conds.Where(),
+	}
+
+	client, err := r.scan(r.db.QueryRowContext(ctx, q, conds.Args()...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get oauth client", op))
+		return nil, apperrors.InternalServerError("oauth_client.get_error", err, nil)
+	}
+
+	return client, nil
+}
+
+// FindAll возвращает клиентов организации арендатора из контекста
+func (r *clientRepository) FindAll(ctx context.Context) ([]*model.Client, error) {
+	const op = "ClientRepository.FindAll"
+
+	conds := &db.Conditions{}
+	tenant.Filter(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name: r.name + ".FindAll",
+		QueryRaw: `
+			SELECT ` + clientColumns + `
+			FROM oauth_clients` + conds.Where() + `
+			ORDER BY created_at DESC
+		`,
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, conds.Args()...)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get oauth clients", op))
+		return nil, apperrors.InternalServerError("oauth_client.get_error", err, nil)
+	}
+	defer rows.Close()
+
+	clients := make([]*model.Client, 0)
+	for rows.Next() {
+		client, err := r.scan(rows)
+		if err != nil {
+			r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to scan oauth client", op))
+			return nil, apperrors.InternalServerError("oauth_client.scan_error", err, nil)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: rows error", op))
+		return nil, apperrors.InternalServerError("oauth_client.rows_error", err, nil)
+	}
+
+	return clients, nil
+}
+
+// UpdateSecret сохраняет хеш нового секрета клиента
+func (r *clientRepository) UpdateSecret(ctx context.Context, id uuid.UUID, secretHash string, rotatedAt time.Time) error {
+	const op = "ClientRepository.UpdateSecret"
+
+	q := db.Query{
+		Name: r.name + ".UpdateSecret",
+		QueryRaw: `
+			UPDATE oauth_clients
+			SET secret_hash = $1, secret_rotated_at = $2
+			WHERE id = $3
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q, secretHash, rotatedAt, id)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to update oauth client secret", op))
+		return apperrors.InternalServerError("oauth_client.update_error", err, nil)
+	}
+
+	return nil
+}
+
+// RegisterAssertion запоминает jti использованного client_assertion
+func (r *clientRepository) RegisterAssertion(ctx context.Context, jti string, clientID uuid.UUID, expiresAt time.Time) (bool, error) {
+	const op = "ClientRepository.RegisterAssertion"
+
+	// Попутно вычищаем assertion'ы, которые уже не могут быть предъявлены повторно
+	cleanup := db.Query{
+		Name:     r.name + ".DeleteExpiredAssertions",
+		QueryRaw: `DELETE FROM oauth_client_assertions WHERE expires_at < $1`,
+	}
+	if _, err := r.db.ExecContext(ctx, cleanup, time.Now()); err != nil {
+		r.sp.Logger().WithError(err).Warn(fmt.Sprintf("%s: unable to delete expired assertions", op))
+	}
+
+	q := db.Query{
+		Name: r.name + ".RegisterAssertion",
+		QueryRaw: `
+			INSERT INTO oauth_client_assertions (jti, client_id, expires_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (jti) DO NOTHING
+		`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, jti, clientID, expiresAt)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to register client assertion", op))
+		return false, apperrors.InternalServerError("oauth_client.assertion_error", err, nil)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// scan считывает клиента из строки результата
+func (r *clientRepository) scan(row pgx.Row) (*model.Client, error) {
+	var client model.Client
+
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.AuthMethod,
+		&client.SecretHash,
+		&client.PublicKey,
+		&client.Scopes,
+		&client.Active,
+		&client.SecretRotatedAt,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+		&client.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
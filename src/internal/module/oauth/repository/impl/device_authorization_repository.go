@@ -0,0 +1,235 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+)
+
+type deviceAuthorizationRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewDeviceAuthorizationRepository создает новый экземпляр репозитория запросов авторизации устройств
+func NewDeviceAuthorizationRepository(sp provider.ServiceProvider, db db.DB) repository.DeviceAuthorizationRepository {
+	return &deviceAuthorizationRepository{
+		sp:   sp,
+		db:   db,
+		name: "DeviceAuthorizationRepository",
+	}
+}
+
+// Create сохраняет новый запрос авторизации устройства
+func (r *deviceAuthorizationRepository) Create(ctx context.Context, authorization *model.DeviceAuthorization) error {
+	const op = "DeviceAuthorizationRepository.Create"
+	if authorization == nil {
+		return apperrors.InternalServerError("device_authorization.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Create",
+		QueryRaw: `
+			INSERT INTO oauth_device_authorizations
+			(id, device_code_hash, user_code, client_id, scope, status, poll_interval, expires_at, created_at)
+			VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+	}
+
+	if authorization.ID == uuid.Nil {
+		authorization.ID = uuid.New()
+	}
+
+	_, err := r.db.ExecContext(ctx, q,
+		authorization.ID,
+		authorization.DeviceCodeHash,
+		authorization.UserCode,
+		authorization.ClientID,
+		authorization.Scope,
+		authorization.Status,
+		authorization.PollInterval,
+		authorization.ExpiresAt,
+		authorization.CreatedAt,
+	)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to create device authorization", op))
+		return err
+	}
+
+	return nil
+}
+
+// GetByDeviceCodeHash находит запрос по хешу device_code
+func (r *deviceAuthorizationRepository) GetByDeviceCodeHash(ctx context.Context, deviceCodeHash string) (*model.DeviceAuthorization, error) {
+	const op = "DeviceAuthorizationRepository.GetByDeviceCodeHash"
+
+	q := db.Query{
+		Name: r.name + ".GetByDeviceCodeHash",
+		QueryRaw: `
+			SELECT id, device_code_hash, user_code, client_id, scope, status, user_id,
+			       poll_interval, last_polled_at, expires_at, approved_at, created_at
+			FROM oauth_device_authorizations
+			WHERE device_code_hash = $1
+		`,
+	}
+
+	authorization, err := r.scan(r.db.QueryRowContext(ctx, q, deviceCodeHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get device authorization", op))
+		return nil, apperrors.InternalServerError("device_authorization.get_error", err, nil)
+	}
+
+	return authorization, nil
+}
+
+// GetPendingByUserCode находит ожидающий подтверждения запрос по user_code
+func (r *deviceAuthorizationRepository) GetPendingByUserCode(ctx context.Context, userCode string) (*model.DeviceAuthorization, error) {
+	const op = "DeviceAuthorizationRepository.GetPendingByUserCode"
+
+	q := db.Query{
+		Name: r.name + ".GetPendingByUserCode",
+		QueryRaw: `
+			SELECT id, device_code_hash, user_code, client_id, scope, status, user_id,
+			       poll_interval, last_polled_at, expires_at, approved_at, created_at
+			FROM oauth_device_authorizations
+			WHERE user_code = $1 AND status = $2 AND expires_at > $3
+		`,
+	}
+
+	authorization, err := r.scan(r.db.QueryRowContext(ctx, q, userCode, model.DeviceStatusPending, time.Now()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get device authorization", op))
+		return nil, apperrors.InternalServerError("device_authorization.get_error", err, nil)
+	}
+
+	return authorization, nil
+}
+
+// UpdatePoll фиксирует очередной опрос token endpoint и текущий интервал опроса
+func (r *deviceAuthorizationRepository) UpdatePoll(ctx context.Context, id uuid.UUID, polledAt time.Time, pollInterval int) error {
+	const op = "DeviceAuthorizationRepository.UpdatePoll"
+
+	q := db.Query{
+		Name: r.name + ".UpdatePoll",
+		QueryRaw: `
+			UPDATE oauth_device_authorizations
+			SET last_polled_at = $1, poll_interval = $2
+			WHERE id = $3
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q, polledAt, pollInterval, id)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to update poll state", op))
+		return apperrors.InternalServerError("device_authorization.update_error", err, nil)
+	}
+
+	return nil
+}
+
+// Resolve переводит ожидающий запрос в статус approved или denied
+func (r *deviceAuthorizationRepository) Resolve(ctx context.Context, id uuid.UUID, status string, userID uuid.UUID) (bool, error) {
+	const op = "DeviceAuthorizationRepository.Resolve"
+
+	q := db.Query{
+		Name: r.name + ".Resolve",
+		QueryRaw: `
+			UPDATE oauth_device_authorizations
+			SET status = $1, user_id = $2, approved_at = $3
+			WHERE id = $4 AND status = $5
+		`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, status, userID, time.Now(), id, model.DeviceStatusPending)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to resolve device authorization", op))
+		return false, apperrors.InternalServerError("device_authorization.update_error", err, nil)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Consume помечает подтвержденный запрос использованным
+func (r *deviceAuthorizationRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	const op = "DeviceAuthorizationRepository.Consume"
+
+	q := db.Query{
+		Name: r.name + ".Consume",
+		QueryRaw: `
+			UPDATE oauth_device_authorizations
+			SET status = $1
+			WHERE id = $2 AND status = $3
+		`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, model.DeviceStatusConsumed, id, model.DeviceStatusApproved)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to consume device authorization", op))
+		return false, apperrors.InternalServerError("device_authorization.update_error", err, nil)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteExpired удаляет запросы с истекшим сроком действия
+func (r *deviceAuthorizationRepository) DeleteExpired(ctx context.Context) error {
+	const op = "DeviceAuthorizationRepository.DeleteExpired"
+
+	q := db.Query{
+		Name: r.name + ".DeleteExpired",
+		QueryRaw: `
+			DELETE FROM oauth_device_authorizations
+			WHERE expires_at < $1
+		`,
+	}
+
+	_, err := r.db.ExecContext(ctx, q, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to delete expired device authorizations", op))
+		return apperrors.InternalServerError("device_authorization.delete_error", err, nil)
+	}
+
+	return nil
+}
+
+// scan считывает запрос авторизации устройства из строки результата
+func (r *deviceAuthorizationRepository) scan(row pgx.Row) (*model.DeviceAuthorization, error) {
+	var authorization model.DeviceAuthorization
+
+	err := row.Scan(
+		&authorization.ID,
+		&authorization.DeviceCodeHash,
+		&authorization.UserCode,
+		&authorization.ClientID,
+		&authorization.Scope,
+		&authorization.Status,
+		&authorization.UserID,
+		&authorization.PollInterval,
+		&authorization.LastPolledAt,
+		&authorization.ExpiresAt,
+		&authorization.ApprovedAt,
+		&authorization.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorization, nil
+}
@@ -0,0 +1,168 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/repository"
+)
+
+type tokenExchangeRuleRepository struct {
+	sp   provider.ServiceProvider
+	db   db.DB
+	name string
+}
+
+// NewTokenExchangeRuleRepository создает новый экземпляр репозитория правил обмена токенов
+func NewTokenExchangeRuleRepository(sp provider.ServiceProvider, db db.DB) repository.TokenExchangeRuleRepository {
+	return &tokenExchangeRuleRepository{
+		sp:   sp,
+		db:   db,
+		name: "TokenExchangeRuleRepository",
+	}
+}
+
+// Save создает правило или заменяет разрешения существующего правила для той же пары клиент/получатель
+func (r *tokenExchangeRuleRepository) Save(ctx context.Context, rule *model.TokenExchangeRule) error {
+	const op = "TokenExchangeRuleRepository.Save"
+	if rule == nil {
+		return apperrors.InternalServerError("token_exchange_rule.is_nil", nil, nil)
+	}
+
+	q := db.Query{
+		Name: r.name + ".Save",
+		QueryRaw: `
+			INSERT INTO oauth_token_exchange_rules (id, client_id, audience, scopes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (client_id, audience) DO UPDATE SET scopes = EXCLUDED.scopes
+			RETURNING id, created_at, updated_at
+		`,
+	}
+
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+
+	err := r.db.QueryRowContext(ctx, q,
+		rule.ID,
+		rule.ClientID,
+		rule.Audience,
+		rule.Scopes,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to save token exchange rule", op))
+		return apperrors.InternalServerError("token_exchange_rule.save_error", err, nil)
+	}
+
+	return nil
+}
+
+// Delete удаляет правило клиента
+func (r *tokenExchangeRuleRepository) Delete(ctx context.Context, clientID, ruleID uuid.UUID) (bool, error) {
+	const op = "TokenExchangeRuleRepository.Delete"
+
+	q := db.Query{
+		Name:     r.name + ".Delete",
+		QueryRaw: `DELETE FROM oauth_token_exchange_rules WHERE id = $1 AND client_id = $2`,
+	}
+
+	tag, err := r.db.ExecContext(ctx, q, ruleID, clientID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to delete token exchange rule", op))
+		return false, apperrors.InternalServerError("token_exchange_rule.delete_error", err, nil)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindByClient возвращает все правила клиента
+func (r *tokenExchangeRuleRepository) FindByClient(ctx context.Context, clientID uuid.UUID) ([]*model.TokenExchangeRule, error) {
+	const op = "TokenExchangeRuleRepository.FindByClient"
+
+	q := db.Query{
+		Name: r.name + ".FindByClient",
+		QueryRaw: `
+			SELECT id, client_id, audience, scopes, created_at, updated_at
+			FROM oauth_token_exchange_rules
+			WHERE client_id = $1
+			ORDER BY audience
+		`,
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, clientID)
+	if err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get token exchange rules", op))
+		return nil, apperrors.InternalServerError("token_exchange_rule.get_error", err, nil)
+	}
+	defer rows.Close()
+
+	rules := make([]*model.TokenExchangeRule, 0)
+	for rows.Next() {
+		rule, err := r.scan(rows)
+		if err != nil {
+			r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to scan token exchange rule", op))
+			return nil, apperrors.InternalServerError("token_exchange_rule.scan_error", err, nil)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: rows error", op))
+		return nil, apperrors.InternalServerError("token_exchange_rule.rows_error", err, nil)
+	}
+
+	return rules, nil
+}
+
+// FindByClientAndAudience находит правило клиента для получателя
+func (r *tokenExchangeRuleRepository) FindByClientAndAudience(ctx context.Context, clientID uuid.UUID, audience string) (*model.TokenExchangeRule, error) {
+	const op = "TokenExchangeRuleRepository.FindByClientAndAudience"
+
+	q := db.Query{
+		Name: r.name + ".FindByClientAndAudience",
+		QueryRaw: `
+			SELECT id, client_id, audience, scopes, created_at, updated_at
+			FROM oauth_token_exchange_rules
+			WHERE client_id = $1 AND audience = $2
+		`,
+	}
+
+	rule, err := r.scan(r.db.QueryRowContext(ctx, q, clientID, audience))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to get token exchange rule", op))
+		return nil, apperrors.InternalServerError("token_exchange_rule.get_error", err, nil)
+	}
+
+	return rule, nil
+}
+
+// scan считывает правило из строки результата
+func (r *tokenExchangeRuleRepository) scan(row pgx.Row) (*model.TokenExchangeRule, error) {
+	var rule model.TokenExchangeRule
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.ClientID,
+		&rule.Audience,
+		&rule.Scopes,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+)
+
+// TokenExchangeRuleRepository определяет интерфейс для операций с правилами обмена токенов
+type TokenExchangeRuleRepository interface {
+	// Save создает правило или заменяет разрешения существующего правила для той же пары клиент/получатель
+	Save(ctx context.Context, rule *model.TokenExchangeRule) error
+
+	// Delete удаляет правило клиента
+	Delete(ctx context.Context, clientID, ruleID uuid.UUID) (bool, error)
+
+	// FindByClient возвращает все правила клиента
+	FindByClient(ctx context.Context, clientID uuid.UUID) ([]*model.TokenExchangeRule, error)
+
+	// FindByClientAndAudience находит правило клиента для получателя
+	FindByClientAndAudience(ctx context.Context, clientID uuid.UUID, audience string) (*model.TokenExchangeRule, error)
+}
@@ -0,0 +1,560 @@
+package impl
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/mtls"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// clientSecretSize размер секрета клиента в байтах до кодирования
+	clientSecretSize = 32
+	// maxAssertionLifetime максимальный срок действия client_assertion,
+	// ограничивает время хранения использованных jti
+	maxAssertionLifetime = time.Hour
+)
+
+// assertionSigningMethods алгоритмы, допустимые для client_assertion
+var assertionSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}
+
+type clientService struct {
+	cfg config.OAuthConfig
+	sp  provider.ServiceProvider
+}
+
+func NewClientService(cfg config.OAuthConfig, sp provider.ServiceProvider) service.ClientService {
+	return &clientService{
+		cfg: cfg,
+		sp:  sp,
+	}
+}
+
+// Create регистрирует нового конфиденциального клиента
+func (s *clientService) Create(ctx context.Context, req *model.CreateClientRequest) (*model.ClientWithSecret, error) {
+	if err := s.validateScopes(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	client := &model.Client{
+		ID:         uuid.New(),
+		Name:       req.Name,
+		AuthMethod: req.AuthMethod,
+		Scopes:     normalizeScopes(req.Scopes),
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+
+		// Клиент действует в организации, в которой зарегистрирован
+		OrganizationID: tenant.OrganizationID(ctx),
+	}
+
+	var secret string
+	if client.UsesSecret() {
+		var err error
+		secret, err = randomToken(clientSecretSize)
+		if err != nil {
+			return nil, apperrors.InternalServerError("errors.internal", err, nil)
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, apperrors.InternalServerError("errors.internal", err, nil)
+		}
+		client.SecretHash = string(hash)
+		client.SecretRotatedAt = &now
+	} else {
+		if _, err := parsePublicKey(req.PublicKey); err != nil {
+			return nil, apperrors.ValidationError("oauth_client.invalid_public_key", err, nil)
+		}
+		client.PublicKey = req.PublicKey
+	}
+
+	if err := s.sp.OAuthClientRepository(ctx).Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	s.sp.Logger().WithField("client_id", client.ID).
+		WithField("auth_method", client.AuthMethod).
+		Info("OAuth client created")
+
+	return &model.ClientWithSecret{Client: client, ClientSecret: secret}, nil
+}
+
+// Update изменяет параметры клиента
+func (s *clientService) Update(ctx context.Context, id uuid.UUID, req *model.UpdateClientRequest) (*model.Client, error) {
+	client, err := s.GetClientOrFail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		client.Name = *req.Name
+	}
+	if req.Active != nil {
+		client.Active = *req.Active
+	}
+	if req.Scopes != nil {
+		if err := s.validateScopes(ctx, req.Scopes); err != nil {
+			return nil, err
+		}
+		client.Scopes = normalizeScopes(req.Scopes)
+	}
+	if req.PublicKey != nil {
+		if client.UsesSecret() {
+			return nil, apperrors.BadRequestError("oauth_client.public_key_not_allowed", nil, nil)
+		}
+		if _, err := parsePublicKey(*req.PublicKey); err != nil {
+			return nil, apperrors.ValidationError("oauth_client.invalid_public_key", err, nil)
+		}
+		client.PublicKey = *req.PublicKey
+	}
+
+	if err := s.sp.OAuthClientRepository(ctx).Update(ctx, client); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Delete удаляет клиента
+func (s *clientService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetClientOrFail(ctx, id); err != nil {
+		return err
+	}
+
+	return s.sp.OAuthClientRepository(ctx).Delete(ctx, id)
+}
+
+// GetByID возвращает клиента по идентификатору
+func (s *clientService) GetByID(ctx context.Context, id uuid.UUID) (*model.Client, error) {
+	return s.sp.OAuthClientRepository(ctx).FindByID(ctx, id)
+}
+
+// GetClientOrFail возвращает клиента по идентификатору или ошибку, если клиента нет
+func (s *clientService) GetClientOrFail(ctx context.Context, id uuid.UUID) (*model.Client, error) {
+	client, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, apperrors.NotFoundError("oauth_client.not_found", nil, map[string]interface{}{
+			"id": id,
+		})
+	}
+	return client, nil
+}
+
+// List возвращает всех клиентов
+func (s *clientService) List(ctx context.Context) ([]*model.Client, error) {
+	return s.sp.OAuthClientRepository(ctx).FindAll(ctx)
+}
+
+// RotateSecret выпускает новый секрет, предыдущий перестает действовать сразу
+func (s *clientService) RotateSecret(ctx context.Context, id uuid.UUID) (*model.ClientWithSecret, error) {
+	client, err := s.GetClientOrFail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.UsesSecret() {
+		return nil, apperrors.BadRequestError("oauth_client.secret_not_used", nil, nil)
+	}
+
+	secret, err := randomToken(clientSecretSize)
+	if err != nil {
+		return nil, apperrors.InternalServerError("errors.internal", err, nil)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.InternalServerError("errors.internal", err, nil)
+	}
+
+	now := time.Now()
+	if err := s.sp.OAuthClientRepository(ctx).UpdateSecret(ctx, client.ID, string(hash), now); err != nil {
+		return nil, err
+	}
+	client.SecretRotatedAt = &now
+
+	s.sp.Logger().WithField("client_id", client.ID).Info("OAuth client secret rotated")
+
+	return &model.ClientWithSecret{Client: client, ClientSecret: secret}, nil
+}
+
+// ListExchangeRules возвращает правила обмена токенов клиента
+func (s *clientService) ListExchangeRules(ctx context.Context, clientID uuid.UUID) ([]*model.TokenExchangeRule, error) {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	return s.sp.TokenExchangeRuleRepository(ctx).FindByClient(ctx, clientID)
+}
+
+// SaveExchangeRule создает или заменяет правило обмена токенов для получателя
+func (s *clientService) SaveExchangeRule(ctx context.Context, clientID uuid.UUID, req *model.SaveTokenExchangeRuleRequest) (*model.TokenExchangeRule, error) {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	audience := strings.TrimSpace(req.Audience)
+	// Токены для собственного API выдаются без делегирования, обмен на них не имеет смысла
+	if audience == "" || audience == s.cfg.TokenAudience() {
+		return nil, apperrors.ValidationError("token_exchange_rule.invalid_audience", nil, map[string]interface{}{
+			"audience": req.Audience,
+		})
+	}
+
+	if err := s.validateScopes(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &model.TokenExchangeRule{
+		ClientID:  clientID,
+		Audience:  audience,
+		Scopes:    normalizeScopes(req.Scopes),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.sp.TokenExchangeRuleRepository(ctx).Save(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.sp.Logger().WithField("client_id", clientID).
+		WithField("audience", rule.Audience).
+		Info("Token exchange rule saved")
+
+	return rule, nil
+}
+
+// DeleteExchangeRule удаляет правило обмена токенов клиента
+func (s *clientService) DeleteExchangeRule(ctx context.Context, clientID, ruleID uuid.UUID) error {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return err
+	}
+
+	deleted, err := s.sp.TokenExchangeRuleRepository(ctx).Delete(ctx, clientID, ruleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperrors.NotFoundError("token_exchange_rule.not_found", nil, map[string]interface{}{
+			"id": ruleID,
+		})
+	}
+
+	return nil
+}
+
+// ListCertificateMappings возвращает привязки клиентских сертификатов клиента
+func (s *clientService) ListCertificateMappings(ctx context.Context, clientID uuid.UUID) ([]*model.CertificateMapping, error) {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	return s.sp.CertificateMappingRepository(ctx).FindByClient(ctx, clientID)
+}
+
+// CreateCertificateMapping привязывает идентификатор сертификата к клиенту
+func (s *clientService) CreateCertificateMapping(ctx context.Context, clientID uuid.UUID, req *model.CreateCertificateMappingRequest) (*model.CertificateMapping, error) {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	value := mtls.NormalizeIdentity(req.IdentityType, req.IdentityValue)
+	if value == "" {
+		return nil, apperrors.ValidationError("certificate_mapping.invalid_identity", nil, map[string]interface{}{
+			"identityValue": req.IdentityValue,
+		})
+	}
+
+	repo := s.sp.CertificateMappingRepository(ctx)
+	existing, err := repo.FindByIdentity(ctx, req.IdentityType, value)
+	if err != nil {
+		return nil, err
+	}
+	// Один сертификат - один клиент, иначе принципал определялся бы неоднозначно
+	if existing != nil {
+		return nil, apperrors.ConflictError("certificate_mapping.already_exists", nil, map[string]interface{}{
+			"clientId": existing.ClientID,
+		})
+	}
+
+	mapping := &model.CertificateMapping{
+		ClientID:      clientID,
+		IdentityType:  req.IdentityType,
+		IdentityValue: value,
+		CreatedAt:     time.Now(),
+	}
+	if err := repo.Create(ctx, mapping); err != nil {
+		return nil, err
+	}
+
+	s.sp.Logger().WithField("client_id", clientID).
+		WithField("identity_type", mapping.IdentityType).
+		WithField("identity_value", mapping.IdentityValue).
+		Info("Client certificate mapping created")
+
+	return mapping, nil
+}
+
+// DeleteCertificateMapping удаляет привязку сертификата клиента
+func (s *clientService) DeleteCertificateMapping(ctx context.Context, clientID, mappingID uuid.UUID) error {
+	if _, err := s.GetClientOrFail(ctx, clientID); err != nil {
+		return err
+	}
+
+	deleted, err := s.sp.CertificateMappingRepository(ctx).Delete(ctx, clientID, mappingID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperrors.NotFoundError("certificate_mapping.not_found", nil, map[string]interface{}{
+			"id": mappingID,
+		})
+	}
+
+	s.sp.Logger().WithField("client_id", clientID).
+		WithField("mapping_id", mappingID).
+		Info("Client certificate mapping deleted")
+
+	return nil
+}
+
+// AuthenticateCertificate определяет клиента по проверенному клиентскому сертификату
+func (s *clientService) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*model.Client, *mtls.Identity, error) {
+	if checker := s.sp.CertificateRevocationChecker(); checker != nil {
+		if err := checker.Check(cert); err != nil {
+			if errors.Is(err, mtls.ErrRevoked) {
+				return nil, nil, apperrors.UnauthorizedError("mtls.certificate_revoked", err, nil)
+			}
+			return nil, nil, apperrors.UnauthorizedError("mtls.revocation_check_failed", err, nil)
+		}
+	}
+
+	repo := s.sp.CertificateMappingRepository(ctx)
+	for _, identity := range mtls.Identities(cert) {
+		mapping, err := repo.FindByIdentity(ctx, identity.Type, identity.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mapping == nil {
+			continue
+		}
+
+		client, err := s.GetByID(ctx, mapping.ClientID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if client == nil || !client.Active {
+			return nil, &identity, apperrors.UnauthorizedError("mtls.client_inactive", nil, nil)
+		}
+		return client, &identity, nil
+	}
+
+	return nil, nil, apperrors.UnauthorizedError("mtls.certificate_not_mapped", nil, nil)
+}
+
+// Authenticate проверяет учетные данные клиента, предъявленные token endpoint
+func (s *clientService) Authenticate(ctx context.Context, req *model.TokenRequest) (*model.Client, error) {
+	if req.ClientAssertion != "" || req.ClientAssertionType != "" {
+		return s.authenticateAssertion(ctx, req)
+	}
+
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "client credentials are required")
+	}
+
+	client, err := s.findActiveClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	method := model.AuthMethodClientSecretPost
+	if req.BasicAuth {
+		method = model.AuthMethodClientSecretBasic
+	}
+	if client.AuthMethod != method {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "authentication method is not allowed for client")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(client.SecretHash), []byte(req.ClientSecret)); err != nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "invalid client credentials")
+	}
+
+	return client, nil
+}
+
+// authenticateAssertion проверяет client_assertion, подписанный закрытым ключом клиента (RFC 7523)
+func (s *clientService) authenticateAssertion(ctx context.Context, req *model.TokenRequest) (*model.Client, error) {
+	if req.ClientAssertionType != model.ClientAssertionTypeJWTBearer || req.ClientAssertion == "" {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "unsupported client_assertion_type")
+	}
+
+	// Идентификатор клиента берем из assertion до проверки подписи, чтобы найти его ключ
+	var unverified jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(req.ClientAssertion, &unverified); err != nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "malformed client_assertion")
+	}
+	if req.ClientID != "" && req.ClientID != unverified.Subject {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "client_id does not match client_assertion")
+	}
+
+	client, err := s.findActiveClient(ctx, unverified.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if client.AuthMethod != model.AuthMethodPrivateKeyJWT {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "authentication method is not allowed for client")
+	}
+
+	publicKey, err := parsePublicKey(client.PublicKey)
+	if err != nil {
+		s.sp.Logger().WithError(err).WithField("client_id", client.ID).Error("Stored client public key is invalid")
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "")
+	}
+
+	var claims jwt.RegisteredClaims
+	_, err = jwt.ParseWithClaims(req.ClientAssertion, &claims,
+		func(token *jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		},
+		jwt.WithValidMethods(assertionSigningMethods),
+		jwt.WithIssuer(client.ID.String()),
+		jwt.WithSubject(client.ID.String()),
+		jwt.WithAudience(s.cfg.TokenEndpointURL()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "invalid client_assertion")
+	}
+
+	if claims.ID == "" {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "client_assertion must contain jti")
+	}
+	if time.Until(claims.ExpiresAt.Time) > maxAssertionLifetime {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "client_assertion lifetime is too long")
+	}
+
+	fresh, err := s.sp.OAuthClientRepository(ctx).RegisterAssertion(ctx, claims.ID, client.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "client_assertion has already been used")
+	}
+
+	return client, nil
+}
+
+// findActiveClient находит активного клиента по client_id
+func (s *clientService) findActiveClient(ctx context.Context, clientID string) (*model.Client, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "unknown client")
+	}
+
+	client, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil || !client.Active {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidClient, "unknown client")
+	}
+
+	return client, nil
+}
+
+// validateScopes проверяет, что все scope клиента - существующие разрешения, которые есть
+// у субъекта запроса: иначе через клиента можно было бы получить больше прав, чем есть у его автора
+func (s *clientService) validateScopes(ctx context.Context, scopes []string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	permissions, err := s.sp.UserService(ctx).GetAllPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Name] = struct{}{}
+	}
+
+	var unknown []string
+	for _, scope := range scopes {
+		if _, ok := known[scope]; !ok {
+			unknown = append(unknown, scope)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return apperrors.ValidationError("oauth_client.unknown_scope", nil, map[string]interface{}{
+			"scopes": unknown,
+		})
+	}
+
+	if denied := corepolicy.NotHeld(ctx, scopes...); len(denied) > 0 {
+		return apperrors.ForbiddenError("oauth_client.scope_not_held", nil, map[string]interface{}{
+			"scopes": denied,
+		})
+	}
+
+	return nil
+}
+
+// normalizeScopes убирает пустые значения и дубликаты
+func normalizeScopes(scopes []string) []string {
+	seen := make(map[string]struct{}, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+	return result
+}
+
+// parsePublicKey разбирает публичный ключ клиента в PEM (RSA, ECDSA или Ed25519)
+func parsePublicKey(pemKey string) (crypto.PublicKey, error) {
+	if strings.TrimSpace(pemKey) == "" {
+		return nil, errors.New("public key is required")
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pemKey)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM([]byte(pemKey)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM([]byte(pemKey)); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key format")
+}
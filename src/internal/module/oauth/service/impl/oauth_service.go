@@ -0,0 +1,420 @@
+package impl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/provider"
+	authModel "github.com/xdevspo/go_tmpl_module_app/internal/module/auth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/jwt"
+)
+
+const (
+	// userCodeAlphabet только согласные: без гласных и легко путаемых символов (RFC 8628, раздел 6.1)
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+	// slowDownIncrement на сколько секунд увеличивается интервал опроса при slow_down
+	slowDownIncrement = 5
+	// userCodeAttempts количество попыток сгенерировать незанятый user_code
+	userCodeAttempts = 3
+	// pgUniqueViolation код ошибки Postgres при нарушении уникальности
+	pgUniqueViolation = "23505"
+)
+
+type oauthService struct {
+	cfg        config.OAuthConfig
+	sp         provider.ServiceProvider
+	jwtManager *jwt.Manager
+}
+
+func NewOAuthService(cfg config.OAuthConfig, sp provider.ServiceProvider, jwtManager *jwt.Manager) service.OAuthService {
+	return &oauthService{
+		cfg:        cfg,
+		sp:         sp,
+		jwtManager: jwtManager,
+	}
+}
+
+// RequestDeviceCode выдает пару device_code/user_code для нового устройства
+func (s *oauthService) RequestDeviceCode(ctx context.Context, req *model.DeviceCodeRequest) (*model.DeviceCodeResponse, error) {
+	const op = "OAuthService.RequestDeviceCode"
+
+	deviceCode, err := randomToken(32)
+	if err != nil {
+		return nil, apperrors.InternalServerError("errors.internal", err, nil)
+	}
+
+	now := time.Now()
+	ttl := s.cfg.DeviceCodeTTLSeconds()
+	repo := s.sp.DeviceAuthorizationRepository(ctx)
+
+	// Отдельной фоновой очистки нет: истёкшие и невостребованные запросы удаляются при создании новых
+	if err := repo.DeleteExpired(ctx); err != nil {
+		s.sp.Logger().WithError(err).WithField("op", op).Warn("Failed to delete expired device authorizations")
+	}
+
+	var userCode string
+	for attempt := 0; attempt < userCodeAttempts; attempt++ {
+		userCode, err = generateUserCode()
+		if err != nil {
+			return nil, apperrors.InternalServerError("errors.internal", err, nil)
+		}
+
+		authorization := &model.DeviceAuthorization{
+			DeviceCodeHash: hashCode(deviceCode),
+			UserCode:       userCode,
+			ClientID:       req.ClientID,
+			Scope:          req.Scope,
+			Status:         model.DeviceStatusPending,
+			PollInterval:   s.cfg.DevicePollIntervalSeconds(),
+			ExpiresAt:      now.Add(time.Duration(ttl) * time.Second),
+			CreatedAt:      now,
+		}
+
+		err = repo.Create(ctx, authorization)
+		if err == nil {
+			break
+		}
+
+		// Совпадение user_code с другим ожидающим запросом - пробуем другой код
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+			return nil, apperrors.InternalServerError("device_authorization.create_error", err, nil)
+		}
+		s.sp.Logger().WithField("op", op).Warn("User code collision, regenerating")
+	}
+	if err != nil {
+		return nil, apperrors.InternalServerError("device_authorization.create_error", err, nil)
+	}
+
+	verificationURI := s.cfg.VerificationURI()
+	formattedCode := formatUserCode(userCode)
+
+	return &model.DeviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formattedCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURIComplete(verificationURI, formattedCode),
+		ExpiresIn:               ttl,
+		Interval:                s.cfg.DevicePollIntervalSeconds(),
+	}, nil
+}
+
+// ResolveDeviceAuthorization подтверждает или отклоняет запрос устройства от имени пользователя
+func (s *oauthService) ResolveDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID, approve bool) error {
+	repo := s.sp.DeviceAuthorizationRepository(ctx)
+
+	authorization, err := repo.GetPendingByUserCode(ctx, normalizeUserCode(userCode))
+	if err != nil {
+		return err
+	}
+	if authorization == nil {
+		return apperrors.NotFoundError("device_authorization.not_found", nil, nil)
+	}
+
+	status := model.DeviceStatusDenied
+	if approve {
+		status = model.DeviceStatusApproved
+	}
+
+	resolved, err := repo.Resolve(ctx, authorization.ID, status, userID)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return apperrors.ConflictError("device_authorization.already_resolved", nil, nil)
+	}
+
+	s.sp.Logger().WithField("device_authorization_id", authorization.ID).
+		WithField("user_id", userID).
+		WithField("status", status).
+		Info("Device authorization resolved")
+
+	return nil
+}
+
+// Token обрабатывает запрос к token endpoint
+func (s *oauthService) Token(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error) {
+	switch req.GrantType {
+	case model.GrantTypeDeviceCode:
+		return s.deviceCodeGrant(ctx, req)
+	case model.GrantTypeClientCredentials:
+		return s.clientCredentialsGrant(ctx, req)
+	case model.GrantTypeTokenExchange:
+		return s.tokenExchangeGrant(ctx, req)
+	default:
+		return nil, model.NewOAuthError(model.ErrCodeUnsupportedGrantType, "")
+	}
+}
+
+// clientCredentialsGrant выдает токен доступа сервисному клиенту (RFC 6749, раздел 4.4).
+// Refresh токен не выдается: клиент всегда может повторно пройти аутентификацию
+func (s *oauthService) clientCredentialsGrant(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error) {
+	const op = "OAuthService.clientCredentialsGrant"
+
+	client, err := s.sp.ClientService(ctx).Authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	for _, scope := range scopes {
+		if !client.AllowsScope(scope) {
+			return nil, model.NewOAuthError(model.ErrCodeInvalidScope, fmt.Sprintf("scope %q is not allowed for client", scope))
+		}
+	}
+
+	accessToken, err := s.jwtManager.GenerateClientToken(client.ID.String(), scopes, jwt.WithDPoPKey(req.DPoPJKT))
+	if err != nil {
+		s.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: failed to generate client token", op))
+		return nil, apperrors.InternalServerError("errors.internal", err, nil)
+	}
+
+	s.sp.Logger().WithField("client_id", client.ID).Info("Client credentials token issued")
+
+	tokenType := "Bearer"
+	if req.DPoPJKT != "" {
+		tokenType = "DPoP"
+	}
+
+	return &model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   tokenType,
+		ExpiresIn:   int64(s.jwtManager.ExpirationMinutes() * 60),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// tokenExchangeGrant обменивает токен пользователя на токен с урезанными правами
+// для вызова стороннего сервиса от его имени (RFC 8693)
+func (s *oauthService) tokenExchangeGrant(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error) {
+	const op = "OAuthService.tokenExchangeGrant"
+
+	client, err := s.sp.ClientService(ctx).Authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SubjectToken == "" || req.Audience == "" {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidRequest, "subject_token and audience are required")
+	}
+	if req.SubjectTokenType != model.TokenTypeAccessToken && req.SubjectTokenType != model.TokenTypeJWT {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidRequest, "unsupported subject_token_type")
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != model.TokenTypeAccessToken {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidRequest, "unsupported requested_token_type")
+	}
+
+	// Обменять можно только действующий токен пользователя, выданный этому API
+	subject, err := s.jwtManager.ValidateToken(req.SubjectToken)
+	if err != nil || subject.IsClientToken() || !subject.IsIntendedFor(s.cfg.TokenAudience()) {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "invalid subject_token")
+	}
+
+	userID, err := uuid.Parse(subject.UserID)
+	if err != nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "invalid subject_token")
+	}
+
+	user, err := s.sp.UserService(ctx).GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Active || user.Suspended {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "subject is not active")
+	}
+
+	rule, err := s.sp.TokenExchangeRuleRepository(ctx).FindByClientAndAudience(ctx, client.ID, req.Audience)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidTarget, "client is not allowed to exchange tokens for this audience")
+	}
+
+	exchangePolicy := policy.NewTokenExchangePolicy()
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = exchangePolicy.DefaultScopes(rule, user)
+	}
+	if !exchangePolicy.Check(ctx, client, rule, user, scopes) {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidScope, "requested scope exceeds what the client or subject may delegate")
+	}
+
+	accessToken, expiresAt, err := s.jwtManager.GenerateDelegatedToken(subject, client.ID.String(), rule.Audience, scopes)
+	if err != nil {
+		s.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: failed to generate delegated token", op))
+		return nil, apperrors.InternalServerError("errors.internal", err, nil)
+	}
+
+	s.sp.Logger().WithField("client_id", client.ID).
+		WithField("user_id", user.ID).
+		WithField("audience", rule.Audience).
+		Info("Token exchanged")
+
+	return &model.TokenResponse{
+		AccessToken:     accessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(time.Until(expiresAt).Seconds()),
+		Scope:           strings.Join(scopes, " "),
+		IssuedTokenType: model.TokenTypeAccessToken,
+	}, nil
+}
+
+// deviceCodeGrant обменивает подтвержденный device_code на пару токенов (RFC 8628, раздел 3.4)
+func (s *oauthService) deviceCodeGrant(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error) {
+	const op = "OAuthService.deviceCodeGrant"
+
+	if req.DeviceCode == "" || req.ClientID == "" {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidRequest, "device_code and client_id are required")
+	}
+
+	repo := s.sp.DeviceAuthorizationRepository(ctx)
+	authorization, err := repo.GetByDeviceCodeHash(ctx, hashCode(req.DeviceCode))
+	if err != nil {
+		return nil, err
+	}
+	if authorization == nil || authorization.ClientID != req.ClientID {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "unknown device_code")
+	}
+
+	if authorization.IsExpired() {
+		return nil, model.NewOAuthError(model.ErrCodeExpiredToken, "")
+	}
+
+	now := time.Now()
+	if authorization.PolledTooSoon(now) {
+		// Клиент обязан увеличить интервал опроса на 5 секунд (RFC 8628, раздел 3.5)
+		if err := repo.UpdatePoll(ctx, authorization.ID, now, authorization.PollInterval+slowDownIncrement); err != nil {
+			return nil, err
+		}
+		return nil, model.NewOAuthError(model.ErrCodeSlowDown, "")
+	}
+
+	if err := repo.UpdatePoll(ctx, authorization.ID, now, authorization.PollInterval); err != nil {
+		return nil, err
+	}
+
+	switch authorization.Status {
+	case model.DeviceStatusPending:
+		return nil, model.NewOAuthError(model.ErrCodeAuthorizationPending, "")
+	case model.DeviceStatusDenied:
+		return nil, model.NewOAuthError(model.ErrCodeAccessDenied, "")
+	case model.DeviceStatusApproved:
+	default:
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "device_code already used")
+	}
+
+	// Помечаем запрос использованным до выдачи токенов, чтобы параллельный опрос не получил вторую пару
+	consumed, err := repo.Consume(ctx, authorization.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed || authorization.UserID == nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "device_code already used")
+	}
+
+	user, err := s.sp.UserService(ctx).GetByID(ctx, *authorization.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "user not found")
+	}
+	if !user.Active || user.Suspended {
+		return nil, model.NewOAuthError(model.ErrCodeInvalidGrant, "user is not active")
+	}
+
+	resp, err := s.sp.AuthService(ctx).IssueTokens(ctx, user, authModel.SessionOptions{
+		DeviceIdentifier: authorization.DeviceIdentifier(),
+		DPoPJKT:          req.DPoPJKT,
+	})
+	if err != nil {
+		s.sp.Logger().WithError(err).Error(fmt.Sprintf("%s: unable to issue tokens", op))
+		return nil, err
+	}
+
+	return model.TokenResponseFromAuth(resp, authorization.Scope), nil
+}
+
+// hashCode возвращает SHA-256 от кода в hex, чтобы в БД не хранились действующие секреты
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken генерирует криптостойкую случайную строку в base64url
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// generateUserCode генерирует короткий код для ручного ввода пользователем
+func generateUserCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(userCodeAlphabet)))
+
+	var sb strings.Builder
+	for i := 0; i < userCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(userCodeAlphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// formatUserCode разбивает код на две группы для удобства ввода: XXXX-XXXX
+func formatUserCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// normalizeUserCode приводит введенный пользователем код к хранимому виду
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// verificationURIComplete добавляет user_code к адресу страницы подтверждения
+func verificationURIComplete(verificationURI, userCode string) string {
+	u, err := url.Parse(verificationURI)
+	if err != nil {
+		return verificationURI
+	}
+
+	query := u.Query()
+	query.Set("user_code", userCode)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
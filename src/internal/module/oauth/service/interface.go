@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/oauth/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/mtls"
+)
+
+// OAuthService defines the interface for OAuth2 grant flows
+type OAuthService interface {
+	// RequestDeviceCode starts a device authorization flow (RFC 8628, section 3.1)
+	RequestDeviceCode(ctx context.Context, req *model.DeviceCodeRequest) (*model.DeviceCodeResponse, error)
+
+	// ResolveDeviceAuthorization approves or denies a pending device authorization on behalf of a logged-in user
+	ResolveDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID, approve bool) error
+
+	// Token handles a token endpoint request and dispatches it by grant type
+	Token(ctx context.Context, req *model.TokenRequest) (*model.TokenResponse, error)
+}
+
+// ClientService defines the interface for OAuth2 client management
+type ClientService interface {
+	// Create registers a confidential client; the plain secret is returned only once
+	Create(ctx context.Context, req *model.CreateClientRequest) (*model.ClientWithSecret, error)
+
+	// Update changes client name, public key, scopes or active flag
+	Update(ctx context.Context, id uuid.UUID, req *model.UpdateClientRequest) (*model.Client, error)
+
+	// Delete removes a client
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetByID returns a client or nil if it doesn't exist
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Client, error)
+
+	// GetClientOrFail returns a client or a not found error
+	GetClientOrFail(ctx context.Context, id uuid.UUID) (*model.Client, error)
+
+	// List returns all clients
+	List(ctx context.Context) ([]*model.Client, error)
+
+	// RotateSecret issues a new secret and invalidates the previous one
+	RotateSecret(ctx context.Context, id uuid.UUID) (*model.ClientWithSecret, error)
+
+	// Authenticate verifies client credentials presented to the token endpoint
+	Authenticate(ctx context.Context, req *model.TokenRequest) (*model.Client, error)
+
+	// ListExchangeRules returns token exchange rules of a client
+	ListExchangeRules(ctx context.Context, clientID uuid.UUID) ([]*model.TokenExchangeRule, error)
+
+	// SaveExchangeRule creates or replaces the token exchange rule for a client and audience
+	SaveExchangeRule(ctx context.Context, clientID uuid.UUID, req *model.SaveTokenExchangeRuleRequest) (*model.TokenExchangeRule, error)
+
+	// DeleteExchangeRule removes a token exchange rule of a client
+	DeleteExchangeRule(ctx context.Context, clientID, ruleID uuid.UUID) error
+
+	// ListCertificateMappings returns TLS client certificate mappings of a client
+	ListCertificateMappings(ctx context.Context, clientID uuid.UUID) ([]*model.CertificateMapping, error)
+
+	// CreateCertificateMapping binds a certificate identity (SPIFFE ID, SAN or subject) to a client
+	CreateCertificateMapping(ctx context.Context, clientID uuid.UUID, req *model.CreateCertificateMappingRequest) (*model.CertificateMapping, error)
+
+	// DeleteCertificateMapping removes a certificate mapping of a client
+	DeleteCertificateMapping(ctx context.Context, clientID, mappingID uuid.UUID) error
+
+	// AuthenticateCertificate checks revocation of a verified TLS client certificate
+	// and resolves the client it is mapped to. The matched identity is returned for audit logging
+	AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*model.Client, *mtls.Identity, error)
+}
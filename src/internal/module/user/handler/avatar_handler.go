@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// UploadAvatar загружает аватар пользователя. Изображение передаётся телом запроса
+// или полем file multipart-формы; тип определяется по содержимому
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			apperrors.ResponseWithError(c, apperrors.BadRequestError("user.avatar_file_required", err, nil))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			apperrors.ResponseWithError(c, apperrors.BadRequestError("user.avatar_file_required", err, nil))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	// Читаем на байт больше лимита, чтобы сервис мог отличить слишком большой файл
+	data, err := io.ReadAll(io.LimitReader(body, model.MaxAvatarFileSize+1))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.avatar_file_required", err, nil))
+		return
+	}
+	if len(data) == 0 {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.avatar_file_required", nil, nil))
+		return
+	}
+
+	user, err := h.userService.SetAvatar(c.Request.Context(), userId, data)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", model.UserETag(user.Version))
+	api.SuccessResponse(c, user)
+}
+
+// DeleteAvatar удаляет аватар пользователя
+func (h *UserHandler) DeleteAvatar(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	if err := h.userService.DeleteAvatar(c.Request.Context(), userId); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.user.avatar_deleted", nil)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/tabular"
+)
+
+// CreateExport создаёт задание на выгрузку пользователей
+func (h *UserHandler) CreateExport(c *gin.Context) {
+	var req model.CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	// Ограничение инициатора фиксируется в задании; значение из тела запроса не принимается
+	req.Filters.Scope = h.scope(c, "export")
+	req.Filters.Tenant = tenant.FromContext(c.Request.Context())
+
+	// Для сервисных клиентов автор задания не сохраняется
+	job, err := h.sp.ExportService(c.Request.Context()).CreateExport(c.Request.Context(), actorID(c), &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Location", c.FullPath()+"/"+job.ID.String())
+	api.CreatedResponse(c, job.ID.String(), job)
+}
+
+// GetExport возвращает состояние задания выгрузки и ссылку на скачивание готового файла
+func (h *UserHandler) GetExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	job, err := h.sp.ExportService(c.Request.Context()).GetExport(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, job)
+}
+
+// DownloadExport отдаёт файл выгрузки по подписанной ссылке; авторизация не требуется
+func (h *UserHandler) DownloadExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.ForbiddenError("export.invalid_signature", err, nil))
+		return
+	}
+
+	job, path, err := h.sp.ExportService(c.Request.Context()).OpenExport(c.Request.Context(), id, expires, c.Query("signature"))
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", tabular.ContentType(tabular.Format(job.Format)))
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, "users-"+job.CreatedAt.Format("20060102-150405")+"."+job.Format)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// ListOrganizations возвращает список организаций
+func (h *UserHandler) ListOrganizations(c *gin.Context) {
+	organizations, err := h.userService.ListOrganizations(c.Request.Context())
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, organizations)
+}
+
+// CreateOrganization создаёт организацию
+func (h *UserHandler) CreateOrganization(c *gin.Context) {
+	var req model.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	organization, err := h.userService.CreateOrganization(c.Request.Context(), &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.CreatedResponse(c, organization.ID.String(), organization)
+}
+
+// GetOrganization возвращает организацию по ID
+func (h *UserHandler) GetOrganization(c *gin.Context) {
+	organizationID, ok := parseUUIDID(c)
+	if !ok {
+		return
+	}
+
+	organization, err := h.userService.GetOrganization(c.Request.Context(), organizationID)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, organization)
+}
+
+// UpdateOrganization изменяет название организации
+func (h *UserHandler) UpdateOrganization(c *gin.Context) {
+	organizationID, ok := parseUUIDID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	organization, err := h.userService.UpdateOrganization(c.Request.Context(), organizationID, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, organization)
+}
+
+// parseUUIDID разбирает параметр :id в формате UUID; при ошибке отвечает 400
+func parseUUIDID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
 // validatePermissionIDs проверяет существование указанных разрешений и возвращает список несуществующих разрешений
@@ -155,3 +160,103 @@ func (h *UserHandler) GetUserPermissionsHandler(c *gin.Context) {
 
 	api.SuccessResponse(c, permissions)
 }
+
+// ListPermissions возвращает каталог разрешений
+func (h *UserHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.userService.GetAllPermissions(c.Request.Context())
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+	if permissions == nil {
+		permissions = []model.Permission{}
+	}
+
+	api.SuccessResponse(c, permissions)
+}
+
+// CreatePermission добавляет разрешение в каталог
+func (h *UserHandler) CreatePermission(c *gin.Context) {
+	var req model.CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	permission, err := h.userService.CreatePermission(c.Request.Context(), req.Name, req.Description)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.CreatedResponse(c, strconv.Itoa(permission.ID), permission)
+}
+
+// GetPermission возвращает разрешение из каталога
+func (h *UserHandler) GetPermission(c *gin.Context) {
+	permissionID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	permission, err := h.userService.GetPermission(c.Request.Context(), permissionID)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permission)
+}
+
+// UpdatePermission изменяет название и описание разрешения
+func (h *UserHandler) UpdatePermission(c *gin.Context) {
+	permissionID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	permission, err := h.userService.UpdatePermission(c.Request.Context(), permissionID, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permission)
+}
+
+// DeletePermission удаляет разрешение. Назначенное разрешение можно удалить только с ?force=true
+func (h *UserHandler) DeletePermission(c *gin.Context) {
+	permissionID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"force": c.Query("force"),
+		}))
+		return
+	}
+
+	if err := h.userService.DeletePermission(c.Request.Context(), permissionID, force); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.permission.deleted", nil)
+}
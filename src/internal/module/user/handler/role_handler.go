@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
 // validateRoleIDs проверяет существование указанных ролей и возвращает список несуществующих ролей
@@ -161,3 +166,280 @@ func (h *UserHandler) GetUserRolesHandler(c *gin.Context) {
 
 	api.SuccessResponse(c, roles)
 }
+
+// ListRoles возвращает все роли
+func (h *UserHandler) ListRoles(c *gin.Context) {
+	roles, err := h.userService.GetAllRoles(c.Request.Context())
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+	if roles == nil {
+		roles = []model.Role{}
+	}
+
+	api.SuccessResponse(c, roles)
+}
+
+// CreateRole создаёт роль
+func (h *UserHandler) CreateRole(c *gin.Context) {
+	var req model.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	role, err := h.userService.CreateRole(c.Request.Context(), req.Name, req.Description)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.CreatedResponse(c, strconv.Itoa(role.ID), role)
+}
+
+// GetRole возвращает роль с её разрешениями
+func (h *UserHandler) GetRole(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.userService.GetRole(c.Request.Context(), roleID)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, role)
+}
+
+// UpdateRole изменяет название и описание роли
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	role, err := h.userService.UpdateRole(c.Request.Context(), roleID, &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, role)
+}
+
+// DeleteRole удаляет роль. Назначенную пользователям роль можно удалить только с ?force=true
+func (h *UserHandler) DeleteRole(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"force": c.Query("force"),
+		}))
+		return
+	}
+
+	if err := h.userService.DeleteRole(c.Request.Context(), roleID, force); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.role.deleted", nil)
+}
+
+// ListRoleMembers возвращает пользователей, которым назначена роль. Поддерживает те же
+// параметры пагинации и фильтры, что и список пользователей
+func (h *UserHandler) ListRoleMembers(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var query model.ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	users, err := h.userService.ListRoleMembers(c.Request.Context(), roleID, &query)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.PaginatedResponse(c, users.Items, api.PaginationMeta{
+		Total:      users.Total,
+		Page:       users.Page,
+		Limit:      users.Limit,
+		NextCursor: users.NextCursor,
+	})
+}
+
+// SetRoleParents заменяет родительские роли, от которых роль наследует разрешения
+func (h *UserHandler) SetRoleParents(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SetRoleParentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	role, err := h.userService.SetRoleParents(c.Request.Context(), roleID, req.ParentIDs)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, role)
+}
+
+// GetRolePermissions возвращает разрешения роли; с ?effective=true - вместе с унаследованными
+func (h *UserHandler) GetRolePermissions(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	effective, err := strconv.ParseBool(c.DefaultQuery("effective", "false"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"effective": c.Query("effective"),
+		}))
+		return
+	}
+
+	var permissions []model.Permission
+	if effective {
+		permissions, err = h.userService.GetRoleEffectivePermissions(c.Request.Context(), roleID)
+	} else {
+		permissions, err = h.userService.GetRolePermissions(c.Request.Context(), roleID)
+	}
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permissions)
+}
+
+// SetRolePermissions заменяет набор разрешений роли целиком
+func (h *UserHandler) SetRolePermissions(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	permissions, err := h.userService.SetRolePermissions(c.Request.Context(), roleID, req.PermissionIDs)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permissions)
+}
+
+// AddRolePermissions добавляет разрешения роли
+func (h *UserHandler) AddRolePermissions(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	permissions, err := h.userService.AddRolePermissions(c.Request.Context(), roleID, req.PermissionIDs)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permissions)
+}
+
+// RemoveRolePermissions снимает разрешения с роли
+func (h *UserHandler) RemoveRolePermissions(c *gin.Context) {
+	roleID, ok := parseIntID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	permissions, err := h.userService.RemoveRolePermissions(c.Request.Context(), roleID, req.PermissionIDs)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, permissions)
+}
+
+// parseIntID разбирает числовой ID (роли, разрешения) из пути; при ошибке отправляет ответ 400
+func parseIntID(c *gin.Context) (int, bool) {
+	roleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || roleID <= 0 {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return 0, false
+	}
+	return roleID, true
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/middleware"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/policy"
 )
@@ -13,26 +14,91 @@ func (h *UserHandler) RegisterRoutes(group *gin.RouterGroup, policyMiddleware *m
 	h.RegisterUserPermissionRoutes(group, policyMiddleware)
 }
 
-// RegisterUserRoutes регистрирует маршруты для управления пользователями
+// RegisterUserRoutes регистрирует маршруты для управления пользователями. Маршруты с :id
+// проверяют политику по атрибутам найденного пользователя: собственную учётную запись
+// пользователь может просматривать, менять её пароль и подтверждать email без разрешений
 func (h *UserHandler) RegisterUserRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
-	group.POST("/:id/change-password", h.ChangePassword)
-	group.GET("/:id/verify-email", h.VerifyEmail)
-
 	group.GET("", policyMiddleware.RequirePermission(policy.ResourceName, "view"), h.ListUsers)
-	group.GET("/:id", policyMiddleware.RequirePermission(policy.ResourceName, "view"), h.GetUserByID)
-	group.DELETE("/:id", policyMiddleware.RequirePermission(policy.ResourceName, "delete"), h.DeleteUser)
+	group.GET("/search", policyMiddleware.RequirePermission(policy.ResourceName, "view"), h.SearchUsers)
+	group.POST("/import", policyMiddleware.RequirePermission(policy.ResourceName, "create"), h.ImportUsers)
+	group.POST("/exports", policyMiddleware.RequirePermission(policy.ResourceName, "export"), h.CreateExport)
+	group.GET("/exports/:id", policyMiddleware.RequirePermission(policy.ResourceName, "export"), h.GetExport)
+
+	user := h.userAction(policyMiddleware, h.resolveUser)
+	deletedUser := h.userAction(policyMiddleware, h.resolveDeletedUser)
+	group.POST("/:id/change-password", user("change-password"), h.ChangePassword)
+	group.GET("/:id/verify-email", user("verify-email"), h.VerifyEmail)
+	group.GET("/:id", user("view"), h.GetUserByID)
+	group.PATCH("/:id", user("update"), h.UpdateUser)
+	group.DELETE("/:id", user("delete"), h.DeleteUser)
+	group.POST("/:id/restore", deletedUser("restore"), h.RestoreUser)
+	group.DELETE("/:id/purge", deletedUser("purge"), h.PurgeUser)
+	group.PUT("/:id/avatar", user("update"), h.UploadAvatar)
+	group.DELETE("/:id/avatar", user("update"), h.DeleteAvatar)
+	group.GET("/:id/suspensions", user("suspend"), h.ListSuspensions)
+	group.POST("/:id/suspensions", user("suspend"), h.SuspendUser)
+	group.POST("/:id/suspensions/lift", user("suspend"), h.LiftSuspension)
 }
 
-// RegisterUserRoleRoutes регистрирует маршруты для управления ролями
+// RegisterUserRoleRoutes регистрирует маршруты для управления ролями пользователя
 func (h *UserHandler) RegisterUserRoleRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
-	group.POST("/:id/roles", policyMiddleware.RequirePermission(policy.ResourceName, "assign-role"), h.AssignRoleHandler)
-	group.DELETE("/:id/roles", policyMiddleware.RequirePermission(policy.ResourceName, "revoke-role"), h.RevokeRoleHandler)
-	group.GET("/:id/roles", policyMiddleware.RequirePermission(policy.ResourceName, "view-roles"), h.GetUserRolesHandler)
+	user := h.userAction(policyMiddleware, h.resolveUser)
+	group.POST("/:id/roles", user("assign-role"), h.AssignRoleHandler)
+	group.DELETE("/:id/roles", user("revoke-role"), h.RevokeRoleHandler)
+	group.GET("/:id/roles", user("view-roles"), h.GetUserRolesHandler)
 }
 
-// RegisterUserPermissionRoutes регистрирует маршруты для управления разрешениями
+// RegisterUserPermissionRoutes регистрирует маршруты для управления разрешениями пользователя
 func (h *UserHandler) RegisterUserPermissionRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
-	group.POST("/:id/permissions", policyMiddleware.RequirePermission(policy.ResourceName, "assign-permission"), h.AssignPermissionsHandler)
-	group.DELETE("/:id/permissions", policyMiddleware.RequirePermission(policy.ResourceName, "revoke-permission"), h.RevokePermissionHandler)
-	group.GET("/:id/permissions", policyMiddleware.RequirePermission(policy.ResourceName, "view-permissions"), h.GetUserPermissionsHandler)
+	user := h.userAction(policyMiddleware, h.resolveUser)
+	group.POST("/:id/permissions", user("assign-permission"), h.AssignPermissionsHandler)
+	group.DELETE("/:id/permissions", user("revoke-permission"), h.RevokePermissionHandler)
+	group.GET("/:id/permissions", user("view-permissions"), h.GetUserPermissionsHandler)
+}
+
+// userAction возвращает проверку действия над пользователем из параметра :id по его атрибутам
+func (h *UserHandler) userAction(policyMiddleware *middleware.PolicyMiddleware, resolve middleware.ResourceResolver) func(action string) gin.HandlerFunc {
+	return func(action string) gin.HandlerFunc {
+		return policyMiddleware.RequireResourcePermission(policy.ResourceName, action, resolve)
+	}
+}
+
+// RegisterRoleRoutes регистрирует маршруты для управления ролями (/roles)
+func (h *UserHandler) RegisterRoleRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
+	group.GET("", policyMiddleware.RequirePermission(policy.RoleResourceName, "view"), h.ListRoles)
+	group.POST("", policyMiddleware.RequirePermission(policy.RoleResourceName, "create"), h.CreateRole)
+	group.GET("/:id", policyMiddleware.RequirePermission(policy.RoleResourceName, "view"), h.GetRole)
+	group.PATCH("/:id", policyMiddleware.RequirePermission(policy.RoleResourceName, "update"), h.UpdateRole)
+	group.DELETE("/:id", policyMiddleware.RequirePermission(policy.RoleResourceName, "delete"), h.DeleteRole)
+	group.GET("/:id/members", policyMiddleware.RequirePermission(policy.RoleResourceName, "view-members"), h.ListRoleMembers)
+	group.PUT("/:id/parents", policyMiddleware.RequirePermission(policy.RoleResourceName, "update"), h.SetRoleParents)
+
+	group.GET("/:id/permissions", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "view"), h.GetRolePermissions)
+	group.PUT("/:id/permissions", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "replace"), h.SetRolePermissions)
+	group.POST("/:id/permissions", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "assign"), h.AddRolePermissions)
+	group.DELETE("/:id/permissions", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "revoke"), h.RemoveRolePermissions)
+}
+
+// RegisterPermissionRoutes регистрирует маршруты каталога разрешений (/permissions)
+func (h *UserHandler) RegisterPermissionRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
+	group.GET("", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "view"), h.ListPermissions)
+	group.POST("", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "create"), h.CreatePermission)
+	group.GET("/:id", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "view"), h.GetPermission)
+	group.PATCH("/:id", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "update"), h.UpdatePermission)
+	group.DELETE("/:id", policyMiddleware.RequirePermission(corepolicy.PermissionResourceName, "delete"), h.DeletePermission)
+}
+
+// RegisterOrganizationRoutes регистрирует маршруты управления организациями (/organizations).
+// Организациями управляет только суперадминистратор платформы
+func (h *UserHandler) RegisterOrganizationRoutes(group *gin.RouterGroup, policyMiddleware *middleware.PolicyMiddleware) {
+	group.GET("", policyMiddleware.RequirePermission(policy.OrganizationResourceName, "view"), h.ListOrganizations)
+	group.POST("", policyMiddleware.RequirePermission(policy.OrganizationResourceName, "create"), h.CreateOrganization)
+	group.GET("/:id", policyMiddleware.RequirePermission(policy.OrganizationResourceName, "view"), h.GetOrganization)
+	group.PATCH("/:id", policyMiddleware.RequirePermission(policy.OrganizationResourceName, "update"), h.UpdateOrganization)
+}
+
+// RegisterExportDownloadRoutes регистрирует маршрут скачивания выгрузки. Доступ проверяется
+// подписью ссылки, поэтому группа не должна требовать аутентификации
+func (h *UserHandler) RegisterExportDownloadRoutes(group *gin.RouterGroup) {
+	group.GET("/:id/download", h.DownloadExport)
 }
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// SuspendUser блокирует пользователя и завершает его сессии
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	var req model.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	suspension, err := h.userService.Suspend(c.Request.Context(), userID, actorID(c), &req, c.ClientIP())
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.CreatedResponse(c, suspension.ID.String(), suspension)
+}
+
+// LiftSuspension досрочно снимает блокировку пользователя. Тело запроса с причиной необязательно
+func (h *UserHandler) LiftSuspension(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	var req model.LiftSuspensionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var validErrs validator.ValidationErrors
+			if !errors.As(err, &validErrs) {
+				err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+			}
+			apperrors.ResponseWithError(c, err)
+			return
+		}
+	}
+
+	suspension, err := h.userService.LiftSuspension(c.Request.Context(), userID, actorID(c), &req)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, suspension)
+}
+
+// ListSuspensions возвращает историю блокировок пользователя
+func (h *UserHandler) ListSuspensions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	suspensions, err := h.userService.ListSuspensions(c.Request.Context(), userID)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, suspensions)
+}
+
+// actorID возвращает ID пользователя, выполняющего запрос; для сервисных клиентов - nil
+func actorID(c *gin.Context) *uuid.UUID {
+	if value, exists := c.Get("userId"); exists {
+		if id, ok := value.(uuid.UUID); ok {
+			return &id
+		}
+	}
+	return nil
+}
@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/api"
 	container "github.com/xdevspo/go_tmpl_module_app/internal/core/container"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/tabular"
 )
 
 // UserHandler обрабатывает HTTP-запросы для модуля пользователей
@@ -55,37 +64,61 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	api.ActionSuccessResponse(c, "response.user.password_changed", nil)
 }
 
-// ListUsers возвращает список пользователей (для админа)
+// ListUsers возвращает список пользователей.
+// Доступ проверяется политикой на уровне маршрута, поэтому вызов возможен и от сервисного клиента
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		apperrors.ResponseWithError(c, apperrors.UnauthorizedError("errors.unauthorized", nil, nil))
+	var query model.ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
 		return
 	}
 
-	// Проверяем наличие прав на основе ролей или разрешений
-	currentUser, ok := user.(*model.User)
-	if !ok {
-		apperrors.ResponseWithError(c, apperrors.InternalServerError("errors.internal", nil, nil))
+	query.Scope = h.scope(c, "view")
+
+	users, err := h.userService.ListUsers(c.Request.Context(), &query)
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
 		return
 	}
 
-	// Проверка на роли admin или full, или разрешение users:read
-	hasAccess := currentUser.HasRole("admin") || currentUser.HasRole("full") || currentUser.HasPermission("users:read")
-	if !hasAccess {
-		apperrors.ResponseWithError(c, apperrors.ForbiddenError("errors.forbidden", nil, map[string]interface{}{
-			"message": "недостаточно прав для просмотра списка пользователей",
-		}))
+	api.PaginatedResponse(c, users.Items, api.PaginationMeta{
+		Total:      users.Total,
+		Page:       users.Page,
+		Limit:      users.Limit,
+		NextCursor: users.NextCursor,
+	})
+}
+
+// SearchUsers выполняет полнотекстовый и нечёткий поиск пользователей.
+// Поле highlight содержит исходные данные с разметкой <mark> и должно экранироваться клиентом
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	var query model.SearchUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
 		return
 	}
 
-	users, err := h.userService.ListUsers(c.Request.Context())
+	query.Scope = h.scope(c, "view")
+
+	result, err := h.userService.SearchUsers(c.Request.Context(), &query)
 	if err != nil {
 		apperrors.ResponseWithError(c, err)
 		return
 	}
 
-	api.SuccessResponse(c, users)
+	api.PaginatedResponse(c, result.Items, api.PaginationMeta{
+		Total: result.Total,
+		Page:  result.Page,
+		Limit: result.Limit,
+	})
 }
 
 // GetUserByID возвращает пользователя по ID
@@ -129,9 +162,220 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		}
 	}
 
+	c.Header("ETag", model.UserETag(user.Version))
 	api.SuccessResponse(c, user)
 }
 
+// UpdateUser частично обновляет пользователя по JSON Merge Patch (RFC 7396).
+// Требует заголовок If-Match с ETag текущей версии; при расхождении версий возвращает 409
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != model.MergePatchContentType && contentType != gin.MIMEJSON {
+		apperrors.ResponseWithError(c, apperrors.NewAppError(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "user.invalid_patch", nil, map[string]any{
+			"content_type": contentType,
+			"expected":     model.MergePatchContentType,
+		}))
+		return
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		apperrors.ResponseWithError(c, apperrors.NewAppError(http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "user.precondition_required", nil, nil))
+		return
+	}
+	expectedVersion, ok := model.ParseUserETag(ifMatch)
+	if !ok {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.invalid_if_match", nil, map[string]any{
+			"if_match": ifMatch,
+		}))
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.invalid_patch", err, nil))
+		return
+	}
+
+	patch, err := model.ParseUserMergePatch(body)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.invalid_patch", err, map[string]any{
+			"message": err.Error(),
+		}))
+		return
+	}
+	if err := apperrors.ValidateRequest(patch); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	user, err := h.userService.Patch(c.Request.Context(), userID, expectedVersion, patch, h.can(c, "update-restricted"))
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", model.UserETag(user.Version))
+	api.SuccessResponse(c, user)
+}
+
+// ImportUsers импортирует пользователей из CSV или XLSX файла (multipart, поле file)
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	var req model.ImportUsersRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var validErrs validator.ValidationErrors
+		if !errors.As(err, &validErrs) {
+			err = apperrors.BadRequestError("errors.invalid_input", err, nil)
+		}
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	var mapping map[string]string
+	if req.Mapping != "" {
+		if err := json.Unmarshal([]byte(req.Mapping), &mapping); err != nil {
+			apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_invalid_mapping", err, nil))
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_file_required", err, nil))
+		return
+	}
+	if fileHeader.Size > model.MaxImportFileSize {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_file_too_large", nil, map[string]any{
+			"max_size": model.MaxImportFileSize,
+		}))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_file_required", err, nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, model.MaxImportFileSize+1))
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_file_required", err, nil))
+		return
+	}
+
+	format, err := tabular.DetectFormat(fileHeader.Filename, data)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_unsupported_format", err, map[string]any{
+			"supported": []tabular.Format{tabular.FormatCSV, tabular.FormatXLSX},
+		}))
+		return
+	}
+
+	table, err := tabular.Read(data, format, tabular.Options{MaxRows: model.MaxImportRows})
+	if err != nil {
+		details := map[string]any{"message": err.Error()}
+		if errors.Is(err, tabular.ErrTooManyRows) {
+			details["max_rows"] = model.MaxImportRows
+		}
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("user.import_invalid_file", err, details))
+		return
+	}
+
+	report, err := h.userService.ImportUsers(c.Request.Context(), table, &model.ImportOptions{
+		Mapping:         mapping,
+		DryRun:          req.DryRun,
+		Upsert:          req.Upsert,
+		Mode:            req.Mode,
+		AllowUpdate:     h.can(c, "update"),
+		AllowRestricted: h.can(c, "update-restricted"),
+	})
+	if err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.SuccessResponse(c, report)
+}
+
+// can проверяет неограниченное право текущего субъекта на действие с пользователями внутри
+// обработчика, например на изменение полей active и data_role. Право, ограниченное по атрибутам
+// ("users:update:data-role"), не учитывается: обработчик не знает, каких пользователей оно затронет
+func (h *UserHandler) can(c *gin.Context, action string) bool {
+	principal := requestPrincipal(c)
+	if principal == nil {
+		return false
+	}
+
+	userPolicy := policy.NewUserPolicy()
+	return userPolicy.Check(c.Request.Context(), principal, policy.ResourceName, action) &&
+		userPolicy.Scope(c.Request.Context(), principal, policy.ResourceName, action) == nil
+}
+
+// scope возвращает ограничение выборки пользователей для действия текущего субъекта
+func (h *UserHandler) scope(c *gin.Context, action string) *corepolicy.Scope {
+	principal := requestPrincipal(c)
+	if principal == nil {
+		return corepolicy.DenyAll()
+	}
+	return policy.NewUserPolicy().Scope(c.Request.Context(), principal, policy.ResourceName, action)
+}
+
+// resolveUser находит пользователя из параметра :id для проверки политик по атрибутам.
+// Для текущего пользователя атрибуты берутся из контекста без обращения к БД
+func (h *UserHandler) resolveUser(c *gin.Context) (corepolicy.Attributes, error) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		})
+	}
+
+	if value, exists := c.Get("user"); exists {
+		if current, ok := value.(*model.User); ok && current.ID == userID {
+			return current.Attributes(), nil
+		}
+	}
+
+	return h.userService.GetAccessAttributes(c.Request.Context(), userID)
+}
+
+// resolveDeletedUser находит удалённого пользователя из параметра :id для восстановления
+// и окончательного удаления: resolveUser удалённых пользователей не видит
+func (h *UserHandler) resolveDeletedUser(c *gin.Context) (corepolicy.Attributes, error) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		})
+	}
+
+	return h.userService.GetDeletedAccessAttributes(c.Request.Context(), userID)
+}
+
+// requestPrincipal возвращает субъект запроса из контекста или nil
+func requestPrincipal(c *gin.Context) corepolicy.Principal {
+	value, exists := c.Get("principal")
+	if !exists {
+		value, exists = c.Get("user")
+	}
+	if !exists {
+		return nil
+	}
+
+	principal, _ := value.(corepolicy.Principal)
+	return principal
+}
+
 // DeleteUser удаляет пользователя
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
@@ -151,6 +395,44 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	api.ActionSuccessResponse(c, "response.user.deleted", nil)
 }
 
+// RestoreUser восстанавливает удалённого пользователя
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id := c.Param("id")
+	userId, err := uuid.Parse(id)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	if err := h.userService.Restore(c.Request.Context(), userId); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.user.restored", nil)
+}
+
+// PurgeUser окончательно удаляет пользователя без возможности восстановления
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	id := c.Param("id")
+	userId, err := uuid.Parse(id)
+	if err != nil {
+		apperrors.ResponseWithError(c, apperrors.BadRequestError("errors.invalid_input", err, map[string]interface{}{
+			"message": "неверный формат ID",
+		}))
+		return
+	}
+
+	if err := h.userService.Purge(c.Request.Context(), userId); err != nil {
+		apperrors.ResponseWithError(c, err)
+		return
+	}
+
+	api.ActionSuccessResponse(c, "response.user.purged", nil)
+}
+
 // VerifyEmail подтверждает email пользователя
 func (h *UserHandler) VerifyEmail(c *gin.Context) {
 	id := c.Param("id")
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strconv"
+)
+
+const (
+	// MaxAvatarFileSize максимальный размер загружаемого файла аватара
+	MaxAvatarFileSize = 5 << 20
+	// MaxAvatarPixels максимальное число пикселей исходного изображения (защита от распаковки огромных картинок)
+	MaxAvatarPixels = 40_000_000
+	// AvatarSize сторона основного изображения аватара
+	AvatarSize = 512
+	// AvatarQuality качество JPEG для аватаров
+	AvatarQuality = 85
+)
+
+// AvatarThumbnailSizes стороны миниатюр аватара
+var AvatarThumbnailSizes = []int{256, 64}
+
+// avatarURLResolver строит публичный адрес файла по ключу хранилища
+var avatarURLResolver func(key string) string
+
+// SetAvatarURLResolver задаёт функцию построения ссылок на аватары; вызывается при инициализации приложения
+func SetAvatarURLResolver(resolver func(key string) string) {
+	avatarURLResolver = resolver
+}
+
+// AvatarObjectKey ключ файла аватара заданного размера
+func AvatarObjectKey(prefix string, size int) string {
+	return prefix + "/" + strconv.Itoa(size) + ".jpg"
+}
+
+// AvatarObjectKeys ключи всех файлов аватара: основного изображения и миниатюр
+func AvatarObjectKeys(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+
+	keys := []string{AvatarObjectKey(prefix, AvatarSize)}
+	for _, size := range AvatarThumbnailSizes {
+		keys = append(keys, AvatarObjectKey(prefix, size))
+	}
+	return keys
+}
+
+// avatarURLs возвращает ссылку на аватар и ссылки на миниатюры по их размеру
+func avatarURLs(prefix string) (string, map[string]string) {
+	if prefix == "" || avatarURLResolver == nil {
+		return "", nil
+	}
+
+	thumbnails := make(map[string]string, len(AvatarThumbnailSizes))
+	for _, size := range AvatarThumbnailSizes {
+		thumbnails[strconv.Itoa(size)] = avatarURLResolver(AvatarObjectKey(prefix, size))
+	}
+	return avatarURLResolver(AvatarObjectKey(prefix, AvatarSize)), thumbnails
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+)
+
+// Статусы задания выгрузки
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportColumns колонки файла выгрузки. Пароль и другие секреты в выгрузку не попадают:
+// строки строятся из UserDTO, в котором их нет
+var ExportColumns = []string{
+	"id", "email", "first_name", "last_name", "middle_name", "phone", "position",
+	"active", "data_role", "email_verified", "last_login", "created_at", "updated_at",
+	"roles", "permissions",
+}
+
+// ExportFilters фильтры выборки пользователей для выгрузки
+type ExportFilters struct {
+	Active        *bool      `json:"active,omitempty"`
+	EmailVerified *bool      `json:"email_verified,omitempty"`
+	Role          string     `json:"role,omitempty"`
+	Permission    string     `json:"permission,omitempty"`
+	DataRole      string     `json:"data_role,omitempty"`
+	CreatedFrom   *time.Time `json:"created_from,omitempty"`
+	CreatedTo     *time.Time `json:"created_to,omitempty"`
+	Deleted       string     `json:"deleted,omitempty" binding:"omitempty,oneof=exclude include only"`
+
+	// Scope ограничение выборки по политике доступа инициатора. Сохраняется вместе с заданием,
+	// так как выгрузка выполняется в фоне; значение из запроса обработчик перезаписывает
+	Scope *corepolicy.Scope `json:"scope,omitempty"`
+
+	// Tenant арендатор инициатора: выгрузка ограничивается его организацией. Заполняется обработчиком
+	Tenant *tenant.Tenant `json:"tenant,omitempty"`
+}
+
+// UserFilter преобразует фильтры выгрузки в фильтр репозитория
+func (f *ExportFilters) UserFilter() *UserFilter {
+	filter := &UserFilter{
+		Active:        f.Active,
+		EmailVerified: f.EmailVerified,
+		Role:          f.Role,
+		Permission:    f.Permission,
+		DataRole:      f.DataRole,
+		Deleted:       f.Deleted,
+		Sort:          DefaultUserSort,
+		Order:         SortOrderAsc,
+		Scope:         f.Scope,
+	}
+	if filter.Deleted == "" {
+		filter.Deleted = DeletedExclude
+	}
+	if f.CreatedFrom != nil {
+		from := f.CreatedFrom.UTC()
+		filter.CreatedFrom = &from
+	}
+	if f.CreatedTo != nil {
+		to := f.CreatedTo.UTC()
+		filter.CreatedTo = &to
+	}
+	return filter
+}
+
+// CreateExportRequest запрос на создание выгрузки
+type CreateExportRequest struct {
+	Format  string        `json:"format" binding:"required,oneof=csv jsonl xlsx"`
+	Filters ExportFilters `json:"filters"`
+}
+
+// ExportJob задание выгрузки в БД
+type ExportJob struct {
+	ID          uuid.UUID     `db:"id"`
+	RequestedBy *uuid.UUID    `db:"requested_by"`
+	Format      string        `db:"format"`
+	Filters     ExportFilters `db:"filters"`
+	Status      string        `db:"status"`
+	FilePath    string        `db:"file_path"`
+	RowCount    int           `db:"row_count"`
+	Error       string        `db:"error"`
+	CreatedAt   time.Time     `db:"created_at"`
+	StartedAt   *time.Time    `db:"started_at"`
+	FinishedAt  *time.Time    `db:"finished_at"`
+	ExpiresAt   *time.Time    `db:"expires_at"`
+
+	// OrganizationID организация инициатора: задание доступно только в ней; nil - уровень платформы
+	OrganizationID *uuid.UUID `db:"organization_id"`
+}
+
+// ExportJobDTO задание выгрузки для API
+type ExportJobDTO struct {
+	ID                uuid.UUID     `json:"id"`
+	Format            string        `json:"format"`
+	Filters           ExportFilters `json:"filters"`
+	Status            string        `json:"status"`
+	RowCount          int           `json:"rowCount"`
+	Error             string        `json:"error,omitempty"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	StartedAt         *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt        *time.Time    `json:"finishedAt,omitempty"`
+	ExpiresAt         *time.Time    `json:"expiresAt,omitempty"`
+	DownloadURL       string        `json:"downloadUrl,omitempty"`
+	DownloadExpiresAt *time.Time    `json:"downloadExpiresAt,omitempty"`
+}
+
+// ExportRow строка файла выгрузки в порядке ExportColumns
+func ExportRow(user *UserDTO, roles, permissions []string) []string {
+	lastLogin := ""
+	if user.LastLogin != nil {
+		lastLogin = user.LastLogin.Format(time.RFC3339)
+	}
+
+	return []string{
+		user.ID.String(),
+		user.Email,
+		user.FirstName,
+		user.LastName,
+		user.MiddleName,
+		user.Phone,
+		user.Position,
+		strconv.FormatBool(user.Active),
+		user.DataRole,
+		strconv.FormatBool(user.EmailVerified),
+		lastLogin,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+		strings.Join(roles, ", "),
+		strings.Join(permissions, ", "),
+	}
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"strings"
+)
+
+const (
+	// MaxImportRows максимальное число строк данных в файле импорта
+	MaxImportRows = 5000
+	// MaxImportFileSize максимальный размер файла импорта
+	MaxImportFileSize = 10 << 20
+
+	// ImportModeTransactional все строки сохраняются в одной транзакции: любая ошибка отменяет импорт
+	ImportModeTransactional = "transactional"
+	// ImportModeBestEffort каждая строка сохраняется отдельно, ошибочные строки пропускаются
+	ImportModeBestEffort = "best_effort"
+)
+
+// Поля пользователя, которые можно загрузить из файла
+const (
+	ImportFieldEmail      = "email"
+	ImportFieldFirstName  = "first_name"
+	ImportFieldLastName   = "last_name"
+	ImportFieldMiddleName = "middle_name"
+	ImportFieldPhone      = "phone"
+	ImportFieldPosition   = "position"
+	ImportFieldActive     = "active"
+	ImportFieldDataRole   = "data_role"
+	ImportFieldPassword   = "password"
+	ImportFieldRoles      = "roles"
+)
+
+// ImportFields все поддерживаемые поля импорта
+var ImportFields = []string{
+	ImportFieldEmail, ImportFieldFirstName, ImportFieldLastName, ImportFieldMiddleName,
+	ImportFieldPhone, ImportFieldPosition, ImportFieldActive, ImportFieldDataRole,
+	ImportFieldPassword, ImportFieldRoles,
+}
+
+// ImportRequiredFields поля, колонки для которых обязательны
+var ImportRequiredFields = []string{ImportFieldEmail, ImportFieldFirstName, ImportFieldLastName}
+
+// ImportUsersRequest параметры импорта из multipart-формы
+type ImportUsersRequest struct {
+	// Mapping JSON-объект "поле пользователя" -> "заголовок колонки в файле"
+	Mapping string `form:"mapping"`
+	DryRun  bool   `form:"dry_run"`
+	Upsert  bool   `form:"upsert"`
+	Mode    string `form:"mode" binding:"omitempty,oneof=transactional best_effort"`
+}
+
+// ImportOptions параметры импорта для сервиса
+type ImportOptions struct {
+	// Mapping заголовки колонок для полей; поле без сопоставления ищется по своему имени
+	Mapping map[string]string
+	DryRun  bool
+	// Upsert обновлять существующих пользователей с тем же email вместо ошибки
+	Upsert bool
+	Mode   string
+	// AllowUpdate субъекту разрешено изменять существующих пользователей
+	AllowUpdate bool
+	// AllowRestricted субъекту разрешено изменять active и data_role существующих пользователей
+	AllowRestricted bool
+}
+
+// ImportUserRow строка файла импорта после разбора
+type ImportUserRow struct {
+	Line       int
+	Email      string `validate:"required,email,max=255"`
+	FirstName  string `validate:"required,max=100"`
+	LastName   string `validate:"required,max=100"`
+	MiddleName string `validate:"max=100"`
+	Phone      string `validate:"max=100"`
+	Position   string `validate:"max=255"`
+	Active     *bool
+	DataRole   string `validate:"max=100"`
+	Password   string `validate:"omitempty,min=8,max=72"`
+	Roles      []string
+}
+
+// importRowFields соответствие полей структуры строки полям импорта для отчёта об ошибках
+var importRowFields = map[string]string{
+	"Email":      ImportFieldEmail,
+	"FirstName":  ImportFieldFirstName,
+	"LastName":   ImportFieldLastName,
+	"MiddleName": ImportFieldMiddleName,
+	"Phone":      ImportFieldPhone,
+	"Position":   ImportFieldPosition,
+	"DataRole":   ImportFieldDataRole,
+	"Password":   ImportFieldPassword,
+}
+
+// ImportRowField возвращает имя поля импорта по имени поля структуры ImportUserRow
+func ImportRowField(structField string) string {
+	if field, ok := importRowFields[structField]; ok {
+		return field
+	}
+	return structField
+}
+
+// ParseImportBool разбирает логическое значение ячейки
+func ParseImportBool(value string) (*bool, bool) {
+	var result bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return nil, true
+	case "1", "true", "yes", "y", "да", "+":
+		result = true
+	case "0", "false", "no", "n", "нет", "-":
+		result = false
+	default:
+		return nil, false
+	}
+	return &result, true
+}
+
+// ParseImportList разбирает список значений, разделённых запятой или точкой с запятой
+func ParseImportList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// ImportRowError ошибка обработки строки файла
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ImportReport результат импорта
+type ImportReport struct {
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dryRun"`
+	// Committed изменения сохранены в БД (хотя бы частично в режиме best_effort)
+	Committed bool `json:"committed"`
+	Total     int  `json:"total"`
+	// Created и Updated в режиме dry-run содержат число строк, которые были бы созданы или обновлены
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+)
+
+const (
+	// DefaultUserListLimit размер страницы по умолчанию
+	DefaultUserListLimit = 20
+	// MaxUserListLimit максимальный размер страницы
+	MaxUserListLimit = 100
+
+	// DefaultUserSort поле сортировки по умолчанию
+	DefaultUserSort = "created_at"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	// Режимы выборки удалённых пользователей
+	DeletedExclude = "exclude"
+	DeletedInclude = "include"
+	DeletedOnly    = "only"
+)
+
+// ErrInvalidCursor возвращается при некорректном курсоре пагинации
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListUsersQuery параметры запроса списка пользователей
+type ListUsersQuery struct {
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor        string     `form:"cursor"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=created_at updated_at email first_name last_name"`
+	Order         string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Active        *bool      `form:"active"`
+	EmailVerified *bool      `form:"email_verified"`
+	Role          string     `form:"role"`
+	Permission    string     `form:"permission"`
+	DataRole      string     `form:"data_role"`
+	CreatedFrom   *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo     *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Deleted       string     `form:"deleted" binding:"omitempty,oneof=exclude include only"`
+
+	// Scope ограничение выборки по политике доступа, задаётся обработчиком
+	Scope *corepolicy.Scope `form:"-"`
+}
+
+// UserFilter условия выборки пользователей для репозитория
+type UserFilter struct {
+	Active        *bool
+	EmailVerified *bool
+	Role          string
+	Permission    string
+	DataRole      string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	Deleted       string
+
+	Sort   string
+	Order  string
+	Limit  int
+	Offset int
+	After  *UserCursor
+
+	// Scope ограничение выборки по атрибутам пользователей; nil - без ограничений
+	Scope *corepolicy.Scope
+}
+
+// UserList страница списка пользователей
+type UserList struct {
+	Items      []*UserDTO
+	Total      int64
+	Page       int
+	Limit      int
+	NextCursor string
+}
+
+// UserCursor позиция в списке пользователей для keyset-пагинации.
+// Хранит значение поля сортировки и ID последней записи страницы
+type UserCursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode кодирует курсор в непрозрачную строку
+func (c *UserCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor разбирает строку курсора
+func DecodeUserCursor(s string) (*UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c UserCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// SortValue возвращает значение поля сортировки в виде строки для курсора
+func (um *UserModel) SortValue(field string) string {
+	switch field {
+	case "updated_at":
+		return um.UpdatedAt.Format(time.RFC3339Nano)
+	case "email":
+		return um.Email
+	case "first_name":
+		return um.FirstName
+	case "last_name":
+		return um.LastName
+	default:
+		return um.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationSlugPattern допустимый slug организации: латиница в нижнем регистре, цифры и дефисы
+var OrganizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Organization организация (арендатор), данные которой изолированы от других организаций
+type Organization struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// CreateOrganizationRequest запрос на создание организации
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Slug string `json:"slug" binding:"required,max=100"`
+}
+
+// UpdateOrganizationRequest запрос на изменение организации; slug не меняется,
+// так как на него могут ссылаться внешние системы
+type UpdateOrganizationRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=255"`
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MergePatchContentType тип содержимого JSON Merge Patch (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+var (
+	// ErrPatchNotObject тело патча не является JSON-объектом
+	ErrPatchNotObject = errors.New("merge patch must be a JSON object")
+	// ErrPatchUnknownField патч содержит поле, которое нельзя изменить
+	ErrPatchUnknownField = errors.New("merge patch contains unknown field")
+	// ErrPatchNullField патч удаляет обязательное поле
+	ErrPatchNullField = errors.New("merge patch removes required field")
+)
+
+// UserPatch изменения профиля пользователя, полученные из JSON Merge Patch.
+// nil означает, что поле не передано и остаётся без изменений
+type UserPatch struct {
+	Email      *string `json:"email" validate:"omitempty,email,max=255"`
+	FirstName  *string `json:"first_name" validate:"omitempty,min=1,max=100"`
+	LastName   *string `json:"last_name" validate:"omitempty,min=1,max=100"`
+	MiddleName *string `json:"middle_name" validate:"omitempty,max=100"`
+	Phone      *string `json:"phone" validate:"omitempty,max=100"`
+	Position   *string `json:"position" validate:"omitempty,max=255"`
+	Active     *bool   `json:"active"`
+	DataRole   *string `json:"data_role" validate:"omitempty,min=1,max=100"`
+}
+
+// userPatchNullable поля, которые патч может удалить (null), сбросив в пустую строку
+var userPatchNullable = map[string]bool{
+	"middle_name": true,
+	"phone":       true,
+	"position":    true,
+}
+
+// ParseUserMergePatch разбирает JSON Merge Patch профиля пользователя.
+// Неизвестные поля отклоняются, null допустим только для необязательных полей
+func ParseUserMergePatch(body []byte) (*UserPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return nil, ErrPatchNotObject
+	}
+
+	patch := &UserPatch{}
+	targets := map[string]any{
+		"email":       &patch.Email,
+		"first_name":  &patch.FirstName,
+		"last_name":   &patch.LastName,
+		"middle_name": &patch.MiddleName,
+		"phone":       &patch.Phone,
+		"position":    &patch.Position,
+		"active":      &patch.Active,
+		"data_role":   &patch.DataRole,
+	}
+
+	for field, raw := range doc {
+		target, ok := targets[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPatchUnknownField, field)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if !userPatchNullable[field] {
+				return nil, fmt.Errorf("%w: %s", ErrPatchNullField, field)
+			}
+			empty := ""
+			*target.(**string) = &empty
+			continue
+		}
+
+		if err := json.Unmarshal(raw, target); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+	}
+
+	return patch, nil
+}
+
+// IsEmpty проверяет, что патч не содержит изменений
+func (p *UserPatch) IsEmpty() bool {
+	return p.Email == nil && p.FirstName == nil && p.LastName == nil && p.MiddleName == nil &&
+		p.Phone == nil && p.Position == nil && p.Active == nil && p.DataRole == nil
+}
+
+// RestrictedFields возвращает изменяемые поля, доступные только администраторам
+func (p *UserPatch) RestrictedFields() []string {
+	fields := make([]string, 0, 2)
+	if p.Active != nil {
+		fields = append(fields, "active")
+	}
+	if p.DataRole != nil {
+		fields = append(fields, "data_role")
+	}
+	return fields
+}
+
+// Apply применяет изменения к модели пользователя
+func (p *UserPatch) Apply(user *UserModel) {
+	if p.Email != nil && *p.Email != user.Email {
+		user.Email = *p.Email
+		// Новый адрес требует повторного подтверждения
+		user.EmailVerified = false
+	}
+	if p.FirstName != nil {
+		user.FirstName = *p.FirstName
+	}
+	if p.LastName != nil {
+		user.LastName = *p.LastName
+	}
+	if p.MiddleName != nil {
+		user.MiddleName = *p.MiddleName
+	}
+	if p.Phone != nil {
+		user.Phone = *p.Phone
+	}
+	if p.Position != nil {
+		user.Position = *p.Position
+	}
+	if p.Active != nil {
+		user.Active = *p.Active
+	}
+	if p.DataRole != nil {
+		user.DataRole = *p.DataRole
+	}
+}
+
+// UserETag формирует ETag по версии записи пользователя
+func UserETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+// ParseUserETag извлекает версию из значения If-Match. "*" соответствует любой версии (0)
+func ParseUserETag(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return 0, true
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.TrimSuffix(strings.TrimPrefix(value, `"v`), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
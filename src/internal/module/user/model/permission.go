@@ -0,0 +1,18 @@
+package model
+
+// CreatePermissionRequest запрос на создание разрешения
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// UpdatePermissionRequest изменения разрешения; не переданные поля остаются без изменений
+type UpdatePermissionRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// RolePermissionsRequest набор разрешений роли. Для PUT пустой список снимает все разрешения
+type RolePermissionsRequest struct {
+	PermissionIDs []int `json:"permission_ids" binding:"required,dive,min=1"`
+}
@@ -0,0 +1,237 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserPreferences пользовательские настройки. Хранятся в users.preferences (JSONB)
+type UserPreferences struct {
+	Language           string `json:"language,omitempty" validate:"omitempty,oneof=en ru"`
+	Timezone           string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Theme              string `json:"theme,omitempty" validate:"omitempty,oneof=light dark system"`
+	EmailNotifications *bool  `json:"email_notifications,omitempty"`
+}
+
+// Profile профиль текущего пользователя для self-service API. Не содержит пароля и служебных полей
+type Profile struct {
+	ID            uuid.UUID       `json:"id"`
+	Email         string          `json:"email"`
+	FirstName     string          `json:"firstName"`
+	LastName      string          `json:"lastName"`
+	MiddleName    string          `json:"middleName"`
+	Phone         string          `json:"phone"`
+	Position      string          `json:"position"`
+	EmailVerified bool            `json:"emailVerified"`
+	LastLogin     *time.Time      `json:"lastLogin,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	Version       int             `json:"version"`
+	Preferences   UserPreferences `json:"preferences"`
+	Roles         []string        `json:"roles"`
+	Permissions   []string        `json:"permissions"`
+
+	AvatarURL        string            `json:"avatarUrl,omitempty"`
+	AvatarThumbnails map[string]string `json:"avatarThumbnails,omitempty"`
+}
+
+// ProfileFromUser строит профиль из бизнес-модели пользователя.
+// Разрешения включают как прямые, так и полученные через роли
+func ProfileFromUser(user *User) *Profile {
+	roles := make([]string, 0, len(user.Roles))
+	permissions := make([]string, 0, len(user.Permissions))
+	seen := make(map[string]bool)
+
+	addPermission := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			permissions = append(permissions, name)
+		}
+	}
+
+	for _, permission := range user.Permissions {
+		addPermission(permission.Name)
+	}
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+		for _, permission := range role.Permissions {
+			addPermission(permission.Name)
+		}
+	}
+
+	return &Profile{
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		MiddleName:    user.MiddleName,
+		Phone:         user.Phone,
+		Position:      user.Position,
+		EmailVerified: user.EmailVerified,
+		LastLogin:     user.LastLogin,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Version:       user.Version,
+		Preferences:   user.Preferences,
+		Roles:         roles,
+		Permissions:   permissions,
+
+		AvatarURL:        user.AvatarURL,
+		AvatarThumbnails: user.AvatarThumbnails,
+	}
+}
+
+// ProfilePatch изменения собственного профиля, полученные из JSON Merge Patch.
+// Email, активность и data_role через self-service не меняются
+type ProfilePatch struct {
+	FirstName  *string `json:"first_name" validate:"omitempty,min=1,max=100"`
+	LastName   *string `json:"last_name" validate:"omitempty,min=1,max=100"`
+	MiddleName *string `json:"middle_name" validate:"omitempty,max=100"`
+	Phone      *string `json:"phone" validate:"omitempty,max=100"`
+	Position   *string `json:"position" validate:"omitempty,max=255"`
+	// Preferences вложенный merge patch настроек; null сбрасывает все настройки
+	Preferences json.RawMessage `json:"preferences"`
+}
+
+// ParseProfileMergePatch разбирает JSON Merge Patch собственного профиля
+func ParseProfileMergePatch(body []byte) (*ProfilePatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return nil, ErrPatchNotObject
+	}
+
+	patch := &ProfilePatch{}
+	targets := map[string]**string{
+		"first_name":  &patch.FirstName,
+		"last_name":   &patch.LastName,
+		"middle_name": &patch.MiddleName,
+		"phone":       &patch.Phone,
+		"position":    &patch.Position,
+	}
+
+	for field, raw := range doc {
+		if field == "preferences" {
+			patch.Preferences = raw
+			continue
+		}
+
+		target, ok := targets[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPatchUnknownField, field)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if !userPatchNullable[field] {
+				return nil, fmt.Errorf("%w: %s", ErrPatchNullField, field)
+			}
+			empty := ""
+			*target = &empty
+			continue
+		}
+
+		if err := json.Unmarshal(raw, target); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+	}
+
+	return patch, nil
+}
+
+// IsEmpty проверяет, что патч не содержит изменений
+func (p *ProfilePatch) IsEmpty() bool {
+	return p.FirstName == nil && p.LastName == nil && p.MiddleName == nil &&
+		p.Phone == nil && p.Position == nil && p.Preferences == nil
+}
+
+// Apply применяет изменения к модели пользователя. Настройки объединяются по RFC 7396;
+// неизвестные ключи настроек отклоняются
+func (p *ProfilePatch) Apply(user *UserModel) error {
+	if p.FirstName != nil {
+		user.FirstName = *p.FirstName
+	}
+	if p.LastName != nil {
+		user.LastName = *p.LastName
+	}
+	if p.MiddleName != nil {
+		user.MiddleName = *p.MiddleName
+	}
+	if p.Phone != nil {
+		user.Phone = *p.Phone
+	}
+	if p.Position != nil {
+		user.Position = *p.Position
+	}
+
+	if p.Preferences == nil {
+		return nil
+	}
+
+	current, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return err
+	}
+	merged, err := mergePatch(current, p.Preferences)
+	if err != nil {
+		return err
+	}
+
+	var preferences UserPreferences
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&preferences); err != nil {
+		return fmt.Errorf("%w: preferences: %v", ErrPatchUnknownField, err)
+	}
+	user.Preferences = preferences
+
+	return nil
+}
+
+// mergePatch применяет JSON Merge Patch (RFC 7396) к документу
+func mergePatch(target, patch []byte) ([]byte, error) {
+	var patchDoc any
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, err
+	}
+
+	patchObject, ok := patchDoc.(map[string]any)
+	if !ok {
+		// Не объект (в том числе null) полностью заменяет документ
+		if patchDoc == nil {
+			return []byte("{}"), nil
+		}
+		return nil, ErrPatchNotObject
+	}
+
+	var targetObject map[string]any
+	if err := json.Unmarshal(target, &targetObject); err != nil || targetObject == nil {
+		targetObject = map[string]any{}
+	}
+
+	return json.Marshal(mergeObjects(targetObject, patchObject))
+}
+
+func mergeObjects(target, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		patchChild, isObject := value.(map[string]any)
+		if !isObject {
+			target[key] = value
+			continue
+		}
+
+		targetChild, _ := target[key].(map[string]any)
+		if targetChild == nil {
+			targetChild = map[string]any{}
+		}
+		target[key] = mergeObjects(targetChild, patchChild)
+	}
+	return target
+}
@@ -1,5 +1,9 @@
 package model
 
+import (
+	"github.com/google/uuid"
+)
+
 type RoleRequest struct {
 	Name string `json:"name"`
 }
@@ -21,4 +25,8 @@ type CreateUserRequest struct {
 	DataRole             string              `json:"data_role"`
 	Roles                []RoleRequest       `json:"roles"`
 	Permissions          []PermissionRequest `json:"permissions"`
+
+	// OrganizationID организация нового пользователя. Учитывается только для суперадминистратора
+	// платформы; пользователь организации создаётся в организации создателя
+	OrganizationID *uuid.UUID `json:"organization_id"`
 }
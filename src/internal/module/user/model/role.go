@@ -0,0 +1,18 @@
+package model
+
+// CreateRoleRequest запрос на создание роли. Системные роли создаются только миграциями
+type CreateRoleRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// SetRoleParentsRequest новый набор родительских ролей; пустой список убирает наследование
+type SetRoleParentsRequest struct {
+	ParentIDs []int `json:"parent_ids" binding:"required,dive,min=1"`
+}
+
+// UpdateRoleRequest изменения роли; не переданные поля остаются без изменений
+type UpdateRoleRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
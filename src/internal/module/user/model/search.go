@@ -0,0 +1,49 @@
+package model
+
+import (
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+)
+
+// SearchUsersQuery параметры поиска пользователей
+type SearchUsersQuery struct {
+	Q     string `form:"q" binding:"required,min=2,max=100"`
+	Page  int    `form:"page" binding:"omitempty,min=1"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	// Scope ограничение выборки по политике доступа, задаётся обработчиком
+	Scope *corepolicy.Scope `form:"-"`
+}
+
+// UserSearchFilter параметры поиска для репозитория
+type UserSearchFilter struct {
+	// Terms нормализованные слова запроса (только буквы и цифры)
+	Terms []string
+	// Text исходная строка запроса в нижнем регистре
+	Text   string
+	Limit  int
+	Offset int
+
+	Scope *corepolicy.Scope
+}
+
+// UserSearchRow строка результата поиска в БД
+type UserSearchRow struct {
+	UserModel
+	Rank      float64 `db:"rank"`
+	Highlight string  `db:"highlight"`
+}
+
+// UserSearchHit найденный пользователь с релевантностью и подсветкой совпадений
+type UserSearchHit struct {
+	*UserDTO
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+// UserSearchResult страница результатов поиска
+type UserSearchResult struct {
+	Items []*UserSearchHit
+	Total int64
+	Page  int
+	Limit int
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Статусы блокировки пользователя
+const (
+	SuspensionStatusActive  = "active"
+	SuspensionStatusExpired = "expired"
+	SuspensionStatusLifted  = "lifted"
+)
+
+// SuspendUserRequest запрос на блокировку пользователя. Без ends_at блокировка бессрочная
+type SuspendUserRequest struct {
+	Reason string     `json:"reason" binding:"required,max=1000"`
+	EndsAt *time.Time `json:"ends_at"`
+}
+
+// LiftSuspensionRequest запрос на досрочное снятие блокировки
+type LiftSuspensionRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// Suspension блокировка пользователя в БД. Status вычисляется запросом относительно текущего времени БД
+type Suspension struct {
+	ID          uuid.UUID  `db:"id"`
+	UserID      uuid.UUID  `db:"user_id"`
+	Reason      string     `db:"reason"`
+	SuspendedBy *uuid.UUID `db:"suspended_by"`
+	StartsAt    time.Time  `db:"starts_at"`
+	EndsAt      *time.Time `db:"ends_at"`
+	LiftedAt    *time.Time `db:"lifted_at"`
+	LiftedBy    *uuid.UUID `db:"lifted_by"`
+	LiftReason  string     `db:"lift_reason"`
+	CreatedAt   time.Time  `db:"created_at"`
+	Status      string     `db:"status"`
+}
+
+// SuspensionDTO блокировка пользователя для API
+type SuspensionDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"userId"`
+	Reason      string     `json:"reason"`
+	SuspendedBy *uuid.UUID `json:"suspendedBy,omitempty"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+	LiftedAt    *time.Time `json:"liftedAt,omitempty"`
+	LiftedBy    *uuid.UUID `json:"liftedBy,omitempty"`
+	LiftReason  string     `json:"liftReason,omitempty"`
+	Status      string     `json:"status"`
+}
+
+// SuspensionDTOFromModel преобразует блокировку для API
+func SuspensionDTOFromModel(s *Suspension) *SuspensionDTO {
+	return &SuspensionDTO{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		Reason:      s.Reason,
+		SuspendedBy: s.SuspendedBy,
+		StartsAt:    s.StartsAt,
+		EndsAt:      s.EndsAt,
+		LiftedAt:    s.LiftedAt,
+		LiftedBy:    s.LiftedBy,
+		LiftReason:  s.LiftReason,
+		Status:      s.Status,
+	}
+}
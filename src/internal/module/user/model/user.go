@@ -3,10 +3,10 @@ package model
 import (
 	"time"
 
-	"slices"
-
 	"github.com/google/uuid"
 	"github.com/jackc/pgtype"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/permission"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,6 +27,15 @@ type UserModel struct {
 	CreatedAt     time.Time        `db:"created_at"`
 	UpdatedAt     time.Time        `db:"updated_at"`
 	DeletedAt     pgtype.Timestamp `db:"deleted_at"`
+	Version       int              `db:"version"`
+	AvatarKey     string           `db:"avatar_key"`
+	Preferences   UserPreferences  `db:"preferences"`
+
+	// Suspended у пользователя есть действующая блокировка; заполняется только при чтении по id и email
+	Suspended bool `db:"suspended"`
+
+	// OrganizationID организация пользователя; nil - пользователь платформы
+	OrganizationID *uuid.UUID `db:"organization_id"`
 }
 
 // RoleModel represents the role in the database
@@ -34,6 +43,10 @@ type RoleModel struct {
 	ID          int    `db:"id"`
 	RoleName    string `db:"role_name"`
 	Description string `db:"description"`
+	IsSystem    bool   `db:"is_system"`
+
+	// OrganizationID организация роли; nil - глобальная роль
+	OrganizationID *uuid.UUID `db:"organization_id"`
 }
 
 // PermissionModel represents the permission in the database
@@ -85,7 +98,7 @@ func (um *UserModel) FullName() string {
 type User struct {
 	ID            uuid.UUID    `json:"id"`
 	Email         string       `json:"email"`
-	Password      string       `json:"password"`
+	Password      string       `json:"-"`
 	FirstName     string       `json:"firstName"`
 	LastName      string       `json:"lastName"`
 	MiddleName    string       `json:"middleName"`
@@ -98,8 +111,22 @@ type User struct {
 	CreatedAt     time.Time    `json:"createdAt"`
 	UpdatedAt     time.Time    `json:"updatedAt"`
 	DeletedAt     *time.Time   `json:"deletedAt,omitempty"`
+	Version       int          `json:"version"`
 	Roles         []Role       `json:"roles,omitempty"`
 	Permissions   []Permission `json:"permissions,omitempty"`
+
+	Preferences UserPreferences `json:"preferences"`
+	AvatarKey   string          `json:"-"`
+	AvatarURL   string          `json:"avatarUrl,omitempty"`
+	// AvatarThumbnails ссылки на миниатюры аватара по размеру стороны в пикселях
+	AvatarThumbnails map[string]string `json:"avatarThumbnails,omitempty"`
+
+	Suspended bool `json:"suspended"`
+
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+
+	// grants скомпилированные действующие разрешения (прямые и через роли)
+	grants *permission.Matcher
 }
 
 // Role represents the business model for role
@@ -107,7 +134,32 @@ type Role struct {
 	ID          int          `json:"id"`
 	Name        string       `json:"name"`
 	Description string       `json:"description"`
+	IsSystem    bool         `json:"isSystem"`
 	Permissions []Permission `json:"permissions"`
+	// ParentIDs родительские роли, разрешения которых наследует роль
+	ParentIDs []int `json:"parentIds,omitempty"`
+
+	// OrganizationID организация роли; nil - глобальная роль, доступная всем организациям
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+}
+
+// AvailableTo reports whether the role can be assigned to a user of the organization
+// (nil for platform users): global roles are available to everyone
+func (r *Role) AvailableTo(organizationID *uuid.UUID) bool {
+	return roleAvailableTo(r.OrganizationID, organizationID)
+}
+
+// AvailableTo reports whether the role can be assigned to a user of the organization
+func (r *RoleModel) AvailableTo(organizationID *uuid.UUID) bool {
+	return roleAvailableTo(r.OrganizationID, organizationID)
+}
+
+// roleAvailableTo глобальная роль доступна всем, роль организации - только её пользователям
+func roleAvailableTo(roleOrganizationID, organizationID *uuid.UUID) bool {
+	if roleOrganizationID == nil {
+		return true
+	}
+	return organizationID != nil && *roleOrganizationID == *organizationID
 }
 
 // Permission represents the business model for permission
@@ -133,6 +185,13 @@ type UserDTO struct {
 	LastLogin     *time.Time `json:"lastLogin,omitempty"`
 	CreatedAt     time.Time  `json:"createdAt"`
 	UpdatedAt     time.Time  `json:"updatedAt"`
+	Version       int        `json:"version"`
+
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+
+	AvatarURL string `json:"avatarUrl,omitempty"`
+	// AvatarThumbnails ссылки на миниатюры аватара по размеру стороны в пикселях
+	AvatarThumbnails map[string]string `json:"avatarThumbnails,omitempty"`
 }
 
 // ToDBModel converts business model to database model
@@ -163,7 +222,12 @@ func (u *User) ToDBModel() *UserModel {
 		LastLogin:     lastLogin,
 		CreatedAt:     u.CreatedAt,
 		UpdatedAt:     u.UpdatedAt,
+		Version:       u.Version,
+		AvatarKey:     u.AvatarKey,
+		Preferences:   u.Preferences,
 		DeletedAt:     deletedAt,
+
+		OrganizationID: u.OrganizationID,
 	}
 }
 
@@ -179,6 +243,8 @@ func FromDBModel(dbUser *UserModel, roles []Role, permissions []Permission) *Use
 		deletedAt = &dbUser.DeletedAt.Time
 	}
 
+	avatarURL, avatarThumbnails := avatarURLs(dbUser.AvatarKey)
+
 	return &User{
 		ID:            dbUser.ID,
 		Email:         dbUser.Email,
@@ -194,9 +260,21 @@ func FromDBModel(dbUser *UserModel, roles []Role, permissions []Permission) *Use
 		LastLogin:     lastLogin,
 		CreatedAt:     dbUser.CreatedAt,
 		UpdatedAt:     dbUser.UpdatedAt,
+		Version:       dbUser.Version,
 		DeletedAt:     deletedAt,
+		Preferences:   dbUser.Preferences,
 		Roles:         roles,
 		Permissions:   permissions,
+
+		AvatarKey:        dbUser.AvatarKey,
+		AvatarURL:        avatarURL,
+		AvatarThumbnails: avatarThumbnails,
+
+		Suspended: dbUser.Suspended,
+
+		OrganizationID: dbUser.OrganizationID,
+
+		grants: compileGrants(roles, permissions),
 	}
 }
 
@@ -210,30 +288,68 @@ func (u *User) HasRole(roleName string) bool {
 	return false
 }
 
-// HasPermission checks if user has specific permission
-func (u *User) HasPermission(permissionName string) bool {
-	// Check direct permissions
-	for _, perm := range u.Permissions {
-		if perm.Name == permissionName {
-			return true
-		}
-	}
-
-	// Check permissions through roles
-	for _, role := range u.Roles {
-		for _, perm := range role.Permissions {
-			if perm.Name == permissionName {
-				return true
-			}
-		}
+// RoleNames returns names of the roles assigned to the user
+func (u *User) RoleNames() []string {
+	names := make([]string, len(u.Roles))
+	for i, role := range u.Roles {
+		names[i] = role.Name
 	}
+	return names
+}
 
-	return false
+// HasPermission checks if user has specific permission. Granted permissions may contain
+// wildcards ("users:*", "*"), see package permission for the grammar
+func (u *User) HasPermission(permissionName string) bool {
+	return u.permissionMatcher().Allows(permissionName)
 }
 
 // HasAnyPermission checks if user has any of the given permissions
 func (u *User) HasAnyPermission(permissions ...string) bool {
-	return slices.ContainsFunc(permissions, u.HasPermission)
+	return u.permissionMatcher().AllowsAny(permissions...)
+}
+
+// Attributes returns user attributes for attribute-based checks. The same set describes
+// the user both as a principal and as a resource: a user record is owned by the user
+func (u *User) Attributes() corepolicy.Attributes {
+	return UserAttributes(u.ID, u.DataRole, u.OrganizationID)
+}
+
+// UserAttributes builds policy attributes of a user
+func UserAttributes(id uuid.UUID, dataRole string, organizationID *uuid.UUID) corepolicy.Attributes {
+	attrs := corepolicy.Attributes{
+		corepolicy.AttrID:      id.String(),
+		corepolicy.AttrOwnerID: id.String(),
+	}
+	if dataRole != "" {
+		attrs[corepolicy.AttrDataRole] = dataRole
+	}
+	if organizationID != nil {
+		attrs[corepolicy.AttrOrganizationID] = organizationID.String()
+	}
+	return attrs
+}
+
+// permissionMatcher возвращает разрешения, скомпилированные при создании модели.
+// Для модели, собранной вручную, набор компилируется при каждом вызове
+func (u *User) permissionMatcher() *permission.Matcher {
+	if u.grants != nil {
+		return u.grants
+	}
+	return compileGrants(u.Roles, u.Permissions)
+}
+
+// compileGrants компилирует прямые разрешения и разрешения ролей; разрешения ролей уже включают унаследованные
+func compileGrants(roles []Role, permissions []Permission) *permission.Matcher {
+	names := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		names = append(names, perm.Name)
+	}
+	for _, role := range roles {
+		for _, perm := range role.Permissions {
+			names = append(names, perm.Name)
+		}
+	}
+	return permission.CompileRestricted(corepolicy.RestrictedPermissions, names...)
 }
 
 // UserDTOFromDBModel создает объект передачи данных (DTO) из модели базы данных
@@ -243,6 +359,8 @@ func UserDTOFromDBModel(dbUser *UserModel) *UserDTO {
 		lastLogin = &dbUser.LastLogin.Time
 	}
 
+	avatarURL, avatarThumbnails := avatarURLs(dbUser.AvatarKey)
+
 	return &UserDTO{
 		ID:            dbUser.ID,
 		Email:         dbUser.Email,
@@ -257,5 +375,11 @@ func UserDTOFromDBModel(dbUser *UserModel) *UserDTO {
 		LastLogin:     lastLogin,
 		CreatedAt:     dbUser.CreatedAt,
 		UpdatedAt:     dbUser.UpdatedAt,
+		Version:       dbUser.Version,
+
+		OrganizationID: dbUser.OrganizationID,
+
+		AvatarURL:        avatarURL,
+		AvatarThumbnails: avatarThumbnails,
 	}
 }
@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"context"
+
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+)
+
+// Название ресурса организаций, используемое в маршрутах при проверке доступа
+const OrganizationResourceName = "organization"
+
+type OrganizationPolicy struct{}
+
+func NewOrganizationPolicy() *OrganizationPolicy {
+	return &OrganizationPolicy{}
+}
+
+// Check требует суперадминистратора платформы с разрешением "organizations:<action>"
+func (p *OrganizationPolicy) Check(ctx context.Context, principal corepolicy.Principal, resource string, action string) bool {
+	return tenant.IsSuperadmin(principal) && corepolicy.CheckAction(principal, "organizations", action, nil)
+}
+
+// CheckResource не зависит от атрибутов ресурса: достаточно права на действие
+func (p *OrganizationPolicy) CheckResource(ctx context.Context, principal corepolicy.Principal, resource string, action string, attrs corepolicy.Attributes) bool {
+	return p.Check(ctx, principal, resource, action)
+}
+
+// Scope выборка не ограничивается: организации, доступные арендатору, отбирает репозиторий
+func (p *OrganizationPolicy) Scope(ctx context.Context, principal corepolicy.Principal, resource string, action string) *corepolicy.Scope {
+	return nil
+}
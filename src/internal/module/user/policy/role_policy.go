@@ -0,0 +1,40 @@
+package policy
+
+import (
+	"context"
+
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+)
+
+// Название ресурса ролей, используемое в маршрутах при проверке доступа
+const RoleResourceName = "role"
+
+// RolePermissionPrefix первый сегмент разрешений на роли
+const RolePermissionPrefix = "roles"
+
+type RolePolicy struct{}
+
+func NewRolePolicy() *RolePolicy {
+	return &RolePolicy{}
+}
+
+// roleActionPermissions действия, для которых требуются разрешения с другими названиями
+var roleActionPermissions = map[string][]string{
+	// Список участников роли раскрывает данные пользователей
+	"view-members": {"roles:view", "users:view"},
+}
+
+// Check требует разрешение "roles:<action>"
+func (p *RolePolicy) Check(ctx context.Context, principal corepolicy.Principal, resource string, action string) bool {
+	return corepolicy.CheckAction(principal, RolePermissionPrefix, action, roleActionPermissions)
+}
+
+// CheckResource не зависит от атрибутов ресурса: достаточно права на действие
+func (p *RolePolicy) CheckResource(ctx context.Context, principal corepolicy.Principal, resource string, action string, attrs corepolicy.Attributes) bool {
+	return p.Check(ctx, principal, resource, action)
+}
+
+// Scope выборка не ограничивается
+func (p *RolePolicy) Scope(ctx context.Context, principal corepolicy.Principal, resource string, action string) *corepolicy.Scope {
+	return nil
+}
@@ -2,52 +2,90 @@ package policy
 
 import (
 	"context"
+	"slices"
 
 	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
-	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
 // Название ресурса, используемое в маршрутах при проверке доступа
 const ResourceName = "user"
 
+// PermissionPrefix первый сегмент разрешений на пользователей
+const PermissionPrefix = "users"
+
 type UserPolicy struct{}
 
 func NewUserPolicy() *UserPolicy {
 	return &UserPolicy{}
 }
 
-func (p *UserPolicy) Check(ctx context.Context, user *model.User, resource string, action string) bool {
-	if user.HasAnyPermission("full", "users:full") {
-		return true
+// userActionPermissions действия, для которых требуется разрешение с другим названием
+var userActionPermissions = map[string][]string{
+	// Восстановление отменяет удаление и доступно тем, кто может удалять
+	"restore": {"users:delete"},
+	// Сменить пароль и подтвердить email другого пользователя может тот, кто вправе его изменять
+	"change-password": {"users:update"},
+	"verify-email":    {"users:update"},
+}
+
+// selfServiceActions действия, доступные пользователю над собственной учётной записью
+// без разрешений (правило "владелец или разрешение")
+var selfServiceActions = []string{"view", "change-password", "verify-email"}
+
+// DataRoleQualifier последний сегмент разрешения, ограничивающего действие пользователями
+// с тем же data_role, что у субъекта ("users:view:data-role")
+const DataRoleQualifier = "data-role"
+
+// dataRoleScopedActions действия, которые можно выдать с ограничением по data_role.
+// Обработчики этих действий проверяют атрибуты ресурса или ограничивают выборку,
+// поэтому остальные действия ограниченным разрешением не открываются
+var dataRoleScopedActions = []string{"view", "update", "export"}
+
+// Check требует разрешение "users:<action>". "users:*" покрывает все действия, кроме
+// update-restricted (активность и data_role) и purge: их дают только явная выдача или "*".
+// Для действий из dataRoleScopedActions достаточно ограниченного разрешения, тогда доступ
+// к конкретным пользователям сужает Scope
+func (p *UserPolicy) Check(ctx context.Context, principal corepolicy.Principal, resource string, action string) bool {
+	return corepolicy.CheckAction(principal, PermissionPrefix, action, userActionPermissions) ||
+		p.hasDataRoleGrant(principal, action)
+}
+
+// CheckResource проверяет действие над пользователем с атрибутами attrs. Действия из
+// selfServiceActions пользователь может выполнять над собой без разрешений
+func (p *UserPolicy) CheckResource(ctx context.Context, principal corepolicy.Principal, resource string, action string, attrs corepolicy.Attributes) bool {
+	if slices.Contains(selfServiceActions, action) {
+		return corepolicy.SelfOr(ctx, p, principal, resource, action, attrs)
 	}
+	return corepolicy.CheckScoped(ctx, p, principal, resource, action, attrs)
+}
+
+// Scope не ограничивает выборку при полном разрешении, а при ограниченном оставляет
+// пользователей с тем же data_role, что у субъекта
+func (p *UserPolicy) Scope(ctx context.Context, principal corepolicy.Principal, resource string, action string) *corepolicy.Scope {
+	if corepolicy.CheckAction(principal, PermissionPrefix, action, userActionPermissions) {
+		return nil
+	}
+	if !p.hasDataRoleGrant(principal, action) {
+		return corepolicy.DenyAll()
+	}
+
+	dataRole := principal.Attributes().Get(corepolicy.AttrDataRole)
+	if dataRole == "" {
+		return corepolicy.DenyAll()
+	}
+	return &corepolicy.Scope{Rules: []corepolicy.Attributes{{corepolicy.AttrDataRole: dataRole}}}
+}
 
-	switch action {
-	case "create":
-		return user.HasPermission("users:create")
-	case "view":
-		return user.HasPermission("users:view")
-	case "update":
-		return user.HasPermission("users:update")
-	case "delete":
-		return user.HasPermission("users:delete")
-	case "assign-role":
-		return user.HasPermission("users:assign-role")
-	case "revoke-role":
-		return user.HasPermission("users:revoke-role")
-	case "view-roles":
-		return user.HasPermission("users:view-roles")
-	case "assign-permission":
-		return user.HasPermission("users:assign-permission")
-	case "revoke-permission":
-		return user.HasAnyPermission("users:revoke-permission")
-	case "view-permissions":
-		return user.HasPermission("users:view-permissions")
-	default:
+func (p *UserPolicy) hasDataRoleGrant(principal corepolicy.Principal, action string) bool {
+	if principal == nil || !slices.Contains(dataRoleScopedActions, action) {
 		return false
 	}
+	return principal.HasPermission(PermissionPrefix + ":" + action + ":" + DataRoleQualifier)
 }
 
-// RegisterInFactory регистрирует политику пользователей в центральной фабрике политик
+// RegisterInFactory регистрирует политики пользователей и ролей в центральной фабрике политик
 func RegisterInFactory(factory *corepolicy.PolicyFactory) {
 	factory.RegisterPolicy(ResourceName, NewUserPolicy())
+	factory.RegisterPolicy(RoleResourceName, NewRolePolicy())
+	factory.RegisterPolicy(OrganizationResourceName, NewOrganizationPolicy())
 }
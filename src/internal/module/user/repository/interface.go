@@ -2,41 +2,109 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 )
 
-// UserRepository defines the interface for user data access
+// UserRepository defines the interface for user data access.
+// Writes to a user are limited to the organization of the tenant in the context;
+// methods returning a bool report false when the user is not found there
 type UserRepository interface {
 	// User methods
 	Create(ctx context.Context, user *model.UserModel) error
-	Update(ctx context.Context, user *model.UserModel) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, user *model.UserModel, expectedVersion int) (bool, error)
+	UpdateProfile(ctx context.Context, user *model.UserModel, expectedVersion int) (bool, error)
+	Deactivate(ctx context.Context, id uuid.UUID) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+	Restore(ctx context.Context, id uuid.UUID, retentionSeconds int) (bool, error)
+	Purge(ctx context.Context, id uuid.UUID) (avatarKey string, found bool, err error)
+	UpdateAvatar(ctx context.Context, id uuid.UUID, avatarKey string) (oldKey string, found bool, err error)
 	FindByID(ctx context.Context, id uuid.UUID) (*model.UserModel, error)
+	FindDeletedByID(ctx context.Context, id uuid.UUID) (*model.UserModel, error)
 	FindByEmail(ctx context.Context, email string) (*model.UserModel, error)
 	FindAll(ctx context.Context) ([]*model.UserModel, error)
-	ConfirmEmail(ctx context.Context, userID uuid.UUID) error
-	ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string) error
+	FindPage(ctx context.Context, filter *model.UserFilter) ([]*model.UserModel, error)
+	Count(ctx context.Context, filter *model.UserFilter) (int64, error)
+	Search(ctx context.Context, filter *model.UserSearchFilter) ([]*model.UserSearchRow, error)
+	CountSearch(ctx context.Context, filter *model.UserSearchFilter) (int64, error)
+	ConfirmEmail(ctx context.Context, userID uuid.UUID, expectedVersion int) (bool, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string, expectedVersion int) (bool, error)
+
+	GetRoleNamesByUserIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
+	GetPermissionNamesByUserIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
 
 	// Role methods
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]model.Role, error)
-	AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error
-	RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) error
+	AssignRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error)
+	RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error)
 	CreateRole(ctx context.Context, role *model.RoleModel) error
 	UpdateRole(ctx context.Context, role *model.RoleModel) error
 	DeleteRole(ctx context.Context, roleID int) error
 	GetAllRoles(ctx context.Context) ([]model.Role, error)
 	FindRoleByName(ctx context.Context, name string) (*model.RoleModel, error)
+	FindRoleByID(ctx context.Context, id int) (*model.RoleModel, error)
+	CountRoleMembers(ctx context.Context, roleID int) (int64, error)
+
+	// Role hierarchy
+	GetRoleParentIDs(ctx context.Context, roleID int) ([]int, error)
+	// LockRoleHierarchy serializes hierarchy changes until the end of the current transaction
+	LockRoleHierarchy(ctx context.Context) error
+	// IsRoleAncestorOf reports whether ancestorID is one of roleIDs or an ancestor of any of them
+	IsRoleAncestorOf(ctx context.Context, ancestorID int, roleIDs []int) (bool, error)
+	SetRoleParents(ctx context.Context, roleID int, parentIDs []int) error
+	GetRoleEffectivePermissions(ctx context.Context, roleID int) ([]model.Permission, error)
 
 	// Permission methods
 	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]model.Permission, error)
 	GetRolePermissions(ctx context.Context, roleID int) ([]model.Permission, error)
-	AssignPermission(ctx context.Context, userID uuid.UUID, permissionID int) error
-	RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) error
+	AssignPermission(ctx context.Context, userID uuid.UUID, permissionID int) (bool, error)
+	RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) (bool, error)
 	CreatePermission(ctx context.Context, permission *model.PermissionModel) error
 	UpdatePermission(ctx context.Context, permission *model.PermissionModel) error
 	DeletePermission(ctx context.Context, permissionID int) error
 	GetAllPermissions(ctx context.Context) ([]model.Permission, error)
 	FindPermissionByName(ctx context.Context, name string) (*model.PermissionModel, error)
+	FindPermissionByID(ctx context.Context, id int) (*model.PermissionModel, error)
+	CountPermissionAssignments(ctx context.Context, permissionID int) (int64, error)
+	AddRolePermissions(ctx context.Context, roleID int, permissionIDs []int) error
+	RemoveRolePermissions(ctx context.Context, roleID int, permissionIDs []int) error
+	// RemoveOtherRolePermissions removes every permission of the role not listed in keepIDs
+	RemoveOtherRolePermissions(ctx context.Context, roleID int, keepIDs []int) error
+}
+
+// ExportJobRepository defines the interface for user export job storage
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *model.ExportJob) error
+	// FindByID returns the job only within the organization of the tenant in the context
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ExportJob, error)
+	// MarkRunning moves a pending job to running; returns false if the job was already picked up
+	MarkRunning(ctx context.Context, id uuid.UUID, startedAt time.Time) (bool, error)
+	MarkCompleted(ctx context.Context, id uuid.UUID, filePath string, rowCount int, finishedAt, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, message string, finishedAt time.Time) error
+	// DeleteExpired removes jobs whose files expired before now and returns their file paths
+	DeleteExpired(ctx context.Context, now time.Time) ([]string, error)
+}
+
+// SuspensionRepository defines the interface for user suspension storage.
+// A suspension is active while it is not lifted and its end time has not passed
+type SuspensionRepository interface {
+	// Create inserts a suspension unless the user already has an active one; returns nil in that case.
+	// A nil durationSeconds means the suspension has no end time
+	Create(ctx context.Context, suspension *model.Suspension, durationSeconds *int) (*model.Suspension, error)
+	FindActive(ctx context.Context, userID uuid.UUID) (*model.Suspension, error)
+	// Lift ends the active suspension early; returns nil if the user is not suspended
+	Lift(ctx context.Context, userID uuid.UUID, liftedBy *uuid.UUID, reason string) (*model.Suspension, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Suspension, error)
+}
+
+// OrganizationRepository defines the interface for organization storage.
+// Reads are limited to the organization of the tenant in the context
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *model.Organization) error
+	Update(ctx context.Context, organization *model.Organization) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Organization, error)
+	FindBySlug(ctx context.Context, slug string) (*model.Organization, error)
+	List(ctx context.Context) ([]*model.Organization, error)
 }
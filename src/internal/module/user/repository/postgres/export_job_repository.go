@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	modelUser "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
+)
+
+// exportJobRepository реализует интерфейс repository.ExportJobRepository
+type exportJobRepository struct {
+	db     db.Client
+	logger logger.Logger
+}
+
+func NewExportJobRepository(db db.Client, logger logger.Logger) repository.ExportJobRepository {
+	return &exportJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *exportJobRepository) Create(ctx context.Context, job *modelUser.ExportJob) error {
+	q := db.Query{
+		Name: "user_export_job.Create",
+		QueryRaw: `
+			INSERT INTO user_export_jobs (id, requested_by, format, filters, status, created_at, organization_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q,
+		job.ID, job.RequestedBy, job.Format, job.Filters, job.Status, job.CreatedAt, job.OrganizationID,
+	)
+
+	return err
+}
+
+// FindByID ищет задание в организации арендатора из контекста
+func (r *exportJobRepository) FindByID(ctx context.Context, id uuid.UUID) (*modelUser.ExportJob, error) {
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	tenant.Filter(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name: "user_export_job.FindByID",
+		QueryRaw: `
+			SELECT id, requested_by, format, filters, status, file_path, row_count, error,
+			       created_at, started_at, finished_at, expires_at, organization_id
+			FROM user_export_jobs` + conds.Where(),
+	}
+
+	var job modelUser.ExportJob
+	err := r.db.DB().ScanOneContext(ctx, &job, q, conds.Args()...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *exportJobRepository) MarkRunning(ctx context.Context, id uuid.UUID, startedAt time.Time) (bool, error) {
+	q := db.Query{
+		Name: "user_export_job.MarkRunning",
+		QueryRaw: `
+			UPDATE user_export_jobs SET status = $2, started_at = $3
+			WHERE id = $1 AND status = $4
+		`,
+	}
+	tag, err := r.db.DB().ExecContext(ctx, q, id, modelUser.ExportStatusRunning, startedAt, modelUser.ExportStatusPending)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *exportJobRepository) MarkCompleted(ctx context.Context, id uuid.UUID, filePath string, rowCount int, finishedAt, expiresAt time.Time) error {
+	q := db.Query{
+		Name: "user_export_job.MarkCompleted",
+		QueryRaw: `
+			UPDATE user_export_jobs
+			SET status = $2, file_path = $3, row_count = $4, finished_at = $5, expires_at = $6
+			WHERE id = $1
+		`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q, id, modelUser.ExportStatusCompleted, filePath, rowCount, finishedAt, expiresAt)
+
+	return err
+}
+
+func (r *exportJobRepository) MarkFailed(ctx context.Context, id uuid.UUID, message string, finishedAt time.Time) error {
+	q := db.Query{
+		Name: "user_export_job.MarkFailed",
+		QueryRaw: `
+			UPDATE user_export_jobs SET status = $2, error = $3, finished_at = $4
+			WHERE id = $1
+		`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q, id, modelUser.ExportStatusFailed, message, finishedAt)
+
+	return err
+}
+
+func (r *exportJobRepository) DeleteExpired(ctx context.Context, now time.Time) ([]string, error) {
+	q := db.Query{
+		Name: "user_export_job.DeleteExpired",
+		QueryRaw: `
+			DELETE FROM user_export_jobs
+			WHERE expires_at IS NOT NULL AND expires_at < $1
+			RETURNING file_path
+		`,
+	}
+
+	var paths []string
+	if err := r.db.DB().ScanAllContext(ctx, &paths, q, now); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
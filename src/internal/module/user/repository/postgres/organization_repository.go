@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	modelUser "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
+)
+
+const organizationColumns = `id, name, slug, created_at, updated_at`
+
+// organizationRepository реализует интерфейс repository.OrganizationRepository
+type organizationRepository struct {
+	db     db.Client
+	logger logger.Logger
+}
+
+func NewOrganizationRepository(db db.Client, logger logger.Logger) repository.OrganizationRepository {
+	return &organizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *modelUser.Organization) error {
+	q := db.Query{
+		Name: "organization.Create",
+		QueryRaw: `
+			INSERT INTO organizations (id, name, slug)
+			VALUES ($1, $2, $3)
+			RETURNING created_at, updated_at
+		`,
+	}
+	return r.db.DB().QueryRowContext(ctx, q, organization.ID, organization.Name, organization.Slug).
+		Scan(&organization.CreatedAt, &organization.UpdatedAt)
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *modelUser.Organization) error {
+	q := db.Query{
+		Name:     "organization.Update",
+		QueryRaw: `UPDATE organizations SET name = $2 WHERE id = $1 RETURNING updated_at`,
+	}
+	return r.db.DB().QueryRowContext(ctx, q, organization.ID, organization.Name).Scan(&organization.UpdatedAt)
+}
+
+func (r *organizationRepository) FindByID(ctx context.Context, id uuid.UUID) (*modelUser.Organization, error) {
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	tenant.Filter(ctx, conds, "id")
+
+	return r.findOne(ctx, "organization.FindByID", conds)
+}
+
+// FindBySlug ищет организацию по slug без учёта арендатора: slug уникален на платформе
+func (r *organizationRepository) FindBySlug(ctx context.Context, slug string) (*modelUser.Organization, error) {
+	conds := &db.Conditions{}
+	conds.Add("slug = ?", slug)
+
+	return r.findOne(ctx, "organization.FindBySlug", conds)
+}
+
+func (r *organizationRepository) List(ctx context.Context) ([]*modelUser.Organization, error) {
+	conds := &db.Conditions{}
+	tenant.Filter(ctx, conds, "id")
+
+	q := db.Query{
+		Name:     "organization.List",
+		QueryRaw: `SELECT ` + organizationColumns + ` FROM organizations` + conds.Where() + ` ORDER BY name, id`,
+	}
+
+	organizations := make([]*modelUser.Organization, 0)
+	if err := r.db.DB().ScanAllContext(ctx, &organizations, q, conds.Args()...); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+func (r *organizationRepository) findOne(ctx context.Context, name string, conds *db.Conditions) (*modelUser.Organization, error) {
+	q := db.Query{
+		Name:     name,
+		QueryRaw: `SELECT ` + organizationColumns + ` FROM organizations` + conds.Where(),
+	}
+
+	var organization modelUser.Organization
+	err := r.db.DB().ScanOneContext(ctx, &organization, q, conds.Args()...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
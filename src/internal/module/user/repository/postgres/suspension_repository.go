@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	modelUser "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
+)
+
+// Сроки блокировок сравниваются с NOW() базы данных, поэтому блокировка снимается
+// автоматически в момент ends_at без фоновых задач
+const (
+	suspensionColumns = `
+		id, user_id, reason, suspended_by, starts_at, ends_at, lifted_at, lifted_by, lift_reason, created_at,
+		CASE
+			WHEN lifted_at IS NOT NULL THEN 'lifted'
+			WHEN ends_at IS NOT NULL AND ends_at <= NOW() THEN 'expired'
+			ELSE 'active'
+		END AS status
+	`
+	activeSuspensionCondition = `lifted_at IS NULL AND starts_at <= NOW() AND (ends_at IS NULL OR ends_at > NOW())`
+)
+
+// suspensionRepository реализует интерфейс repository.SuspensionRepository
+type suspensionRepository struct {
+	db     db.Client
+	logger logger.Logger
+}
+
+func NewSuspensionRepository(db db.Client, logger logger.Logger) repository.SuspensionRepository {
+	return &suspensionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create создаёт блокировку, если у пользователя нет действующей. Возвращает nil, если блокировка уже есть
+func (r *suspensionRepository) Create(ctx context.Context, suspension *modelUser.Suspension, durationSeconds *int) (*modelUser.Suspension, error) {
+	q := db.Query{
+		Name: "user_suspension.Create",
+		QueryRaw: `
+			INSERT INTO user_suspensions (id, user_id, reason, suspended_by, starts_at, ends_at)
+			SELECT $1, $2, $3, $4, NOW(), NOW() + $5::int * INTERVAL '1 second'
+			WHERE NOT EXISTS (
+				SELECT 1 FROM user_suspensions WHERE user_id = $2 AND ` + activeSuspensionCondition + `
+			)
+			RETURNING ` + suspensionColumns,
+	}
+
+	var created modelUser.Suspension
+	err := r.db.DB().ScanOneContext(ctx, &created, q,
+		suspension.ID, suspension.UserID, suspension.Reason, suspension.SuspendedBy, durationSeconds,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *suspensionRepository) FindActive(ctx context.Context, userID uuid.UUID) (*modelUser.Suspension, error) {
+	q := db.Query{
+		Name: "user_suspension.FindActive",
+		QueryRaw: `
+			SELECT ` + suspensionColumns + `
+			FROM user_suspensions
+			WHERE user_id = $1 AND ` + activeSuspensionCondition + `
+			ORDER BY starts_at DESC
+			LIMIT 1
+		`,
+	}
+
+	var suspension modelUser.Suspension
+	err := r.db.DB().ScanOneContext(ctx, &suspension, q, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &suspension, nil
+}
+
+// Lift досрочно снимает действующую блокировку. Возвращает nil, если действующей блокировки нет
+func (r *suspensionRepository) Lift(ctx context.Context, userID uuid.UUID, liftedBy *uuid.UUID, reason string) (*modelUser.Suspension, error) {
+	q := db.Query{
+		Name: "user_suspension.Lift",
+		QueryRaw: `
+			UPDATE user_suspensions SET lifted_at = NOW(), lifted_by = $2, lift_reason = $3
+			WHERE user_id = $1 AND ` + activeSuspensionCondition + `
+			RETURNING ` + suspensionColumns,
+	}
+
+	var suspension modelUser.Suspension
+	err := r.db.DB().ScanOneContext(ctx, &suspension, q, userID, liftedBy, reason)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &suspension, nil
+}
+
+func (r *suspensionRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*modelUser.Suspension, error) {
+	q := db.Query{
+		Name: "user_suspension.ListByUser",
+		QueryRaw: `
+			SELECT ` + suspensionColumns + `
+			FROM user_suspensions
+			WHERE user_id = $1
+			ORDER BY starts_at DESC, created_at DESC
+		`,
+	}
+
+	var suspensions []*modelUser.Suspension
+	if err := r.db.DB().ScanAllContext(ctx, &suspensions, q, userID); err != nil {
+		return nil, err
+	}
+
+	return suspensions, nil
+}
@@ -3,11 +3,15 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
 	modelUser "github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
 )
@@ -34,64 +38,251 @@ func (r *userRepository) Create(ctx context.Context, user *modelUser.UserModel)
 			INSERT INTO users (
 				id, email, password, first_name, last_name, middle_name,
 				phone, position, active, data_role, email_verified,
-				created_at, updated_at
+				created_at, updated_at, organization_id
 			) VALUES (
 				$1, $2, $3, $4, $5, $6,
 				$7, $8, $9, $10, $11,
-				$12, $13
+				$12, $13, $14
 			)
 		`,
 	}
 	_, err := r.db.DB().ExecContext(ctx, q,
 		user.ID, user.Email, user.Password, user.FirstName, user.LastName, user.MiddleName,
 		user.Phone, user.Position, user.Active, user.DataRole, user.EmailVerified,
-		user.CreatedAt, user.UpdatedAt,
+		user.CreatedAt, user.UpdatedAt, user.OrganizationID,
 	)
 
 	return err
 }
 
-func (r *userRepository) Update(ctx context.Context, user *modelUser.UserModel) error {
+// userWriteConditions условия изменения пользователя в организации арендатора из контекста.
+// args занимают плейсхолдеры $1, $2, ... запроса, первым передаётся id пользователя
+func userWriteConditions(ctx context.Context, args ...any) *db.Conditions {
+	conds := &db.Conditions{}
+	for _, arg := range args {
+		conds.Arg(arg)
+	}
+	conds.Add("id = $1")
+	tenant.Filter(ctx, conds, "organization_id")
+	return conds
+}
+
+// Update сохраняет email и имя пользователя, если версия записи совпадает с ожидаемой.
+// Возвращает false, если запись была изменена, удалена параллельно или относится к другой организации
+func (r *userRepository) Update(ctx context.Context, user *modelUser.UserModel, expectedVersion int) (bool, error) {
+	conds := userWriteConditions(ctx, user.ID, user.Email, user.FirstName, user.LastName, expectedVersion)
+	conds.Add("version = $5")
+	conds.Add("deleted_at IS NULL")
+
 	q := db.Query{
-		Name:     "user.Update",
-		QueryRaw: `UPDATE users SET email = $2, first_name = $3, last_name = $4 WHERE id = $1`,
+		Name: "user.Update",
+		QueryRaw: `
+			UPDATE users SET email = $2, first_name = $3, last_name = $4, version = version + 1` + conds.Where() + `
+			RETURNING version, updated_at
+		`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, user.ID, user.Email, user.FirstName, user.LastName)
-	return err
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&user.Version, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// UpdateProfile сохраняет редактируемые поля профиля, если версия записи совпадает с ожидаемой.
+// Возвращает false, если запись была изменена, удалена параллельно или относится к другой организации
+func (r *userRepository) UpdateProfile(ctx context.Context, user *modelUser.UserModel, expectedVersion int) (bool, error) {
+	conds := userWriteConditions(ctx,
+		user.ID, user.Email, user.FirstName, user.LastName, user.MiddleName,
+		user.Phone, user.Position, user.Active, user.DataRole, user.EmailVerified,
+		expectedVersion, user.Preferences,
+	)
+	conds.Add("version = $11")
+	conds.Add("deleted_at IS NULL")
+
+	q := db.Query{
+		Name: "user.UpdateProfile",
+		QueryRaw: `
+			UPDATE users
+			SET email = $2, first_name = $3, last_name = $4, middle_name = $5,
+				phone = $6, position = $7, active = $8, data_role = $9, email_verified = $10,
+				preferences = $12, version = version + 1` + conds.Where() + `
+			RETURNING version, updated_at
+		`,
+	}
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&user.Version, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Deactivate отключает учётную запись пользователя
+func (r *userRepository) Deactivate(ctx context.Context, id uuid.UUID) (bool, error) {
+	conds := userWriteConditions(ctx, id)
+	conds.Add("deleted_at IS NULL")
+
+	q := db.Query{
+		Name:     "user.Deactivate",
+		QueryRaw: `UPDATE users SET active = FALSE, version = version + 1` + conds.Where(),
+	}
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Delete помечает пользователя удалённым; возвращает false, если пользователь не найден
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	conds := userWriteConditions(ctx, id)
+	conds.Add("deleted_at IS NULL")
+
 	q := db.Query{
 		Name:     "user.Delete",
-		QueryRaw: `UPDATE users SET deleted_at = NOW() WHERE id = $1`,
+		QueryRaw: `UPDATE users SET deleted_at = NOW()` + conds.Where(),
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, id)
-	return err
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
 
-func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*modelUser.UserModel, error) {
+// Restore снимает отметку об удалении, если пользователь удалён не раньше retentionSeconds назад
+func (r *userRepository) Restore(ctx context.Context, id uuid.UUID, retentionSeconds int) (bool, error) {
+	conds := userWriteConditions(ctx, id, retentionSeconds)
+	conds.Add("deleted_at IS NOT NULL")
+	conds.Add("deleted_at >= NOW() - $2::int * INTERVAL '1 second'")
+
 	q := db.Query{
-		Name:     "user.FindByID",
-		QueryRaw: `SELECT id, email, password, first_name, last_name FROM users WHERE id = $1 AND deleted_at IS NULL`,
+		Name:     "user.Restore",
+		QueryRaw: `UPDATE users SET deleted_at = NULL, version = version + 1` + conds.Where(),
 	}
-	var user modelUser.UserModel
-	err := r.db.DB().ScanOneContext(ctx, &user, q, id)
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Purge окончательно удаляет ранее удалённого (soft delete) пользователя; связанные записи удаляются каскадно.
+// Возвращает ключ его аватара для очистки хранилища
+func (r *userRepository) Purge(ctx context.Context, id uuid.UUID) (string, bool, error) {
+	conds := userWriteConditions(ctx, id)
+	conds.Add("deleted_at IS NOT NULL")
+
+	q := db.Query{
+		Name:     "user.Purge",
+		QueryRaw: `DELETE FROM users` + conds.Where() + ` RETURNING avatar_key`,
+	}
+
+	var avatarKey string
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&avatarKey)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+			return "", false, nil
 		}
-		return nil, err
+		return "", false, err
 	}
-	return &user, nil
+	return avatarKey, true, nil
+}
+
+// UpdateAvatar заменяет ключ аватара и возвращает предыдущий ключ для удаления старых файлов
+func (r *userRepository) UpdateAvatar(ctx context.Context, id uuid.UUID, avatarKey string) (string, bool, error) {
+	conds := userWriteConditions(ctx, id, avatarKey, time.Now())
+	conds.Add("deleted_at IS NULL")
+
+	q := db.Query{
+		Name: "user.UpdateAvatar",
+		QueryRaw: `
+			UPDATE users u
+			SET avatar_key = $2, version = u.version + 1, updated_at = $3
+			FROM (SELECT id, avatar_key FROM users` + conds.Where() + ` FOR UPDATE) old
+			WHERE u.id = old.id
+			RETURNING old.avatar_key
+		`,
+	}
+
+	var oldKey string
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&oldKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return oldKey, true, nil
+}
+
+// userColumns колонки пользователя для выборок без алиаса таблицы
+const userColumns = `id, email, password, first_name, last_name, middle_name,
+				phone, position, active, data_role, email_verified,
+				created_at, updated_at, last_login, deleted_at, version, avatar_key, preferences, organization_id`
+
+// userSuspendedColumn вычисляет наличие действующей блокировки
+const userSuspendedColumn = `EXISTS (
+					SELECT 1 FROM user_suspensions WHERE user_id = users.id AND ` + activeSuspensionCondition + `
+				) AS suspended`
+
+// FindByID ищет пользователя в организации арендатора из контекста
+func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*modelUser.UserModel, error) {
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	conds.Add("deleted_at IS NULL")
+	tenant.Filter(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name: "user.FindByID",
+		QueryRaw: `
+			SELECT ` + userColumns + `,
+				` + userSuspendedColumn + `
+			FROM users` + conds.Where(),
+	}
+	return r.findOne(ctx, q, conds.Args()...)
+}
+
+// FindDeletedByID возвращает удалённого (soft delete) пользователя организации арендатора
+func (r *userRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*modelUser.UserModel, error) {
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	conds.Add("deleted_at IS NOT NULL")
+	tenant.Filter(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name:     "user.FindDeletedByID",
+		QueryRaw: `SELECT ` + userColumns + ` FROM users` + conds.Where(),
+	}
+	return r.findOne(ctx, q, conds.Args()...)
 }
 
+// FindByEmail ищет пользователя без учёта арендатора: email уникален на платформе,
+// а поиск выполняется в том числе при входе, когда организация ещё неизвестна.
+// По той же причине явно отключаются политики row-level security
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*modelUser.UserModel, error) {
+	ctx = db.WithoutRowSecurity(ctx)
 	q := db.Query{
-		Name:     "user.FindByEmail",
-		QueryRaw: `SELECT id, email, password, first_name, last_name FROM users WHERE email = $1 AND deleted_at IS NULL`,
+		Name: "user.FindByEmail",
+		QueryRaw: `
+			SELECT ` + userColumns + `,
+				` + userSuspendedColumn + `
+			FROM users
+			WHERE email = $1 AND deleted_at IS NULL
+		`,
 	}
+	return r.findOne(ctx, q, email)
+}
+
+func (r *userRepository) findOne(ctx context.Context, q db.Query, args ...any) (*modelUser.UserModel, error) {
 	var user modelUser.UserModel
-	err := r.db.DB().ScanOneContext(ctx, &user, q, email)
+	err := r.db.DB().ScanOneContext(ctx, &user, q, args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -102,21 +293,21 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*modelU
 }
 
 func (r *userRepository) FindAll(ctx context.Context) ([]*modelUser.UserModel, error) {
+	conds := &db.Conditions{}
+	conds.Add("deleted_at IS NULL")
+	tenant.Filter(ctx, conds, "organization_id")
 
 	q := db.Query{
 		Name: "user.FindAll",
 		QueryRaw: `
-			SELECT id, email, password, first_name, last_name, middle_name,
-				phone, position, active, data_role, email_verified,
-				created_at, updated_at, last_login, deleted_at
-			FROM users 
-			WHERE deleted_at IS NULL
+			SELECT ` + userColumns + `
+			FROM users` + conds.Where() + `
 			ORDER BY created_at DESC
 		`,
 	}
 
 	var users []*modelUser.UserModel
-	err := r.db.DB().ScanAllContext(ctx, &users, q)
+	err := r.db.DB().ScanAllContext(ctx, &users, q, conds.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,12 +315,308 @@ func (r *userRepository) FindAll(ctx context.Context) ([]*modelUser.UserModel, e
 	return users, nil
 }
 
+// userSortColumns белый список полей сортировки списка пользователей.
+// В SQL подставляются только значения из этой таблицы
+var userSortColumns = map[string]struct {
+	column string
+	isTime bool
+}{
+	"created_at": {column: "u.created_at", isTime: true},
+	"updated_at": {column: "u.updated_at", isTime: true},
+	"email":      {column: "u.email"},
+	"first_name": {column: "u.first_name"},
+	"last_name":  {column: "u.last_name"},
+}
+
+func (r *userRepository) FindPage(ctx context.Context, filter *modelUser.UserFilter) ([]*modelUser.UserModel, error) {
+	sort, ok := userSortColumns[filter.Sort]
+	if !ok {
+		sort = userSortColumns[modelUser.DefaultUserSort]
+	}
+
+	direction, cmp := "DESC", "<"
+	if filter.Order == modelUser.SortOrderAsc {
+		direction, cmp = "ASC", ">"
+	}
+
+	conds := userFilterConditions(ctx, filter)
+
+	if filter.After != nil {
+		var value any = filter.After.Value
+		if sort.isTime {
+			t, err := time.Parse(time.RFC3339Nano, filter.After.Value)
+			if err != nil {
+				return nil, modelUser.ErrInvalidCursor
+			}
+			value = t
+		}
+		conds.Add("("+sort.column+", u.id) "+cmp+" (?, ?)", value, filter.After.ID)
+	}
+
+	sql := `
+			SELECT u.id, u.email, u.password, u.first_name, u.last_name, u.middle_name,
+				u.phone, u.position, u.active, u.data_role, u.email_verified,
+				u.created_at, u.updated_at, u.last_login, u.deleted_at, u.version, u.avatar_key, u.preferences,
+				u.organization_id
+			FROM users u` + conds.Where() + `
+			ORDER BY ` + sort.column + ` ` + direction + `, u.id ` + direction + `
+			LIMIT ` + conds.Arg(filter.Limit)
+	if filter.Offset > 0 {
+		sql += ` OFFSET ` + conds.Arg(filter.Offset)
+	}
+
+	q := db.Query{
+		Name:     "user.FindPage",
+		QueryRaw: sql,
+	}
+
+	users := make([]*modelUser.UserModel, 0)
+	if err := r.db.DB().ScanAllContext(ctx, &users, q, conds.Args()...); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) Count(ctx context.Context, filter *modelUser.UserFilter) (int64, error) {
+	conds := userFilterConditions(ctx, filter)
+
+	q := db.Query{
+		Name:     "user.Count",
+		QueryRaw: `SELECT COUNT(*) FROM users u` + conds.Where(),
+	}
+
+	var total int64
+	if err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// userSearchCTE общая часть поисковых запросов: префиксный tsquery по словам,
+// строка для нечёткого сравнения и шаблон для поиска подстроки
+const userSearchCTE = `
+			WITH q AS (
+				SELECT to_tsquery('simple', $1) AS tsq, $2::text AS term, $3::text AS pattern
+			)`
+
+// userSearchCondition условие совпадения: полнотекстовое, по триграммам или по подстроке
+const userSearchCondition = `u.deleted_at IS NULL
+			AND (u.search_vector @@ q.tsq OR q.term <% u.search_text OR u.search_text LIKE q.pattern)`
+
+func (r *userRepository) Search(ctx context.Context, filter *modelUser.UserSearchFilter) ([]*modelUser.UserSearchRow, error) {
+	conds := userSearchConditions(ctx, filter)
+	limit := conds.Arg(filter.Limit)
+	offset := conds.Arg(filter.Offset)
+
+	q := db.Query{
+		Name: "user.Search",
+		QueryRaw: userSearchCTE + `
+			SELECT u.id, u.email, u.password, u.first_name, u.last_name, u.middle_name,
+				u.phone, u.position, u.active, u.data_role, u.email_verified,
+				u.created_at, u.updated_at, u.last_login, u.deleted_at, u.version, u.avatar_key, u.preferences,
+				u.organization_id,
+				(ts_rank(u.search_vector, q.tsq) + word_similarity(q.term, u.search_text))::float8 AS rank,
+				ts_headline('simple',
+					u.last_name || ' ' || u.first_name || ' ' || u.middle_name || ' ' || u.email || ' ' || u.phone || ' ' || u.position,
+					q.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight
+			FROM users u, q` + conds.Where() + `
+			ORDER BY rank DESC, u.id
+			LIMIT ` + limit + ` OFFSET ` + offset,
+	}
+
+	rows := make([]*modelUser.UserSearchRow, 0)
+	err := r.db.DB().ScanAllContext(ctx, &rows, q, conds.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *userRepository) CountSearch(ctx context.Context, filter *modelUser.UserSearchFilter) (int64, error) {
+	conds := userSearchConditions(ctx, filter)
+
+	q := db.Query{
+		Name: "user.CountSearch",
+		QueryRaw: userSearchCTE + `
+			SELECT COUNT(*)
+			FROM users u, q` + conds.Where(),
+	}
+
+	var total int64
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// userSearchConditions строит условия поиска в организации арендатора. Параметры CTE регистрируются первыми,
+// поэтому занимают $1-$3, на которые ссылается userSearchCTE
+func userSearchConditions(ctx context.Context, filter *modelUser.UserSearchFilter) *db.Conditions {
+	conds := &db.Conditions{}
+	conds.Arg(userSearchTSQuery(filter.Terms))
+	conds.Arg(filter.Text)
+	conds.Arg(userSearchPattern(filter.Text))
+
+	conds.Add(userSearchCondition)
+	filter.Scope.Apply(conds, userScopeColumns)
+	tenant.Filter(ctx, conds, "u.organization_id")
+
+	return conds
+}
+
+// userSearchTSQuery собирает префиксный tsquery ("ivan:* & petr:*").
+// Слова заранее очищены до букв и цифр, поэтому не содержат операторов tsquery
+func userSearchTSQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, term+":*")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// userSearchPattern возвращает шаблон LIKE для поиска подстроки с экранированием спецсимволов
+func userSearchPattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(text) + "%"
+}
+
+// userFilterConditions строит условия выборки пользователей по фильтру в организации арендатора
+func userFilterConditions(ctx context.Context, filter *modelUser.UserFilter) *db.Conditions {
+	conds := &db.Conditions{}
+
+	switch filter.Deleted {
+	case modelUser.DeletedInclude:
+	case modelUser.DeletedOnly:
+		conds.Add("u.deleted_at IS NOT NULL")
+	default:
+		conds.Add("u.deleted_at IS NULL")
+	}
+
+	if filter.Active != nil {
+		conds.Add("u.active = ?", *filter.Active)
+	}
+	if filter.EmailVerified != nil {
+		conds.Add("u.email_verified = ?", *filter.EmailVerified)
+	}
+	if filter.DataRole != "" {
+		conds.Add("u.data_role = ?", filter.DataRole)
+	}
+	if filter.CreatedFrom != nil {
+		conds.Add("u.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		conds.Add("u.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.Role != "" {
+		conds.Add(`EXISTS (
+				SELECT 1 FROM user_roles ur
+				JOIN roles r ON r.id = ur.role_id
+				WHERE ur.user_id = u.id AND r.role_name = ?
+			)`, filter.Role)
+	}
+	if filter.Permission != "" {
+		// Разрешение может быть назначено напрямую или через роль, в том числе унаследовано
+		conds.Add(`(EXISTS (
+				SELECT 1 FROM user_permissions up
+				JOIN permissions p ON p.id = up.permission_id
+				WHERE up.user_id = u.id AND p.permission_name = ?
+			) OR EXISTS (
+				SELECT 1 FROM user_roles ur
+				JOIN role_effective_permissions rp ON rp.role_id = ur.role_id
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE ur.user_id = u.id AND p.permission_name = ?
+			))`, filter.Permission, filter.Permission)
+	}
+	filter.Scope.Apply(conds, userScopeColumns)
+	tenant.Filter(ctx, conds, "u.organization_id")
+
+	return conds
+}
+
+// userScopeColumns колонки users, соответствующие атрибутам ограничения выборки
+var userScopeColumns = map[string]string{
+	corepolicy.AttrID:             "u.id",
+	corepolicy.AttrOwnerID:        "u.id",
+	corepolicy.AttrDataRole:       "u.data_role",
+	corepolicy.AttrOrganizationID: "u.organization_id",
+}
+
+// GetRoleNamesByUserIDs возвращает названия ролей для набора пользователей одним запросом
+func (r *userRepository) GetRoleNamesByUserIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	q := db.Query{
+		Name: "user.GetRoleNamesByUserIDs",
+		QueryRaw: `
+			SELECT ur.user_id, r.role_name
+			FROM user_roles ur
+			JOIN roles r ON r.id = ur.role_id
+			WHERE ur.user_id = ANY($1)
+			ORDER BY ur.user_id, r.role_name
+		`,
+	}
+	return r.namesByUserID(ctx, q, ids)
+}
+
+// GetPermissionNamesByUserIDs возвращает действующие разрешения (прямые и через роли) для набора пользователей
+func (r *userRepository) GetPermissionNamesByUserIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	q := db.Query{
+		Name: "user.GetPermissionNamesByUserIDs",
+		QueryRaw: `
+			SELECT user_id, permission_name FROM (
+				SELECT up.user_id, p.permission_name
+				FROM user_permissions up
+				JOIN permissions p ON p.id = up.permission_id
+				WHERE up.user_id = ANY($1)
+				UNION
+				SELECT ur.user_id, p.permission_name
+				FROM user_roles ur
+				JOIN role_effective_permissions rp ON rp.role_id = ur.role_id
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE ur.user_id = ANY($1)
+			) effective
+			ORDER BY user_id, permission_name
+		`,
+	}
+	return r.namesByUserID(ctx, q, ids)
+}
+
+// namesByUserID выполняет запрос, возвращающий пары (user_id, name), и группирует имена по пользователю
+func (r *userRepository) namesByUserID(ctx context.Context, q db.Query, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.DB().QueryContext(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			name   string
+		)
+		if err := rows.Scan(&userID, &name); err != nil {
+			return nil, err
+		}
+		result[userID] = append(result[userID], name)
+	}
+
+	return result, rows.Err()
+}
+
 // Role methods
 func (r *userRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]modelUser.Role, error) {
 	// Получаем роли пользователя
 	roleQuery := db.Query{
 		Name:     "user.GetUserRoles",
-		QueryRaw: `SELECT r.id, r.role_name as name, r.description FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = $1`,
+		QueryRaw: `SELECT r.id, r.role_name as name, r.description, r.is_system, r.organization_id FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = $1`,
 	}
 	var roles []modelUser.Role
 	err := r.db.DB().ScanAllContext(ctx, &roles, roleQuery, userID)
@@ -137,19 +624,9 @@ func (r *userRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 		return nil, err
 	}
 
-	// Для каждой роли загружаем разрешения
+	// Для каждой роли загружаем разрешения, включая унаследованные от родительских ролей
 	for i := range roles {
-		permQuery := db.Query{
-			Name: "user.GetRolePermissions",
-			QueryRaw: `
-				SELECT p.id, p.permission_name as name, p.description
-				FROM permissions p
-				JOIN role_permissions rp ON rp.permission_id = p.id
-				WHERE rp.role_id = $1
-			`,
-		}
-		var permissions []modelUser.Permission
-		err := r.db.DB().ScanAllContext(ctx, &permissions, permQuery, roles[i].ID)
+		permissions, err := r.GetRoleEffectivePermissions(ctx, roles[i].ID)
 		if err != nil {
 			return nil, err
 		}
@@ -159,31 +636,47 @@ func (r *userRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 	return roles, nil
 }
 
-func (r *userRepository) AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error {
+// AssignRole назначает роль пользователю организации арендатора; возвращает false, если пользователь не найден
+func (r *userRepository) AssignRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, roleID)
+	conds.Add("deleted_at IS NULL")
+
 	q := db.Query{
 		Name:     "user.AssignRole",
-		QueryRaw: `INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2)`,
+		QueryRaw: `INSERT INTO user_roles (user_id, role_id) SELECT id, $2::int FROM users` + conds.Where(),
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID, roleID)
-	return err
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
 
-func (r *userRepository) RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) error {
+// RemoveRole снимает роль с пользователя организации арендатора; возвращает false, если пользователь не найден.
+// Отсутствие назначения ошибкой не считается
+func (r *userRepository) RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, roleID)
+
 	q := db.Query{
-		Name:     "user.RemoveRole",
-		QueryRaw: `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`,
+		Name: "user.RemoveRole",
+		QueryRaw: `
+			WITH target AS (SELECT id FROM users` + conds.Where() + `),
+			removed AS (DELETE FROM user_roles WHERE user_id IN (SELECT id FROM target) AND role_id = $2)
+			SELECT EXISTS (SELECT 1 FROM target)
+		`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID, roleID)
-	return err
+
+	var found bool
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&found)
+	return found, err
 }
 
 func (r *userRepository) CreateRole(ctx context.Context, role *modelUser.RoleModel) error {
 	q := db.Query{
 		Name:     "user.CreateRole",
-		QueryRaw: `INSERT INTO roles (role_name, description) VALUES ($1, $2)`,
+		QueryRaw: `INSERT INTO roles (role_name, description, is_system, organization_id) VALUES ($1, $2, $3, $4) RETURNING id`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, role.RoleName, role.Description)
-	return err
+	return r.db.DB().QueryRowContext(ctx, q, role.RoleName, role.Description, role.IsSystem, role.OrganizationID).Scan(&role.ID)
 }
 
 func (r *userRepository) UpdateRole(ctx context.Context, role *modelUser.RoleModel) error {
@@ -204,16 +697,56 @@ func (r *userRepository) DeleteRole(ctx context.Context, roleID int) error {
 	return err
 }
 
+// GetAllRoles возвращает глобальные роли и роли организации арендатора
 func (r *userRepository) GetAllRoles(ctx context.Context) ([]modelUser.Role, error) {
+	conds := &db.Conditions{}
+	tenant.FilterShared(ctx, conds, "organization_id")
+
 	q := db.Query{
 		Name:     "user.GetAllRoles",
-		QueryRaw: `SELECT id, role_name as name, description FROM roles`,
+		QueryRaw: `SELECT id, role_name as name, description, is_system, organization_id FROM roles` + conds.Where() + ` ORDER BY id`,
 	}
 	var roles []modelUser.Role
-	err := r.db.DB().ScanAllContext(ctx, &roles, q)
+	err := r.db.DB().ScanAllContext(ctx, &roles, q, conds.Args()...)
 	return roles, err
 }
 
+// FindRoleByID ищет роль среди глобальных ролей и ролей организации арендатора
+func (r *userRepository) FindRoleByID(ctx context.Context, id int) (*modelUser.RoleModel, error) {
+	conds := &db.Conditions{}
+	conds.Add("id = ?", id)
+	tenant.FilterShared(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name:     "user.FindRoleByID",
+		QueryRaw: `SELECT id, role_name, description, is_system, organization_id FROM roles` + conds.Where(),
+	}
+	return r.findRole(ctx, q, conds.Args()...)
+}
+
+func (r *userRepository) findRole(ctx context.Context, q db.Query, args ...any) (*modelUser.RoleModel, error) {
+	var role modelUser.RoleModel
+	err := r.db.DB().ScanOneContext(ctx, &role, q, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CountRoleMembers возвращает число назначений роли, включая удалённых (soft delete) пользователей
+func (r *userRepository) CountRoleMembers(ctx context.Context, roleID int) (int64, error) {
+	q := db.Query{
+		Name:     "user.CountRoleMembers",
+		QueryRaw: `SELECT COUNT(*) FROM user_roles WHERE role_id = $1`,
+	}
+	var count int64
+	err := r.db.DB().QueryRowContext(ctx, q, roleID).Scan(&count)
+	return count, err
+}
+
 // Permission methods
 func (r *userRepository) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]modelUser.Permission, error) {
 	// Используем UNION для объединения разрешений из двух источников
@@ -228,10 +761,10 @@ func (r *userRepository) GetUserPermissions(ctx context.Context, userID uuid.UUI
 			
 			UNION
 			
-			-- Разрешения, полученные через роли
+			-- Разрешения, полученные через роли, включая унаследованные
 			SELECT DISTINCT p.id, p.permission_name as name, p.description
 			FROM permissions p
-			JOIN role_permissions rp ON p.id = rp.permission_id
+			JOIN role_effective_permissions rp ON p.id = rp.permission_id
 			JOIN user_roles ur ON rp.role_id = ur.role_id
 			WHERE ur.user_id = $1
 		`,
@@ -258,31 +791,135 @@ func (r *userRepository) GetRolePermissions(ctx context.Context, roleID int) ([]
 	return permissions, err
 }
 
-func (r *userRepository) AssignPermission(ctx context.Context, userID uuid.UUID, permissionID int) error {
+// GetRoleEffectivePermissions возвращает разрешения роли вместе с унаследованными от родительских ролей
+func (r *userRepository) GetRoleEffectivePermissions(ctx context.Context, roleID int) ([]modelUser.Permission, error) {
 	q := db.Query{
-		Name:     "user.AssignPermission",
-		QueryRaw: `INSERT INTO user_permissions (user_id, permission_id) VALUES ($1, $2)`,
+		Name: "user.GetRoleEffectivePermissions",
+		QueryRaw: `
+			SELECT p.id, p.permission_name as name, p.description
+			FROM permissions p
+			JOIN role_effective_permissions rp ON rp.permission_id = p.id
+			WHERE rp.role_id = $1
+			ORDER BY p.permission_name
+		`,
+	}
+	var permissions []modelUser.Permission
+	err := r.db.DB().ScanAllContext(ctx, &permissions, q, roleID)
+	return permissions, err
+}
+
+func (r *userRepository) GetRoleParentIDs(ctx context.Context, roleID int) ([]int, error) {
+	q := db.Query{
+		Name:     "user.GetRoleParentIDs",
+		QueryRaw: `SELECT parent_role_id FROM role_parents WHERE role_id = $1 ORDER BY parent_role_id`,
+	}
+	rows, err := r.db.DB().QueryContext(ctx, q, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parentIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		parentIDs = append(parentIDs, id)
+	}
+	return parentIDs, rows.Err()
+}
+
+// LockRoleHierarchy блокирует изменение иерархии ролей до конца транзакции, чтобы
+// параллельные изменения не образовали цикл, который не видит ни одна из проверок
+func (r *userRepository) LockRoleHierarchy(ctx context.Context) error {
+	q := db.Query{
+		Name:     "user.LockRoleHierarchy",
+		QueryRaw: `LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID, permissionID)
+	_, err := r.db.DB().ExecContext(ctx, q)
 	return err
 }
 
-func (r *userRepository) RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) error {
+// IsRoleAncestorOf проверяет, является ли роль ancestorID одной из ролей roleIDs или их предком
+func (r *userRepository) IsRoleAncestorOf(ctx context.Context, ancestorID int, roleIDs []int) (bool, error) {
 	q := db.Query{
-		Name:     "user.RemovePermission",
-		QueryRaw: `DELETE FROM user_permissions WHERE user_id = $1 AND permission_id = $2`,
+		Name: "user.IsRoleAncestorOf",
+		QueryRaw: `
+			SELECT EXISTS (
+				SELECT 1 FROM role_closure
+				WHERE role_id = ANY($2) AND ancestor_id = $1
+			)
+		`,
+	}
+	var exists bool
+	err := r.db.DB().QueryRowContext(ctx, q, ancestorID, roleIDs).Scan(&exists)
+	return exists, err
+}
+
+// SetRoleParents заменяет родительские роли
+func (r *userRepository) SetRoleParents(ctx context.Context, roleID int, parentIDs []int) error {
+	deleteQuery := db.Query{
+		Name:     "user.DeleteRoleParents",
+		QueryRaw: `DELETE FROM role_parents WHERE role_id = $1`,
+	}
+	if _, err := r.db.DB().ExecContext(ctx, deleteQuery, roleID); err != nil {
+		return err
+	}
+
+	insertQuery := db.Query{
+		Name: "user.InsertRoleParents",
+		QueryRaw: `
+			INSERT INTO role_parents (role_id, parent_role_id)
+			SELECT $1, unnest($2::int[])
+			ON CONFLICT DO NOTHING
+		`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID, permissionID)
+	_, err := r.db.DB().ExecContext(ctx, insertQuery, roleID, parentIDs)
 	return err
 }
 
+// AssignPermission выдаёт разрешение пользователю организации арендатора; возвращает false, если пользователь не найден
+func (r *userRepository) AssignPermission(ctx context.Context, userID uuid.UUID, permissionID int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, permissionID)
+	conds.Add("deleted_at IS NULL")
+
+	q := db.Query{
+		Name:     "user.AssignPermission",
+		QueryRaw: `INSERT INTO user_permissions (user_id, permission_id) SELECT id, $2::int FROM users` + conds.Where(),
+	}
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RemovePermission отзывает разрешение у пользователя организации арендатора; возвращает false, если пользователь
+// не найден. Отсутствие выданного разрешения ошибкой не считается
+func (r *userRepository) RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, permissionID)
+
+	q := db.Query{
+		Name: "user.RemovePermission",
+		QueryRaw: `
+			WITH target AS (SELECT id FROM users` + conds.Where() + `),
+			removed AS (DELETE FROM user_permissions WHERE user_id IN (SELECT id FROM target) AND permission_id = $2)
+			SELECT EXISTS (SELECT 1 FROM target)
+		`,
+	}
+
+	var found bool
+	err := r.db.DB().QueryRowContext(ctx, q, conds.Args()...).Scan(&found)
+	return found, err
+}
+
 func (r *userRepository) CreatePermission(ctx context.Context, permission *modelUser.PermissionModel) error {
 	q := db.Query{
 		Name:     "user.CreatePermission",
-		QueryRaw: `INSERT INTO permissions (permission_name, description) VALUES ($1, $2)`,
+		QueryRaw: `INSERT INTO permissions (permission_name, description) VALUES ($1, $2) RETURNING id`,
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, permission.PermissionName, permission.Description)
-	return err
+	return r.db.DB().QueryRowContext(ctx, q, permission.PermissionName, permission.Description).Scan(&permission.ID)
 }
 
 func (r *userRepository) UpdatePermission(ctx context.Context, permission *modelUser.PermissionModel) error {
@@ -306,27 +943,90 @@ func (r *userRepository) DeletePermission(ctx context.Context, permissionID int)
 func (r *userRepository) GetAllPermissions(ctx context.Context) ([]modelUser.Permission, error) {
 	q := db.Query{
 		Name:     "user.GetAllPermissions",
-		QueryRaw: `SELECT id, permission_name as name, description FROM permissions`,
+		QueryRaw: `SELECT id, permission_name as name, description FROM permissions ORDER BY permission_name`,
 	}
 	var permissions []modelUser.Permission
 	err := r.db.DB().ScanAllContext(ctx, &permissions, q)
 	return permissions, err
 }
 
-func (r *userRepository) FindRoleByName(ctx context.Context, name string) (*modelUser.RoleModel, error) {
+func (r *userRepository) FindPermissionByID(ctx context.Context, id int) (*modelUser.PermissionModel, error) {
 	q := db.Query{
-		Name:     "user.FindRoleByName",
-		QueryRaw: `SELECT id, role_name, description FROM roles WHERE role_name = $1`,
+		Name:     "user.FindPermissionByID",
+		QueryRaw: `SELECT id, permission_name, description FROM permissions WHERE id = $1`,
 	}
-	var role modelUser.RoleModel
-	err := r.db.DB().ScanOneContext(ctx, &role, q, name)
+	var permission modelUser.PermissionModel
+	err := r.db.DB().ScanOneContext(ctx, &permission, q, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &role, nil
+	return &permission, nil
+}
+
+// CountPermissionAssignments возвращает число ролей и пользователей, которым назначено разрешение
+func (r *userRepository) CountPermissionAssignments(ctx context.Context, permissionID int) (int64, error) {
+	q := db.Query{
+		Name: "user.CountPermissionAssignments",
+		QueryRaw: `
+			SELECT (SELECT COUNT(*) FROM role_permissions WHERE permission_id = $1)
+			     + (SELECT COUNT(*) FROM user_permissions WHERE permission_id = $1)
+		`,
+	}
+	var count int64
+	err := r.db.DB().QueryRowContext(ctx, q, permissionID).Scan(&count)
+	return count, err
+}
+
+// AddRolePermissions добавляет разрешения роли; уже назначенные пропускаются
+func (r *userRepository) AddRolePermissions(ctx context.Context, roleID int, permissionIDs []int) error {
+	q := db.Query{
+		Name: "user.AddRolePermissions",
+		QueryRaw: `
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT $1, unnest($2::int[])
+			ON CONFLICT DO NOTHING
+		`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q, roleID, permissionIDs)
+	return err
+}
+
+func (r *userRepository) RemoveRolePermissions(ctx context.Context, roleID int, permissionIDs []int) error {
+	q := db.Query{
+		Name:     "user.RemoveRolePermissions",
+		QueryRaw: `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = ANY($2)`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q, roleID, permissionIDs)
+	return err
+}
+
+// RemoveOtherRolePermissions снимает с роли все разрешения, кроме перечисленных
+func (r *userRepository) RemoveOtherRolePermissions(ctx context.Context, roleID int, keepIDs []int) error {
+	q := db.Query{
+		Name:     "user.RemoveOtherRolePermissions",
+		QueryRaw: `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id <> ALL($2)`,
+	}
+	_, err := r.db.DB().ExecContext(ctx, q, roleID, keepIDs)
+	return err
+}
+
+// FindRoleByName ищет роль среди глобальных ролей и ролей организации арендатора.
+// Одноимённые роли разных организаций видит только суперадминистратор; глобальная роль имеет приоритет
+func (r *userRepository) FindRoleByName(ctx context.Context, name string) (*modelUser.RoleModel, error) {
+	conds := &db.Conditions{}
+	conds.Add("role_name = ?", name)
+	tenant.FilterShared(ctx, conds, "organization_id")
+
+	q := db.Query{
+		Name: "user.FindRoleByName",
+		QueryRaw: `SELECT id, role_name, description, is_system, organization_id FROM roles` + conds.Where() + `
+			ORDER BY organization_id NULLS FIRST, id
+			LIMIT 1`,
+	}
+	return r.findRole(ctx, q, conds.Args()...)
 }
 
 func (r *userRepository) FindPermissionByName(ctx context.Context, name string) (*modelUser.PermissionModel, error) {
@@ -345,22 +1045,36 @@ func (r *userRepository) FindPermissionByName(ctx context.Context, name string)
 	return &permission, nil
 }
 
-func (r *userRepository) ConfirmEmail(ctx context.Context, userID uuid.UUID) error {
+// ConfirmEmail отмечает email подтверждённым, если версия записи совпадает с ожидаемой
+func (r *userRepository) ConfirmEmail(ctx context.Context, userID uuid.UUID, expectedVersion int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, expectedVersion)
+	conds.Add("version = $2")
+	conds.Add("deleted_at IS NULL")
+
 	q := db.Query{
 		Name:     "user.ConfirmEmail",
-		QueryRaw: `UPDATE users SET email_verified = true WHERE id = $1`,
+		QueryRaw: `UPDATE users SET email_verified = true, version = version + 1` + conds.Where(),
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID)
-
-	return err
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
 
-func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string) error {
+// ChangePassword сохраняет хеш нового пароля, если версия записи совпадает с ожидаемой
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string, expectedVersion int) (bool, error) {
+	conds := userWriteConditions(ctx, userID, newPassword, expectedVersion)
+	conds.Add("version = $3")
+	conds.Add("deleted_at IS NULL")
+
 	q := db.Query{
 		Name:     "user.ChangePassword",
-		QueryRaw: `UPDATE users SET password = $2 WHERE id = $1`,
+		QueryRaw: `UPDATE users SET password = $2, version = version + 1` + conds.Where(),
 	}
-	_, err := r.db.DB().ExecContext(ctx, q, userID, newPassword)
-
-	return err
+	tag, err := r.db.DB().ExecContext(ctx, q, conds.Args()...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
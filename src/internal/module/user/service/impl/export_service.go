@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/tabular"
+)
+
+const (
+	// maxConcurrentExports сколько выгрузок может выполняться одновременно
+	maxConcurrentExports = 2
+	// exportTimeout максимальная длительность одной выгрузки
+	exportTimeout = 30 * time.Minute
+)
+
+type exportService struct {
+	users      repository.UserRepository
+	jobs       repository.ExportJobRepository
+	logger     logger.Logger
+	config     config.ExportConfig
+	signingKey []byte
+	slots      chan struct{}
+}
+
+func NewExportService(
+	users repository.UserRepository,
+	jobs repository.ExportJobRepository,
+	logger logger.Logger,
+	config config.ExportConfig,
+	signingKey string,
+) service.ExportService {
+	return &exportService{
+		users:      users,
+		jobs:       jobs,
+		logger:     logger,
+		config:     config,
+		signingKey: []byte(signingKey),
+		slots:      make(chan struct{}, maxConcurrentExports),
+	}
+}
+
+// CreateExport сохраняет задание и запускает выгрузку в фоне
+func (s *exportService) CreateExport(ctx context.Context, requestedBy *uuid.UUID, req *model.CreateExportRequest) (*model.ExportJobDTO, error) {
+	job := &model.ExportJob{
+		ID:          uuid.New(),
+		RequestedBy: requestedBy,
+		Format:      req.Format,
+		Filters:     req.Filters,
+		Status:      model.ExportStatusPending,
+		CreatedAt:   time.Now(),
+
+		OrganizationID: tenant.OrganizationID(ctx),
+	}
+
+	if err := s.jobs.Create(ctx, job); err != nil {
+		s.logger.WithError(err).Error("Failed to create export job")
+		return nil, apperrors.InternalServerError("export.create_error", err, nil)
+	}
+
+	go s.run(job)
+
+	return s.toDTO(job), nil
+}
+
+// GetExport возвращает состояние задания; для готовой выгрузки добавляется подписанная ссылка
+func (s *exportService) GetExport(ctx context.Context, id uuid.UUID) (*model.ExportJobDTO, error) {
+	job, err := s.findJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDTO(job), nil
+}
+
+// OpenExport проверяет подпись ссылки и возвращает задание с путём к файлу
+func (s *exportService) OpenExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*model.ExportJob, string, error) {
+	if !s.validSignature(id, expires, signature) {
+		return nil, "", apperrors.ForbiddenError("export.invalid_signature", nil, nil)
+	}
+	if time.Now().Unix() > expires {
+		return nil, "", apperrors.ForbiddenError("export.link_expired", nil, nil)
+	}
+
+	job, err := s.findJob(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.Status != model.ExportStatusCompleted {
+		return nil, "", apperrors.ConflictError("export.not_ready", nil, map[string]any{"status": job.Status})
+	}
+	if job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt) {
+		return nil, "", apperrors.NotFoundError("export.not_found", nil, map[string]any{"id": id})
+	}
+
+	path := filepath.Join(s.config.Dir(), job.FilePath)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", apperrors.NotFoundError("export.not_found", err, map[string]any{"id": id})
+		}
+		return nil, "", apperrors.InternalServerError("export.download_error", err, nil)
+	}
+
+	return job, path, nil
+}
+
+func (s *exportService) findJob(ctx context.Context, id uuid.UUID) (*model.ExportJob, error) {
+	job, err := s.jobs.FindByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get export job")
+		return nil, apperrors.InternalServerError("export.get_error", err, nil)
+	}
+	if job == nil {
+		return nil, apperrors.NotFoundError("export.not_found", nil, map[string]any{"id": id})
+	}
+
+	return job, nil
+}
+
+// run выполняет выгрузку вне контекста запроса. Одновременно выполняется не больше maxConcurrentExports заданий
+func (s *exportService) run(job *model.ExportJob) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	log := s.logger.WithField("component", "ExportService.run").WithField("export_id", job.ID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("panic", r).Error("Export panicked")
+			s.fail(ctx, job.ID, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	s.cleanupExpired(ctx)
+
+	started, err := s.jobs.MarkRunning(ctx, job.ID, time.Now())
+	if err != nil {
+		log.WithError(err).Error("Failed to start export")
+		return
+	}
+	if !started {
+		return
+	}
+
+	fileName, rowCount, err := s.writeFile(ctx, job)
+	if err != nil {
+		log.WithError(err).Error("Export failed")
+		s.fail(ctx, job.ID, err)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(s.config.RetentionHours()) * time.Hour)
+	if err := s.jobs.MarkCompleted(ctx, job.ID, fileName, rowCount, now, expiresAt); err != nil {
+		log.WithError(err).Error("Failed to complete export")
+		s.removeFile(fileName)
+		return
+	}
+
+	log.WithField("rows", rowCount).Info("Export completed")
+}
+
+// jobContext возвращает контекст фоновой выгрузки от имени арендатора, создавшего задание,
+// в том числе для политик row-level security. Задание без арендатора и организации создано
+// на уровне платформы без ограничений, поэтому политики для него отключаются явно
+func (s *exportService) jobContext(ctx context.Context, job *model.ExportJob) context.Context {
+	t := job.Filters.Tenant
+	if t == nil && job.OrganizationID != nil {
+		t = &tenant.Tenant{OrganizationID: job.OrganizationID}
+	}
+	if t == nil {
+		return db.WithoutRowSecurity(ctx)
+	}
+
+	var userID string
+	if job.RequestedBy != nil {
+		userID = job.RequestedBy.String()
+	}
+	ctx = tenant.WithContext(ctx, t)
+	return db.WithSession(ctx, t.Session(userID, nil))
+}
+
+// writeFile потоково пишет пользователей во временный файл и переименовывает его по готовности
+func (s *exportService) writeFile(ctx context.Context, job *model.ExportJob) (string, int, error) {
+	if err := os.MkdirAll(s.config.Dir(), 0o750); err != nil {
+		return "", 0, err
+	}
+
+	tmp, err := os.CreateTemp(s.config.Dir(), job.ID.String()+".*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	writer, err := tabular.NewWriter(tmp, tabular.Format(job.Format), model.ExportColumns)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ctx = s.jobContext(ctx, job)
+
+	filter := job.Filters.UserFilter()
+	filter.Limit = s.config.BatchSize()
+
+	rowCount := 0
+	for {
+		users, err := s.users.FindPage(ctx, filter)
+		if err != nil {
+			return "", 0, err
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		roles, err := s.users.GetRoleNamesByUserIDs(ctx, ids)
+		if err != nil {
+			return "", 0, err
+		}
+		permissions, err := s.users.GetPermissionNamesByUserIDs(ctx, ids)
+		if err != nil {
+			return "", 0, err
+		}
+
+		for _, user := range users {
+			row := model.ExportRow(model.UserDTOFromDBModel(user), roles[user.ID], permissions[user.ID])
+			if err := writer.Write(row); err != nil {
+				return "", 0, err
+			}
+		}
+		rowCount += len(users)
+
+		if len(users) < filter.Limit {
+			break
+		}
+		last := users[len(users)-1]
+		filter.After = &model.UserCursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: last.SortValue(filter.Sort),
+			ID:    last.ID,
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	fileName := job.ID.String() + "." + job.Format
+	if err := os.Rename(tmp.Name(), filepath.Join(s.config.Dir(), fileName)); err != nil {
+		return "", 0, err
+	}
+
+	return fileName, rowCount, nil
+}
+
+func (s *exportService) fail(ctx context.Context, id uuid.UUID, cause error) {
+	if err := s.jobs.MarkFailed(ctx, id, cause.Error(), time.Now()); err != nil {
+		s.logger.WithError(err).WithField("export_id", id).Error("Failed to mark export as failed")
+	}
+}
+
+// cleanupExpired удаляет задания с истёкшим сроком хранения вместе с файлами
+func (s *exportService) cleanupExpired(ctx context.Context) {
+	files, err := s.jobs.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to delete expired exports")
+		return
+	}
+	for _, file := range files {
+		s.removeFile(file)
+	}
+}
+
+func (s *exportService) removeFile(fileName string) {
+	if fileName == "" {
+		return
+	}
+	err := os.Remove(filepath.Join(s.config.Dir(), fileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.WithError(err).WithField("file", fileName).Warn("Failed to remove export file")
+	}
+}
+
+// toDTO преобразует задание для API и подписывает ссылку на скачивание готового файла
+func (s *exportService) toDTO(job *model.ExportJob) *model.ExportJobDTO {
+	dto := &model.ExportJobDTO{
+		ID:         job.ID,
+		Format:     job.Format,
+		Filters:    job.Filters,
+		Status:     job.Status,
+		RowCount:   job.RowCount,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		ExpiresAt:  job.ExpiresAt,
+	}
+
+	if job.Status != model.ExportStatusCompleted {
+		return dto
+	}
+
+	// Ссылка не переживает сам файл
+	linkExpires := time.Now().Add(time.Duration(s.config.LinkTTLSeconds()) * time.Second)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(linkExpires) {
+		linkExpires = *job.ExpiresAt
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(linkExpires.Unix(), 10))
+	query.Set("signature", s.sign(job.ID, linkExpires.Unix()))
+
+	dto.DownloadURL = fmt.Sprintf("%s/api/v1/users/exports/%s/download?%s", s.config.PublicURL(), job.ID, query.Encode())
+	dto.DownloadExpiresAt = &linkExpires
+
+	return dto
+}
+
+// sign вычисляет HMAC-SHA256 подпись ссылки на скачивание
+func (s *exportService) sign(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(id.String() + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *exportService) validSignature(id uuid.UUID, expires int64, signature string) bool {
+	expected, err := hex.DecodeString(s.sign(id, expires))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
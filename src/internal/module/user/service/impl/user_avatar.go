@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/imaging"
+)
+
+// SetAvatar загружает новый аватар пользователя: проверяет тип по содержимому, строит
+// квадратное изображение и миниатюры, сохраняет их в хранилище и удаляет файлы прежнего аватара
+func (s *userService) SetAvatar(ctx context.Context, id uuid.UUID, data []byte) (*model.UserDTO, error) {
+	if len(data) > model.MaxAvatarFileSize {
+		return nil, apperrors.NewAppError(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "user.avatar_too_large", nil, map[string]any{
+			"max_size": model.MaxAvatarFileSize,
+		})
+	}
+
+	contentType, err := imaging.SniffType(data)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "user.avatar_unsupported_type", err, map[string]any{
+			"content_type": contentType,
+			"supported":    imaging.SupportedTypes,
+		})
+	}
+
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for avatar upload")
+		return nil, apperrors.InternalServerError("user.avatar_error", err, nil)
+	}
+	if user == nil {
+		return nil, apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	img, err := imaging.Decode(data, model.MaxAvatarPixels)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, apperrors.BadRequestError("user.avatar_too_large_dimensions", err, map[string]any{
+				"max_pixels": model.MaxAvatarPixels,
+			})
+		}
+		return nil, apperrors.BadRequestError("user.avatar_invalid_image", err, nil)
+	}
+
+	// Каждая загрузка получает новый префикс: ссылки на старые файлы не отдают новое изображение из кэша
+	prefix := "avatars/" + id.String() + "/" + uuid.NewString()
+	if err := s.storeAvatar(ctx, prefix, img); err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to store avatar")
+		s.removeAvatarFiles(ctx, prefix)
+		return nil, apperrors.InternalServerError("user.avatar_error", err, nil)
+	}
+
+	oldPrefix, found, err := s.repo.UpdateAvatar(ctx, id, prefix)
+	if err != nil || !found {
+		s.removeAvatarFiles(ctx, prefix)
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", id).Error("Failed to update avatar")
+			return nil, apperrors.InternalServerError("user.avatar_error", err, nil)
+		}
+		return nil, apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+	s.removeAvatarFiles(ctx, oldPrefix)
+
+	updated, err := s.repo.FindByID(ctx, id)
+	if err != nil || updated == nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to reload user after avatar upload")
+		return nil, apperrors.InternalServerError("user.avatar_error", err, nil)
+	}
+
+	return model.UserDTOFromDBModel(updated), nil
+}
+
+// DeleteAvatar удаляет аватар пользователя
+func (s *userService) DeleteAvatar(ctx context.Context, id uuid.UUID) error {
+	oldPrefix, found, err := s.repo.UpdateAvatar(ctx, id, "")
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to delete avatar")
+		return apperrors.InternalServerError("user.avatar_error", err, nil)
+	}
+	if !found {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	s.removeAvatarFiles(ctx, oldPrefix)
+	return nil
+}
+
+// storeAvatar сохраняет основное изображение и миниатюры. Каждая миниатюра строится
+// из предыдущего, большего изображения, чтобы не обрабатывать исходник повторно
+func (s *userService) storeAvatar(ctx context.Context, prefix string, img image.Image) error {
+	current := image.Image(imaging.SquareThumbnail(img, model.AvatarSize))
+	sizes := append([]int{model.AvatarSize}, model.AvatarThumbnailSizes...)
+
+	for i, size := range sizes {
+		if i > 0 {
+			current = imaging.SquareThumbnail(current, size)
+		}
+
+		encoded, err := imaging.EncodeJPEG(current, model.AvatarQuality)
+		if err != nil {
+			return err
+		}
+
+		key := model.AvatarObjectKey(prefix, size)
+		if err := s.storage.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), "image/jpeg"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeAvatarFiles удаляет файлы аватара; ошибки только логируются, чтобы не откатывать уже сохранённые изменения
+func (s *userService) removeAvatarFiles(ctx context.Context, prefix string) {
+	for _, key := range model.AvatarObjectKeys(prefix) {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			s.logger.WithError(err).WithField("key", key).Warn("Failed to delete avatar file")
+		}
+	}
+}
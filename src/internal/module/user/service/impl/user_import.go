@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/i18n"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/tabular"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// importPlan строка, прошедшая проверку, и найденный по email пользователь (для обновления)
+type importPlan struct {
+	row      *model.ImportUserRow
+	existing *model.UserModel
+	roleIDs  []int
+
+	// organizationID организация создаваемого пользователя
+	organizationID *uuid.UUID
+}
+
+// importRowFailure ошибка сохранения строки с ключом перевода и полем
+type importRowFailure struct {
+	field string
+	key   string
+	err   error
+}
+
+func (f *importRowFailure) Error() string {
+	if f.err != nil {
+		return f.key + ": " + f.err.Error()
+	}
+	return f.key
+}
+
+func (f *importRowFailure) Unwrap() error {
+	return f.err
+}
+
+// ImportUsers создаёт или обновляет пользователей из таблицы.
+// Сначала проверяются все строки, затем изменения сохраняются в выбранном режиме
+func (s *userService) ImportUsers(ctx context.Context, table *tabular.Table, opts *model.ImportOptions) (*model.ImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = model.ImportModeTransactional
+	}
+
+	columns, err := importColumns(table, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.repo.GetAllRoles(ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get roles for import")
+		return nil, apperrors.InternalServerError("user.import_error", err, nil)
+	}
+	// Новые пользователи создаются в организации арендатора и получают только доступные ей роли
+	roleIDs := assignableRoles(roles, tenant.OrganizationID(ctx))
+
+	report := &model.ImportReport{
+		Mode:   opts.Mode,
+		DryRun: opts.DryRun,
+		Total:  len(table.Rows),
+		Errors: make([]model.ImportRowError, 0),
+	}
+
+	plans := make([]*importPlan, 0, len(table.Rows))
+	failedRows := make(map[int]bool)
+	seen := make(map[string]int, len(table.Rows))
+
+	for _, tableRow := range table.Rows {
+		row, rowErrors := parseImportRow(tableRow, columns)
+
+		if row != nil {
+			email := strings.ToLower(row.Email)
+			if line, ok := seen[email]; ok {
+				rowErrors = append(rowErrors, importError(tableRow.Line, model.ImportFieldEmail, "user.import_duplicate_email", line))
+			} else {
+				seen[email] = tableRow.Line
+			}
+		}
+
+		var plan *importPlan
+		if len(rowErrors) == 0 {
+			plan, rowErrors, err = s.planImportRow(ctx, row, roleIDs, opts)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			failedRows[tableRow.Line] = true
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	if opts.DryRun {
+		for _, plan := range plans {
+			countImportPlan(report, plan)
+		}
+		report.Failed = len(failedRows)
+		return report, nil
+	}
+
+	switch opts.Mode {
+	case model.ImportModeBestEffort:
+		for _, plan := range plans {
+			err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+				return s.applyImportPlan(ctx, plan)
+			})
+			if err != nil {
+				report.Errors = append(report.Errors, s.importFailure(plan.row.Line, err))
+				failedRows[plan.row.Line] = true
+				continue
+			}
+			countImportPlan(report, plan)
+		}
+		report.Committed = report.Created+report.Updated > 0
+
+	default:
+		// В транзакционном режиме ошибки проверки отменяют весь импорт
+		if len(failedRows) > 0 {
+			break
+		}
+
+		var failedLine int
+		err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+			for _, plan := range plans {
+				if err := s.applyImportPlan(ctx, plan); err != nil {
+					failedLine = plan.row.Line
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			report.Errors = append(report.Errors, s.importFailure(failedLine, err))
+			failedRows[failedLine] = true
+			break
+		}
+
+		for _, plan := range plans {
+			countImportPlan(report, plan)
+		}
+		report.Committed = true
+	}
+
+	report.Failed = len(failedRows)
+
+	s.logger.WithFields(logrus.Fields{
+		"mode":      report.Mode,
+		"total":     report.Total,
+		"created":   report.Created,
+		"updated":   report.Updated,
+		"failed":    report.Failed,
+		"committed": report.Committed,
+	}).Info("Users import finished")
+
+	return report, nil
+}
+
+// importColumns сопоставляет поля импорта с индексами колонок таблицы
+func importColumns(table *tabular.Table, mapping map[string]string) (map[string]int, error) {
+	for field := range mapping {
+		if !slices.Contains(model.ImportFields, field) {
+			return nil, apperrors.BadRequestError("user.import_unknown_field", nil, map[string]any{
+				"field":     field,
+				"supported": model.ImportFields,
+			})
+		}
+	}
+
+	columns := make(map[string]int, len(model.ImportFields))
+	for _, field := range model.ImportFields {
+		header := field
+		if mapped, ok := mapping[field]; ok && mapped != "" {
+			header = mapped
+		}
+		columns[field] = table.Index(header)
+	}
+
+	var missing []string
+	for _, field := range model.ImportRequiredFields {
+		if columns[field] < 0 {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.BadRequestError("user.import_missing_columns", nil, map[string]any{
+			"fields": missing,
+			"header": table.Header,
+		})
+	}
+
+	return columns, nil
+}
+
+// parseImportRow разбирает и проверяет значения строки
+func parseImportRow(tableRow tabular.Row, columns map[string]int) (*model.ImportUserRow, []model.ImportRowError) {
+	value := func(field string) string {
+		return strings.TrimSpace(tableRow.Get(columns[field]))
+	}
+
+	row := &model.ImportUserRow{
+		Line:       tableRow.Line,
+		Email:      value(model.ImportFieldEmail),
+		FirstName:  value(model.ImportFieldFirstName),
+		LastName:   value(model.ImportFieldLastName),
+		MiddleName: value(model.ImportFieldMiddleName),
+		Phone:      value(model.ImportFieldPhone),
+		Position:   value(model.ImportFieldPosition),
+		DataRole:   value(model.ImportFieldDataRole),
+		Password:   tableRow.Get(columns[model.ImportFieldPassword]),
+		Roles:      model.ParseImportList(value(model.ImportFieldRoles)),
+	}
+
+	var rowErrors []model.ImportRowError
+
+	active, ok := model.ParseImportBool(value(model.ImportFieldActive))
+	if !ok {
+		rowErrors = append(rowErrors, importError(row.Line, model.ImportFieldActive, "user.import_invalid_bool"))
+	}
+	row.Active = active
+
+	if err := apperrors.ValidateRequest(row); err != nil {
+		var appErr *apperrors.AppError
+		var fields []apperrors.ValidationFieldError
+		if errors.As(err, &appErr) {
+			fields, _ = appErr.Details.([]apperrors.ValidationFieldError)
+		}
+		for _, field := range fields {
+			rowErrors = append(rowErrors, model.ImportRowError{
+				Row:     row.Line,
+				Field:   model.ImportRowField(field.Field),
+				Code:    "errors.validation",
+				Message: field.Message,
+			})
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+	return row, nil
+}
+
+// planImportRow проверяет строку относительно данных БД: роли, существование email и права на обновление
+func (s *userService) planImportRow(ctx context.Context, row *model.ImportUserRow, roleIDs map[string]int, opts *model.ImportOptions) (*importPlan, []model.ImportRowError, error) {
+	var rowErrors []model.ImportRowError
+
+	plan := &importPlan{row: row, organizationID: tenant.OrganizationID(ctx)}
+	for _, name := range row.Roles {
+		id, ok := roleIDs[name]
+		if !ok {
+			rowErrors = append(rowErrors, importError(row.Line, model.ImportFieldRoles, "user.import_unknown_role", name))
+			continue
+		}
+		plan.roleIDs = append(plan.roleIDs, id)
+	}
+
+	existing, err := s.repo.FindByEmail(ctx, row.Email)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to find user for import")
+		return nil, nil, apperrors.InternalServerError("user.import_error", err, nil)
+	}
+
+	if existing != nil {
+		switch {
+		case !opts.Upsert, !tenant.FromContext(ctx).Allows(existing.OrganizationID):
+			// Пользователь другой организации не раскрывается: для арендатора email просто занят
+			rowErrors = append(rowErrors, importError(row.Line, model.ImportFieldEmail, "user.email_exists"))
+		case !opts.AllowUpdate:
+			rowErrors = append(rowErrors, importError(row.Line, model.ImportFieldEmail, "user.import_update_forbidden"))
+		case !opts.AllowRestricted && importChangesRestricted(row, existing):
+			rowErrors = append(rowErrors, importError(row.Line, "", "user.field_forbidden"))
+		}
+		plan.existing = existing
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+	return plan, nil, nil
+}
+
+// importChangesRestricted проверяет, меняет ли строка active или data_role существующего пользователя
+func importChangesRestricted(row *model.ImportUserRow, existing *model.UserModel) bool {
+	if row.Active != nil && *row.Active != existing.Active {
+		return true
+	}
+	return row.DataRole != "" && row.DataRole != existing.DataRole
+}
+
+// applyImportPlan сохраняет одну строку импорта
+func (s *userService) applyImportPlan(ctx context.Context, plan *importPlan) error {
+	row := plan.row
+
+	if plan.existing == nil {
+		password := row.Password
+		if password == "" {
+			// Пользователь без пароля входит после сброса пароля или через SSO
+			generated, err := importRandomPassword()
+			if err != nil {
+				return err
+			}
+			password = generated
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user := &model.UserModel{
+			ID:         uuid.New(),
+			Email:      row.Email,
+			Password:   string(hashedPassword),
+			FirstName:  row.FirstName,
+			LastName:   row.LastName,
+			MiddleName: row.MiddleName,
+			Phone:      row.Phone,
+			Position:   row.Position,
+			Active:     row.Active == nil || *row.Active,
+			DataRole:   row.DataRole,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+
+			OrganizationID: plan.organizationID,
+		}
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+
+		for _, roleID := range plan.roleIDs {
+			if _, err := s.repo.AssignRole(ctx, user.ID, roleID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Пустые ячейки при обновлении оставляют значение без изменений
+	user := *plan.existing
+	user.FirstName = row.FirstName
+	user.LastName = row.LastName
+	if row.MiddleName != "" {
+		user.MiddleName = row.MiddleName
+	}
+	if row.Phone != "" {
+		user.Phone = row.Phone
+	}
+	if row.Position != "" {
+		user.Position = row.Position
+	}
+	if row.Active != nil {
+		user.Active = *row.Active
+	}
+	if row.DataRole != "" {
+		user.DataRole = row.DataRole
+	}
+
+	updated, err := s.repo.UpdateProfile(ctx, &user, plan.existing.Version)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return &importRowFailure{key: "user.version_conflict"}
+	}
+
+	if row.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(row.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		changed, err := s.repo.ChangePassword(ctx, user.ID, string(hashedPassword), user.Version)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return &importRowFailure{key: "user.version_conflict"}
+		}
+	}
+
+	if len(plan.roleIDs) > 0 {
+		current, err := s.repo.GetUserRoles(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for _, roleID := range plan.roleIDs {
+			if slices.ContainsFunc(current, func(role model.Role) bool { return role.ID == roleID }) {
+				continue
+			}
+			if _, err := s.repo.AssignRole(ctx, user.ID, roleID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// importFailure преобразует ошибку сохранения строки в запись отчёта
+func (s *userService) importFailure(line int, err error) model.ImportRowError {
+	var failure *importRowFailure
+	if errors.As(err, &failure) {
+		return importError(line, failure.field, failure.key)
+	}
+
+	s.logger.WithError(err).WithField("row", line).Error("Failed to import user row")
+	return importError(line, "", "user.import_row_error")
+}
+
+func countImportPlan(report *model.ImportReport, plan *importPlan) {
+	if plan.existing != nil {
+		report.Updated++
+	} else {
+		report.Created++
+	}
+}
+
+func importError(line int, field, key string, args ...any) model.ImportRowError {
+	return model.ImportRowError{
+		Row:     line,
+		Field:   field,
+		Code:    key,
+		Message: i18n.GetInstance().T(key, args...),
+	}
+}
+
+func importRandomPassword() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
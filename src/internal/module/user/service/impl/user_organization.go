@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// CreateOrganization создаёт организацию. Slug уникален на платформе и после создания не меняется
+func (s *userService) CreateOrganization(ctx context.Context, req *model.CreateOrganizationRequest) (*model.Organization, error) {
+	if err := ensureSuperadmin(ctx); err != nil {
+		return nil, err
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !model.OrganizationSlugPattern.MatchString(slug) {
+		return nil, apperrors.ValidationError("organization.invalid_slug", nil, map[string]any{"slug": req.Slug})
+	}
+
+	existing, err := s.organizations.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.ConflictError("organization.slug_exists", nil, map[string]any{"slug": slug})
+	}
+
+	organization := &model.Organization{
+		ID:   uuid.New(),
+		Name: strings.TrimSpace(req.Name),
+		Slug: slug,
+	}
+	if err := s.organizations.Create(ctx, organization); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("organization_id", organization.ID).Info("Organization created")
+	return organization, nil
+}
+
+// ListOrganizations возвращает организации, доступные арендатору запроса
+func (s *userService) ListOrganizations(ctx context.Context) ([]*model.Organization, error) {
+	if err := ensureSuperadmin(ctx); err != nil {
+		return nil, err
+	}
+
+	organizations, err := s.organizations.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if organizations == nil {
+		organizations = []*model.Organization{}
+	}
+	return organizations, nil
+}
+
+// GetOrganization возвращает организацию по ID
+func (s *userService) GetOrganization(ctx context.Context, id uuid.UUID) (*model.Organization, error) {
+	if err := ensureSuperadmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.findOrganizationOrFail(ctx, id)
+}
+
+// UpdateOrganization изменяет название организации
+func (s *userService) UpdateOrganization(ctx context.Context, id uuid.UUID, req *model.UpdateOrganizationRequest) (*model.Organization, error) {
+	if err := ensureSuperadmin(ctx); err != nil {
+		return nil, err
+	}
+
+	organization, err := s.findOrganizationOrFail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		organization.Name = strings.TrimSpace(*req.Name)
+	}
+	if err := s.organizations.Update(ctx, organization); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (s *userService) findOrganizationOrFail(ctx context.Context, id uuid.UUID) (*model.Organization, error) {
+	organization, err := s.organizations.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if organization == nil {
+		return nil, apperrors.NotFoundError("organization.not_found", nil, map[string]any{"id": id.String()})
+	}
+	return organization, nil
+}
+
+// ensureSuperadmin разрешает управление организациями только суперадминистратору платформы.
+// Дублирует проверку политики маршрутов, чтобы сервис нельзя было вызвать в обход неё
+func ensureSuperadmin(ctx context.Context) error {
+	if !tenant.IsSuperadmin(corepolicy.PrincipalFromContext(ctx)) {
+		return apperrors.ForbiddenError("organization.superadmin_required", nil, nil)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
+	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+)
+
+// GetProfile возвращает профиль пользователя для self-service API
+func (s *userService) GetProfile(ctx context.Context, userID uuid.UUID) (*model.Profile, error) {
+	user, err := s.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get profile")
+		return nil, apperrors.InternalServerError("user.profile_error", err, nil)
+	}
+	if user == nil {
+		return nil, apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+
+	return model.ProfileFromUser(user), nil
+}
+
+// UpdateOwnProfile изменяет собственный профиль пользователя. expectedVersion 0 отключает проверку версии
+func (s *userService) UpdateOwnProfile(ctx context.Context, userID uuid.UUID, expectedVersion int, patch *model.ProfilePatch) (*model.Profile, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user for profile update")
+		return nil, apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if user == nil {
+		return nil, apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+
+	if expectedVersion == 0 {
+		expectedVersion = user.Version
+	}
+	if user.Version != expectedVersion {
+		return nil, apperrors.ConflictError("user.version_conflict", nil, map[string]any{
+			"current_version": user.Version,
+		})
+	}
+
+	if err := patch.Apply(user); err != nil {
+		if errors.Is(err, model.ErrPatchUnknownField) || errors.Is(err, model.ErrPatchNotObject) {
+			return nil, apperrors.BadRequestError("user.invalid_patch", err, map[string]any{"message": err.Error()})
+		}
+		return nil, apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if err := apperrors.ValidateRequest(&user.Preferences); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateProfile(ctx, user, expectedVersion)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to update own profile")
+		return nil, apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if !updated {
+		return nil, apperrors.ConflictError("user.version_conflict", nil, nil)
+	}
+
+	return s.GetProfile(ctx, userID)
+}
+
+// DeactivateAccount отключает учётную запись по запросу самого пользователя и завершает все его сессии.
+// Запись не удаляется: повторно включить её может администратор
+func (s *userService) DeactivateAccount(ctx context.Context, userID uuid.UUID, ipAddress string) error {
+	return s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		deactivated, err := s.repo.Deactivate(ctx, userID)
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to deactivate account")
+			return apperrors.InternalServerError("user.deactivate_error", err, nil)
+		}
+		if !deactivated {
+			return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+		}
+
+		if err := s.sessions.RevokeAllUserTokens(ctx, userID, ipAddress); err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to revoke sessions of deactivated account")
+			return apperrors.InternalServerError("user.deactivate_error", err, nil)
+		}
+
+		s.logger.WithField("user_id", userID).Info("Account deactivated by owner")
+		return nil
+	})
+}
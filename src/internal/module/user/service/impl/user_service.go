@@ -2,18 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/client/db"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/config"
 	apperrors "github.com/xdevspo/go_tmpl_module_app/internal/core/errors"
 	"github.com/xdevspo/go_tmpl_module_app/internal/core/logger"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
+	"github.com/xdevspo/go_tmpl_module_app/internal/core/tenant"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/repository"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/service"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/permission"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/storage"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,13 +30,36 @@ type userService struct {
 	repo      repository.UserRepository
 	logger    logger.Logger
 	txManager db.TxManager
+	sessions  service.SessionRevoker
+	config    config.UserConfig
+	storage   storage.Storage
+
+	suspensions repository.SuspensionRepository
+
+	organizations repository.OrganizationRepository
 }
 
-func NewUserService(repo repository.UserRepository, logger logger.Logger, txManager db.TxManager) service.UserService {
+func NewUserService(
+	repo repository.UserRepository,
+	logger logger.Logger,
+	txManager db.TxManager,
+	sessions service.SessionRevoker,
+	config config.UserConfig,
+	storage storage.Storage,
+	suspensions repository.SuspensionRepository,
+	organizations repository.OrganizationRepository,
+) service.UserService {
 	return &userService{
 		repo:      repo,
 		logger:    logger,
 		txManager: txManager,
+		sessions:  sessions,
+		config:    config,
+		storage:   storage,
+
+		suspensions: suspensions,
+
+		organizations: organizations,
 	}
 }
 
@@ -50,6 +82,11 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 		return nil, apperrors.ConflictError("user.email_exists", nil, map[string]any{"email": req.Email})
 	}
 
+	organizationID, err := s.newUserOrganization(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -71,6 +108,8 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 		EmailVerified: false,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+
+		OrganizationID: organizationID,
 	}
 
 	var roles []model.Role
@@ -99,12 +138,11 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 		}
 
 		s.logger.WithField("available_roles_count", len(availableRoles)).Info("Available roles")
-		roleMap := make(map[string]int)
-		for _, r := range availableRoles {
-			roleMap[r.Name] = r.ID
+		roleMap := assignableRoles(availableRoles, user.OrganizationID)
+		for name, id := range roleMap {
 			s.logger.WithFields(logrus.Fields{
-				"role_id":   r.ID,
-				"role_name": r.Name,
+				"role_id":   id,
+				"role_name": name,
 			}).Info("Available role")
 		}
 
@@ -123,7 +161,7 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 					"role_name": role.Name,
 				}).Info("Assigning permission to user from cache")
 
-				if err := s.repo.AssignRole(ctx, user.ID, roleID); err != nil {
+				if _, err := s.repo.AssignRole(ctx, user.ID, roleID); err != nil {
 					s.logger.WithError(err).WithField("role", role.Name).Error("Failed to assign role")
 					return err
 				}
@@ -136,7 +174,7 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 				s.logger.WithError(err).WithField("role", role.Name).Error("Failed to find role")
 				return err
 			}
-			if roleModel == nil {
+			if roleModel == nil || !roleModel.AvailableTo(user.OrganizationID) {
 				notFoundErr := apperrors.NotFoundError("role.not_found", nil, map[string]interface{}{"name": role.Name})
 				s.logger.WithError(notFoundErr).
 					WithField("role", role.Name).
@@ -151,7 +189,7 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 				"role_name": roleModel.RoleName,
 			}).Info("Assigning role to user")
 
-			if err := s.repo.AssignRole(ctx, user.ID, roleModel.ID); err != nil {
+			if _, err := s.repo.AssignRole(ctx, user.ID, roleModel.ID); err != nil {
 				s.logger.WithError(err).WithField("role", role.Name).Error("Failed to assign role")
 				return err
 			}
@@ -176,6 +214,15 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 			}).Info("Available permission")
 		}
 
+		// Прямые разрешения выдаются, только если они есть у субъекта запроса
+		requested := make([]string, 0, len(req.Permissions))
+		for _, perm := range req.Permissions {
+			requested = append(requested, perm.Name)
+		}
+		if err := ensurePermissionsHeld(ctx, requested...); err != nil {
+			return err
+		}
+
 		// Assign permissions
 		for _, perm := range req.Permissions {
 			s.logger.WithFields(logrus.Fields{
@@ -191,7 +238,7 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 					"permission_name": perm.Name,
 				}).Info("Assigning permission to user from cache")
 
-				if err := s.repo.AssignPermission(ctx, user.ID, permID); err != nil {
+				if _, err := s.repo.AssignPermission(ctx, user.ID, permID); err != nil {
 					s.logger.WithError(err).WithField("permission", perm.Name).Error("Failed to assign permission")
 					return err
 				}
@@ -224,7 +271,7 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 				"permission_name": permModel.PermissionName,
 			}).Info("Assigning permission to user")
 
-			if err := s.repo.AssignPermission(ctx, user.ID, permModel.ID); err != nil {
+			if _, err := s.repo.AssignPermission(ctx, user.ID, permModel.ID); err != nil {
 				s.logger.WithError(err).WithField("permission", perm.Name).Error("Failed to assign permission")
 				return err
 			}
@@ -256,8 +303,9 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 			WithField("errorType", reflect.TypeOf(txErr).String()).
 			Error("Transaction failed")
 
-		// Создаем новый контекст для проверки, т.к. старый контекст может содержать отмененную транзакцию
-		newCtx := context.Background()
+		// Создаем новый контекст для проверки, т.к. старый контекст может содержать отмененную транзакцию.
+		// Без параметров сессии политики row-level security скрыли бы запись, поэтому они отключаются явно
+		newCtx := db.WithoutRowSecurity(context.Background())
 		// Проверим, остался ли пользователь в базе данных несмотря на ошибку
 		checkUser, checkErr := s.repo.FindByEmail(newCtx, user.Email)
 		if checkErr != nil {
@@ -277,25 +325,197 @@ func (s *userService) Create(ctx context.Context, req *model.CreateUserRequest)
 	return model.FromDBModel(user, roles, permissions), nil
 }
 
-// Update updates an existing user
+// Update updates an existing user.
+// Запись сохраняется, только если её версия совпадает с user.Version, иначе возвращается 409
 func (s *userService) Update(ctx context.Context, user *model.User) error {
 	dbUser := user.ToDBModel()
 	dbUser.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, dbUser)
+
+	updated, err := s.repo.Update(ctx, dbUser, user.Version)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to update user")
+		return apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if !updated {
+		return s.writeConflict(ctx, user.ID, user.Version)
+	}
+
+	user.Version = dbUser.Version
+	user.UpdatedAt = dbUser.UpdatedAt
+	return nil
+}
+
+// Patch частично обновляет профиль пользователя с оптимистичной блокировкой.
+// expectedVersion - версия, на основе которой клиент сформировал изменения (0 - любая).
+// Поля active и data_role может менять только администратор (allowRestricted)
+func (s *userService) Patch(ctx context.Context, id uuid.UUID, expectedVersion int, patch *model.UserPatch, allowRestricted bool) (*model.UserDTO, error) {
+	if restricted := patch.RestrictedFields(); len(restricted) > 0 && !allowRestricted {
+		return nil, apperrors.ForbiddenError("user.field_forbidden", nil, map[string]any{
+			"fields": restricted,
+		})
+	}
+
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for update")
+		return nil, apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if user == nil {
+		return nil, apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	if expectedVersion == 0 {
+		expectedVersion = user.Version
+	}
+	if user.Version != expectedVersion {
+		return nil, apperrors.ConflictError("user.version_conflict", nil, map[string]any{
+			"current_version": user.Version,
+		})
+	}
+
+	if patch.Email != nil && *patch.Email != user.Email {
+		existing, err := s.repo.FindByEmail(ctx, *patch.Email)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to check email uniqueness")
+			return nil, apperrors.InternalServerError("user.update_error", err, nil)
+		}
+		if existing != nil {
+			return nil, apperrors.ConflictError("user.email_exists", nil, map[string]any{"email": *patch.Email})
+		}
+	}
+
+	patch.Apply(user)
+
+	updated, err := s.repo.UpdateProfile(ctx, user, expectedVersion)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to update user profile")
+		return nil, apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if !updated {
+		// Запись изменили между чтением и записью
+		return nil, apperrors.ConflictError("user.version_conflict", nil, nil)
+	}
+
+	return model.UserDTOFromDBModel(user), nil
 }
 
 // Delete deletes an existing user
 func (s *userService) delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to delete user")
+		return apperrors.InternalServerError("user.delete_error", err, nil)
+	}
+	if !deleted {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+	return nil
 }
 
+// Delete помечает пользователя удалённым и отзывает все его сессии
 func (s *userService) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := s.GetUserOrFail(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return s.delete(ctx, id)
+	return s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.sessions.RevokeAllUserTokens(ctx, id, "")
+	})
+}
+
+// Restore восстанавливает удалённого пользователя в пределах срока хранения
+func (s *userService) Restore(ctx context.Context, id uuid.UUID) error {
+	user, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to get deleted user")
+		return apperrors.InternalServerError("user.restore_error", err, nil)
+	}
+	if user == nil {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	// Email удалённого пользователя мог быть занят заново
+	existing, err := s.repo.FindByEmail(ctx, user.Email)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to check email uniqueness")
+		return apperrors.InternalServerError("user.restore_error", err, nil)
+	}
+	if existing != nil {
+		return apperrors.ConflictError("user.email_exists", nil, map[string]any{"email": user.Email})
+	}
+
+	retentionSeconds := s.config.RestoreRetentionDays() * 24 * 60 * 60
+	restored, err := s.repo.Restore(ctx, id, retentionSeconds)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to restore user")
+		return apperrors.InternalServerError("user.restore_error", err, nil)
+	}
+	if !restored {
+		return apperrors.ConflictError("user.restore_expired", nil, map[string]any{
+			"retention_days": s.config.RestoreRetentionDays(),
+		})
+	}
+
+	s.logger.WithField("user_id", id).Info("User restored")
+	return nil
+}
+
+// Purge окончательно удаляет ранее удалённого пользователя вместе с сессиями, ролями и учётными данными
+func (s *userService) Purge(ctx context.Context, id uuid.UUID) error {
+	user, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to get deleted user")
+		return apperrors.InternalServerError("user.delete_error", err, nil)
+	}
+	if user == nil {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	avatarKey, purged, err := s.repo.Purge(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", id).Error("Failed to purge user")
+		return apperrors.InternalServerError("user.delete_error", err, nil)
+	}
+	if !purged {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": id})
+	}
+
+	s.removeAvatarFiles(ctx, avatarKey)
+
+	s.logger.WithField("user_id", id).Warn("User purged")
+	return nil
+}
+
+// GetAccessAttributes возвращает атрибуты пользователя для проверки политик без загрузки ролей и разрешений
+func (s *userService) GetAccessAttributes(ctx context.Context, id uuid.UUID) (corepolicy.Attributes, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	return model.UserAttributes(user.ID, user.DataRole, user.OrganizationID), nil
+}
+
+// GetDeletedAccessAttributes возвращает атрибуты удалённого пользователя для проверки политик
+// восстановления и окончательного удаления
+func (s *userService) GetDeletedAccessAttributes(ctx context.Context, id uuid.UUID) (corepolicy.Attributes, error) {
+	user, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	return model.UserAttributes(user.ID, user.DataRole, user.OrganizationID), nil
 }
 
 // GetByID gets a user by ID
@@ -359,6 +579,13 @@ func (s *userService) ValidateCredentials(ctx context.Context, email, password s
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return nil, apperrors.UnauthorizedError("errors.invalid_credentials", err, nil)
 	}
+	if !user.Active {
+		return nil, apperrors.ForbiddenError("user.inactive", nil, nil)
+	}
+	// Причина и срок блокировки видны только администраторам в истории блокировок
+	if user.Suspended {
+		return nil, apperrors.ForbiddenError("user.suspended", nil, nil)
+	}
 
 	roles, err := s.repo.GetUserRoles(ctx, user.ID)
 	if err != nil {
@@ -373,71 +600,504 @@ func (s *userService) ValidateCredentials(ctx context.Context, email, password s
 	return model.FromDBModel(user, roles, permissions), nil
 }
 
-// ListUsers возвращает список всех пользователей без ролей и разрешений
-func (s *userService) ListUsers(ctx context.Context) ([]*model.UserDTO, error) {
-	s.logger.WithField("component", "UserService.ListUsersLight").Debug("Getting all users")
+// ListUsers возвращает страницу пользователей без ролей и разрешений.
+// Поддерживает постраничный режим (page/limit) и keyset-пагинацию по курсору
+func (s *userService) ListUsers(ctx context.Context, query *model.ListUsersQuery) (*model.UserList, error) {
+	s.logger.WithField("component", "UserService.ListUsers").Debug("Getting users page")
+
+	filter := &model.UserFilter{
+		Active:        query.Active,
+		EmailVerified: query.EmailVerified,
+		Role:          query.Role,
+		Permission:    query.Permission,
+		DataRole:      query.DataRole,
+		Deleted:       query.Deleted,
+		Sort:          query.Sort,
+		Order:         query.Order,
+		Limit:         query.Limit,
+		Scope:         query.Scope,
+	}
+	if filter.Deleted == "" {
+		filter.Deleted = model.DeletedExclude
+	}
+	if filter.Sort == "" {
+		filter.Sort = model.DefaultUserSort
+	}
+	if filter.Order == "" {
+		filter.Order = model.SortOrderDesc
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = model.DefaultUserListLimit
+	}
+	if filter.Limit > model.MaxUserListLimit {
+		filter.Limit = model.MaxUserListLimit
+	}
+	if query.CreatedFrom != nil {
+		from := query.CreatedFrom.UTC()
+		filter.CreatedFrom = &from
+	}
+	if query.CreatedTo != nil {
+		to := query.CreatedTo.UTC()
+		filter.CreatedTo = &to
+	}
+
+	page := 0
+	if query.Cursor != "" {
+		cursor, err := model.DecodeUserCursor(query.Cursor)
+		if err != nil {
+			return nil, apperrors.BadRequestError("user.invalid_cursor", err, nil)
+		}
+		// Курсор действителен только для той же сортировки, в которой был выдан
+		if cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+			return nil, apperrors.BadRequestError("user.invalid_cursor", model.ErrInvalidCursor, map[string]any{
+				"sort":  cursor.Sort,
+				"order": cursor.Order,
+			})
+		}
+		filter.After = cursor
+	} else {
+		page = query.Page
+		if page <= 0 {
+			page = 1
+		}
+		filter.Offset = (page - 1) * filter.Limit
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to count users")
+		return nil, apperrors.InternalServerError("user.list_error", err, nil)
+	}
 
-	// Получаем список пользователей из репозитория
-	users, err := s.repo.FindAll(ctx)
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	users, err := s.repo.FindPage(ctx, filter)
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidCursor) {
+			return nil, apperrors.BadRequestError("user.invalid_cursor", err, nil)
+		}
 		s.logger.WithError(err).Error("Failed to get users from repository")
-		return nil, err
+		return nil, apperrors.InternalServerError("user.list_error", err, nil)
+	}
+
+	result := &model.UserList{
+		Items: make([]*model.UserDTO, 0, len(users)),
+		Total: total,
+		Page:  page,
+		Limit: limit,
 	}
 
-	// Создаем слайс для моделей пользователей без ролей и разрешений
-	result := make([]*model.UserDTO, 0, len(users))
+	if len(users) > limit {
+		users = users[:limit]
+		last := users[len(users)-1]
+		next := &model.UserCursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: last.SortValue(filter.Sort),
+			ID:    last.ID,
+		}
+		result.NextCursor = next.Encode()
+	}
 
-	// Преобразуем каждую модель из БД в DTO
 	for _, dbUser := range users {
-		// Преобразуем в DTO и добавляем в результат
-		user := model.UserDTOFromDBModel(dbUser)
-		result = append(result, user)
+		result.Items = append(result.Items, model.UserDTOFromDBModel(dbUser))
 	}
 
 	return result, nil
 }
 
-// AssignRole assigns a role to a user
+// maxSearchTerms ограничивает число слов поискового запроса
+const maxSearchTerms = 8
+
+// SearchUsers ищет активных (не удалённых) пользователей по ФИО, email, телефону и должности.
+// Совпадения ранжируются по полнотекстовой релевантности и триграммному сходству
+func (s *userService) SearchUsers(ctx context.Context, query *model.SearchUsersQuery) (*model.UserSearchResult, error) {
+	text := strings.ToLower(strings.TrimSpace(query.Q))
+
+	filter := &model.UserSearchFilter{
+		Terms: searchTerms(text),
+		Text:  text,
+		Limit: query.Limit,
+		Scope: query.Scope,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = model.DefaultUserListLimit
+	}
+	if filter.Limit > model.MaxUserListLimit {
+		filter.Limit = model.MaxUserListLimit
+	}
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	result := &model.UserSearchResult{
+		Items: make([]*model.UserSearchHit, 0),
+		Page:  page,
+		Limit: filter.Limit,
+	}
+
+	// Запрос без букв и цифр ничего не найдёт
+	if len(filter.Terms) == 0 {
+		return result, nil
+	}
+
+	total, err := s.repo.CountSearch(ctx, filter)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to count user search results")
+		return nil, apperrors.InternalServerError("user.search_error", err, nil)
+	}
+	result.Total = total
+
+	if total == 0 {
+		return result, nil
+	}
+
+	rows, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to search users")
+		return nil, apperrors.InternalServerError("user.search_error", err, nil)
+	}
+
+	for _, row := range rows {
+		result.Items = append(result.Items, &model.UserSearchHit{
+			UserDTO:   model.UserDTOFromDBModel(&row.UserModel),
+			Rank:      row.Rank,
+			Highlight: row.Highlight,
+		})
+	}
+
+	return result, nil
+}
+
+// searchTerms разбивает запрос на слова из букв и цифр без повторов
+func searchTerms(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if slices.Contains(terms, field) {
+			continue
+		}
+		terms = append(terms, field)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+
+	return terms
+}
+
+// AssignRole assigns a role to a user. A role of an organization can be assigned
+// only to users of the same organization
 func (s *userService) AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error {
-	return s.repo.AssignRole(ctx, userID, roleID)
+	user, err := s.GetUserOrFail(ctx, userID)
+	if err != nil {
+		return err
+	}
+	role, err := s.findRoleOrFail(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if !role.AvailableTo(user.OrganizationID) {
+		return apperrors.ForbiddenError("role.foreign_organization", nil, map[string]any{"id": roleID})
+	}
+
+	assigned, err := s.repo.AssignRole(ctx, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+	return nil
 }
 
 // RemoveRole removes a role from a user
 func (s *userService) RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) error {
-	return s.repo.RemoveRole(ctx, userID, roleID)
+	if _, err := s.GetUserOrFail(ctx, userID); err != nil {
+		return err
+	}
+
+	found, err := s.repo.RemoveRole(ctx, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+	return nil
 }
 
-// CreateRole creates a new role
+// CreateRole creates a new role in the organization of the tenant; a platform-level caller creates a global role
 func (s *userService) CreateRole(ctx context.Context, name, description string) (*model.Role, error) {
+	existing, err := s.repo.FindRoleByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.ConflictError("role.name_exists", nil, map[string]any{"name": name})
+	}
+
 	role := &model.RoleModel{
-		RoleName:    name,
-		Description: description,
+		RoleName:       name,
+		Description:    description,
+		OrganizationID: tenant.OrganizationID(ctx),
 	}
 
 	if err := s.repo.CreateRole(ctx, role); err != nil {
 		return nil, err
 	}
 
+	return &model.Role{
+		ID:             role.ID,
+		Name:           role.RoleName,
+		Description:    role.Description,
+		Permissions:    []model.Permission{},
+		OrganizationID: role.OrganizationID,
+	}, nil
+}
+
+// GetRole возвращает роль вместе с её разрешениями
+func (s *userService) GetRole(ctx context.Context, roleID int) (*model.Role, error) {
+	role, err := s.findRoleOrFail(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.repo.GetRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []model.Permission{}
+	}
+
+	parentIDs, err := s.repo.GetRoleParentIDs(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.Role{
 		ID:          role.ID,
 		Name:        role.RoleName,
 		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		ParentIDs:   parentIDs,
+
+		OrganizationID: role.OrganizationID,
 	}, nil
 }
 
-// UpdateRole updates an existing role
-func (s *userService) UpdateRole(ctx context.Context, role *model.Role) error {
-	dbRole := &model.RoleModel{
-		ID:          role.ID,
-		RoleName:    role.Name,
-		Description: role.Description,
+// SetRoleParents заменяет родительские роли. Изменение отклоняется, если новая связь
+// образует цикл: роль не может наследовать саму себя ни напрямую, ни через другие роли.
+// Родителем может быть глобальная роль или роль той же организации
+func (s *userService) SetRoleParents(ctx context.Context, roleID int, parentIDs []int) (*model.Role, error) {
+	role, err := s.findMutableRoleOrFail(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	allRoles, err := s.repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int]model.Role, len(allRoles))
+	for _, r := range allRoles {
+		existing[r.ID] = r
+	}
+
+	unique := make([]int, 0, len(parentIDs))
+	var invalid, foreign []int
+	for _, id := range parentIDs {
+		if slices.Contains(unique, id) || slices.Contains(invalid, id) || slices.Contains(foreign, id) {
+			continue
+		}
+		parent, ok := existing[id]
+		if !ok {
+			invalid = append(invalid, id)
+			continue
+		}
+		if !parent.AvailableTo(role.OrganizationID) {
+			foreign = append(foreign, id)
+			continue
+		}
+		unique = append(unique, id)
+	}
+	if len(invalid) > 0 {
+		return nil, apperrors.NotFoundError("role.not_found", nil, map[string]any{"invalid_ids": invalid})
+	}
+	if len(foreign) > 0 {
+		return nil, apperrors.ForbiddenError("role.foreign_organization", nil, map[string]any{"invalid_ids": foreign})
+	}
+
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockRoleHierarchy(ctx); err != nil {
+			return err
+		}
+
+		if len(unique) > 0 {
+			cycle, err := s.repo.IsRoleAncestorOf(ctx, roleID, unique)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return apperrors.ConflictError("role.hierarchy_cycle", nil, map[string]any{
+					"role_id":    roleID,
+					"parent_ids": unique,
+				})
+			}
+		}
+
+		return s.repo.SetRoleParents(ctx, roleID, unique)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRole(ctx, roleID)
+}
+
+// UpdateRole изменяет название и описание роли. Системные роли переименовывать нельзя:
+// на их названия могут ссылаться миграции и конфигурация
+func (s *userService) UpdateRole(ctx context.Context, roleID int, req *model.UpdateRoleRequest) (*model.Role, error) {
+	role, err := s.findMutableRoleOrFail(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != role.RoleName {
+		if role.IsSystem {
+			return nil, apperrors.ForbiddenError("role.system_role", nil, map[string]any{"name": role.RoleName})
+		}
+
+		existing, err := s.repo.FindRoleByName(ctx, *req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, apperrors.ConflictError("role.name_exists", nil, map[string]any{"name": *req.Name})
+		}
+		role.RoleName = *req.Name
 	}
-	return s.repo.UpdateRole(ctx, dbRole)
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return s.GetRole(ctx, roleID)
 }
 
-// DeleteRole deletes an existing role
-func (s *userService) DeleteRole(ctx context.Context, roleID int) error {
-	return s.repo.DeleteRole(ctx, roleID)
+// DeleteRole удаляет роль. Системные роли не удаляются; назначенная пользователям роль
+// удаляется только при force, назначения при этом снимаются каскадно
+func (s *userService) DeleteRole(ctx context.Context, roleID int, force bool) error {
+	role, err := s.findMutableRoleOrFail(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return apperrors.ForbiddenError("role.system_role", nil, map[string]any{"name": role.RoleName})
+	}
+
+	members, err := s.repo.CountRoleMembers(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if members > 0 && !force {
+		return apperrors.ConflictError("role.in_use", nil, map[string]any{
+			"name":    role.RoleName,
+			"members": members,
+		})
+	}
+
+	if err := s.repo.DeleteRole(ctx, roleID); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"role_id": roleID,
+		"members": members,
+	}).Info("Role deleted")
+	return nil
+}
+
+// ListRoleMembers возвращает страницу пользователей, которым назначена роль
+func (s *userService) ListRoleMembers(ctx context.Context, roleID int, query *model.ListUsersQuery) (*model.UserList, error) {
+	role, err := s.findRoleOrFail(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	query.Role = role.RoleName
+	return s.ListUsers(ctx, query)
+}
+
+func (s *userService) findRoleOrFail(ctx context.Context, roleID int) (*model.RoleModel, error) {
+	role, err := s.repo.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, apperrors.NotFoundError("role.not_found", nil, map[string]any{"id": roleID})
+	}
+	return role, nil
+}
+
+// findMutableRoleOrFail возвращает роль, которую арендатор может изменять. Глобальные роли
+// общие для всех организаций, поэтому изменять их может только уровень платформы
+func (s *userService) findMutableRoleOrFail(ctx context.Context, roleID int) (*model.RoleModel, error) {
+	role, err := s.findRoleOrFail(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.OrganizationID == nil && !tenant.FromContext(ctx).ManagesShared() {
+		return nil, apperrors.ForbiddenError("role.global_role", nil, map[string]any{"name": role.RoleName})
+	}
+	return role, nil
+}
+
+// assignableRoles возвращает идентификаторы ролей по названию, доступных пользователю организации.
+// При совпадении названий глобальная роль имеет приоритет, как и в FindRoleByName
+func assignableRoles(roles []model.Role, organizationID *uuid.UUID) map[string]int {
+	result := make(map[string]int, len(roles))
+	for _, role := range roles {
+		if !role.AvailableTo(organizationID) {
+			continue
+		}
+		if _, ok := result[role.Name]; ok && role.OrganizationID != nil {
+			continue
+		}
+		result[role.Name] = role.ID
+	}
+	return result
+}
+
+// newUserOrganization определяет организацию создаваемого пользователя. Арендатор создаёт
+// пользователей в своей организации; суперадминистратор без выбранной организации может указать её в запросе
+func (s *userService) newUserOrganization(ctx context.Context, requested *uuid.UUID) (*uuid.UUID, error) {
+	t := tenant.FromContext(ctx)
+	if !t.CrossTenant() {
+		if requested != nil && !t.Allows(requested) {
+			return nil, apperrors.ForbiddenError("tenant.access_denied", nil, map[string]any{"organization_id": requested.String()})
+		}
+		return t.OrganizationID, nil
+	}
+	if requested == nil {
+		return nil, nil
+	}
+
+	if _, err := s.findOrganizationOrFail(ctx, *requested); err != nil {
+		return nil, err
+	}
+	return requested, nil
 }
 
 // GetAllRoles gets all roles
@@ -447,7 +1107,14 @@ func (s *userService) GetAllRoles(ctx context.Context) ([]model.Role, error) {
 
 // AssignPermission Permission management
 func (s *userService) assignPermission(ctx context.Context, userID uuid.UUID, permissionID int) error {
-	return s.repo.AssignPermission(ctx, userID, permissionID)
+	assigned, err := s.repo.AssignPermission(ctx, userID, permissionID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+	return nil
 }
 
 func (s *userService) AssignPermission(ctx context.Context, userID uuid.UUID, permissionID int) error {
@@ -455,16 +1122,48 @@ func (s *userService) AssignPermission(ctx context.Context, userID uuid.UUID, pe
 	if err != nil {
 		return err
 	}
+
+	permission, err := s.findPermissionOrFail(ctx, permissionID)
+	if err != nil {
+		return err
+	}
+	if err := ensurePermissionsHeld(ctx, permission.PermissionName); err != nil {
+		return err
+	}
+
 	return s.assignPermission(ctx, userID, permissionID)
 }
 
 // RemovePermission Permission management
 func (s *userService) RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) error {
-	return s.repo.RemovePermission(ctx, userID, permissionID)
+	if _, err := s.GetUserOrFail(ctx, userID); err != nil {
+		return err
+	}
+
+	found, err := s.repo.RemovePermission(ctx, userID, permissionID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+	return nil
 }
 
 // CreatePermission Permission management
 func (s *userService) CreatePermission(ctx context.Context, name, description string) (*model.Permission, error) {
+	if err := validatePermissionName(name); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindPermissionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.ConflictError("permission.name_exists", nil, map[string]any{"name": name})
+	}
+
 	permission := &model.PermissionModel{
 		PermissionName: name,
 		Description:    description,
@@ -481,11 +1180,256 @@ func (s *userService) CreatePermission(ctx context.Context, name, description st
 	}, nil
 }
 
+// validatePermissionName проверяет, что название разрешения соответствует грамматике
+// сегментов, иначе шаблоны вида "users:*" совпадали бы непредсказуемо
+func validatePermissionName(name string) error {
+	if !permission.Valid(name) {
+		return apperrors.ValidationError("permission.invalid_name", nil, map[string]any{"name": name})
+	}
+	return nil
+}
+
 // GetAllPermissions Permission management
 func (s *userService) GetAllPermissions(ctx context.Context) ([]model.Permission, error) {
 	return s.repo.GetAllPermissions(ctx)
 }
 
+// GetPermission возвращает разрешение из каталога
+func (s *userService) GetPermission(ctx context.Context, permissionID int) (*model.Permission, error) {
+	permission, err := s.findPermissionOrFail(ctx, permissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Permission{
+		ID:          permission.ID,
+		Name:        permission.PermissionName,
+		Description: permission.Description,
+	}, nil
+}
+
+// UpdatePermission изменяет название и описание разрешения
+func (s *userService) UpdatePermission(ctx context.Context, permissionID int, req *model.UpdatePermissionRequest) (*model.Permission, error) {
+	permission, err := s.findPermissionOrFail(ctx, permissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != permission.PermissionName {
+		if err := validatePermissionName(*req.Name); err != nil {
+			return nil, err
+		}
+
+		existing, err := s.repo.FindPermissionByName(ctx, *req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, apperrors.ConflictError("permission.name_exists", nil, map[string]any{"name": *req.Name})
+		}
+		permission.PermissionName = *req.Name
+	}
+	if req.Description != nil {
+		permission.Description = *req.Description
+	}
+
+	if err := s.repo.UpdatePermission(ctx, permission); err != nil {
+		return nil, err
+	}
+
+	return s.GetPermission(ctx, permissionID)
+}
+
+// DeletePermission удаляет разрешение. Назначенное ролям или пользователям разрешение
+// удаляется только при force, назначения при этом снимаются каскадно
+func (s *userService) DeletePermission(ctx context.Context, permissionID int, force bool) error {
+	permission, err := s.findPermissionOrFail(ctx, permissionID)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := s.repo.CountPermissionAssignments(ctx, permissionID)
+	if err != nil {
+		return err
+	}
+	if assignments > 0 && !force {
+		return apperrors.ConflictError("permission.in_use", nil, map[string]any{
+			"name":        permission.PermissionName,
+			"assignments": assignments,
+		})
+	}
+
+	if err := s.repo.DeletePermission(ctx, permissionID); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"permission_id": permissionID,
+		"assignments":   assignments,
+	}).Info("Permission deleted")
+	return nil
+}
+
+// GetRolePermissions возвращает разрешения роли
+func (s *userService) GetRolePermissions(ctx context.Context, roleID int) ([]model.Permission, error) {
+	if _, err := s.findRoleOrFail(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.repo.GetRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []model.Permission{}
+	}
+	return permissions, nil
+}
+
+// GetRoleEffectivePermissions возвращает разрешения роли вместе с унаследованными
+func (s *userService) GetRoleEffectivePermissions(ctx context.Context, roleID int) ([]model.Permission, error) {
+	if _, err := s.findRoleOrFail(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.repo.GetRoleEffectivePermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []model.Permission{}
+	}
+	return permissions, nil
+}
+
+// SetRolePermissions заменяет набор разрешений роли целиком в одной транзакции
+func (s *userService) SetRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error) {
+	permissionIDs, err := s.prepareRolePermissionChange(ctx, roleID, permissionIDs, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.repo.RemoveOtherRolePermissions(ctx, roleID, permissionIDs); err != nil {
+			return err
+		}
+		return s.repo.AddRolePermissions(ctx, roleID, permissionIDs)
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("role_id", roleID).Error("Failed to replace role permissions")
+		return nil, err
+	}
+
+	return s.GetRolePermissions(ctx, roleID)
+}
+
+// AddRolePermissions добавляет разрешения роли; уже назначенные пропускаются
+func (s *userService) AddRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error) {
+	permissionIDs, err := s.prepareRolePermissionChange(ctx, roleID, permissionIDs, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddRolePermissions(ctx, roleID, permissionIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetRolePermissions(ctx, roleID)
+}
+
+// RemoveRolePermissions снимает разрешения с роли; не назначенные пропускаются
+func (s *userService) RemoveRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error) {
+	permissionIDs, err := s.prepareRolePermissionChange(ctx, roleID, permissionIDs, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveRolePermissions(ctx, roleID, permissionIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetRolePermissions(ctx, roleID)
+}
+
+// prepareRolePermissionChange проверяет роль и разрешения и убирает повторяющиеся ID.
+// Пустой список допустим только при полной замене набора. При выдаче (grant) разрешения,
+// которых у роли ещё нет, должны быть у субъекта запроса
+func (s *userService) prepareRolePermissionChange(ctx context.Context, roleID int, permissionIDs []int, allowEmpty, grant bool) ([]int, error) {
+	if len(permissionIDs) == 0 && !allowEmpty {
+		return nil, apperrors.BadRequestError("errors.invalid_input", nil, map[string]any{
+			"message": "список разрешений не может быть пустым",
+		})
+	}
+
+	if _, err := s.findMutableRoleOrFail(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	allPermissions, err := s.repo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int]string, len(allPermissions))
+	for _, permission := range allPermissions {
+		existing[permission.ID] = permission.Name
+	}
+
+	unique := make([]int, 0, len(permissionIDs))
+	var invalid []int
+	for _, id := range permissionIDs {
+		if slices.Contains(unique, id) || slices.Contains(invalid, id) {
+			continue
+		}
+		if _, ok := existing[id]; !ok {
+			invalid = append(invalid, id)
+			continue
+		}
+		unique = append(unique, id)
+	}
+	if len(invalid) > 0 {
+		return nil, apperrors.NotFoundError("permission.not_found", nil, map[string]any{"invalid_ids": invalid})
+	}
+
+	if grant {
+		current, err := s.repo.GetRolePermissions(ctx, roleID)
+		if err != nil {
+			return nil, err
+		}
+
+		var granted []string
+		for _, id := range unique {
+			if !slices.ContainsFunc(current, func(p model.Permission) bool { return p.ID == id }) {
+				granted = append(granted, existing[id])
+			}
+		}
+		if err := ensurePermissionsHeld(ctx, granted...); err != nil {
+			return nil, err
+		}
+	}
+
+	return unique, nil
+}
+
+// ensurePermissionsHeld запрещает выдавать разрешения, которых нет у субъекта запроса:
+// иначе администратор ролей или пользователей мог бы расширить собственные права
+func ensurePermissionsHeld(ctx context.Context, names ...string) error {
+	if denied := corepolicy.NotHeld(ctx, names...); len(denied) > 0 {
+		return apperrors.ForbiddenError("permission.not_held", nil, map[string]any{"permissions": denied})
+	}
+	return nil
+}
+
+func (s *userService) findPermissionOrFail(ctx context.Context, permissionID int) (*model.PermissionModel, error) {
+	permission, err := s.repo.FindPermissionByID(ctx, permissionID)
+	if err != nil {
+		return nil, err
+	}
+	if permission == nil {
+		return nil, apperrors.NotFoundError("permission.not_found", nil, map[string]any{"id": permissionID})
+	}
+	return permission, nil
+}
+
 // HasRole checks if a user has a given role
 func (s *userService) HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error) {
 	roles, err := s.repo.GetUserRoles(ctx, userID)
@@ -545,17 +1489,41 @@ func (s *userService) GetUserPermissions(ctx context.Context, userID uuid.UUID)
 }
 
 // ConfirmEmail confirms a user's email
-func (s *userService) confirmEmail(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.ConfirmEmail(ctx, userID)
+func (s *userService) confirmEmail(ctx context.Context, userID uuid.UUID, expectedVersion int) error {
+	confirmed, err := s.repo.ConfirmEmail(ctx, userID, expectedVersion)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to confirm user email")
+		return apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if !confirmed {
+		return s.writeConflict(ctx, userID, expectedVersion)
+	}
+	return nil
+}
+
+// writeConflict объясняет отказ версионированной записи: пользователь не найден в организации
+// арендатора (404) или его версия отличается от ожидаемой (409)
+func (s *userService) writeConflict(ctx context.Context, userID uuid.UUID, expectedVersion int) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user after rejected update")
+		return apperrors.InternalServerError("user.update_error", err, nil)
+	}
+	if user == nil {
+		return apperrors.NotFoundError("user.not_found", nil, map[string]any{"id": userID})
+	}
+	return apperrors.ConflictError("user.version_conflict", nil, map[string]any{
+		"expected_version": expectedVersion,
+	})
 }
 
 func (s *userService) ConfirmEmail(ctx context.Context, userID uuid.UUID) error {
-	_, err := s.GetUserOrFail(ctx, userID)
+	user, err := s.GetUserOrFail(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	return s.confirmEmail(ctx, userID)
+	return s.confirmEmail(ctx, userID, user.Version)
 }
 
 // GetUserOrFail возвращает пользователя по ID или ошибку, если пользователя нет
@@ -611,6 +1579,13 @@ func (s *userService) ChangePassword(ctx context.Context, userID uuid.UUID, oldP
 		return apperrors.InternalServerError("errors.internal", err, nil)
 	}
 
-	// Сохраняем новый пароль
-	return s.repo.ChangePassword(ctx, userID, string(hashedPassword))
+	// Сохраняем новый пароль, если пользователя не изменили после проверки старого
+	changed, err := s.repo.ChangePassword(ctx, userID, string(hashedPassword), user.Version)
+	if err != nil {
+		return apperrors.InternalServerError("errors.internal", err, nil)
+	}
+	if !changed {
+		return s.writeConflict(ctx, userID, user.Version)
+	}
+	return nil
 }
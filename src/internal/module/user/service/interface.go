@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	corepolicy "github.com/xdevspo/go_tmpl_module_app/internal/core/policy"
 	"github.com/xdevspo/go_tmpl_module_app/internal/module/user/model"
+	"github.com/xdevspo/go_tmpl_module_app/pkg/tabular"
 )
 
 // UserService defines the interface for user business logic
@@ -12,21 +14,55 @@ type UserService interface {
 	// User management
 	Create(ctx context.Context, req *model.CreateUserRequest) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	Patch(ctx context.Context, id uuid.UUID, expectedVersion int, patch *model.UserPatch, allowRestricted bool) (*model.UserDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID) error
+	SetAvatar(ctx context.Context, id uuid.UUID, data []byte) (*model.UserDTO, error)
+	DeleteAvatar(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// GetAccessAttributes returns the attributes policies check for the user, or nil if the user does not exist
+	GetAccessAttributes(ctx context.Context, id uuid.UUID) (corepolicy.Attributes, error)
+	// GetDeletedAccessAttributes returns the attributes of a soft-deleted user, or nil if there is no such user
+	GetDeletedAccessAttributes(ctx context.Context, id uuid.UUID) (corepolicy.Attributes, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	ValidateCredentials(ctx context.Context, email, password string) (*model.User, error)
-	ListUsers(ctx context.Context) ([]*model.UserDTO, error)
+	ListUsers(ctx context.Context, query *model.ListUsersQuery) (*model.UserList, error)
+	SearchUsers(ctx context.Context, query *model.SearchUsersQuery) (*model.UserSearchResult, error)
+	ImportUsers(ctx context.Context, table *tabular.Table, opts *model.ImportOptions) (*model.ImportReport, error)
 	ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error
 
+	// Self-service
+	GetProfile(ctx context.Context, userID uuid.UUID) (*model.Profile, error)
+	UpdateOwnProfile(ctx context.Context, userID uuid.UUID, expectedVersion int, patch *model.ProfilePatch) (*model.Profile, error)
+	DeactivateAccount(ctx context.Context, userID uuid.UUID, ipAddress string) error
+
+	// Suspensions
+	// Suspend records a suspension and revokes all refresh tokens of the user
+	Suspend(ctx context.Context, userID uuid.UUID, suspendedBy *uuid.UUID, req *model.SuspendUserRequest, ipAddress string) (*model.SuspensionDTO, error)
+	LiftSuspension(ctx context.Context, userID uuid.UUID, liftedBy *uuid.UUID, req *model.LiftSuspensionRequest) (*model.SuspensionDTO, error)
+	ListSuspensions(ctx context.Context, userID uuid.UUID) ([]*model.SuspensionDTO, error)
+
+	// Organizations
+	// CreateOrganization creates a tenant organization; the slug is unique across the platform
+	CreateOrganization(ctx context.Context, req *model.CreateOrganizationRequest) (*model.Organization, error)
+	ListOrganizations(ctx context.Context) ([]*model.Organization, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (*model.Organization, error)
+	UpdateOrganization(ctx context.Context, id uuid.UUID, req *model.UpdateOrganizationRequest) (*model.Organization, error)
+
 	// Role management
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]model.Role, error)
 	AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error
 	RemoveRole(ctx context.Context, userID uuid.UUID, roleID int) error
 	CreateRole(ctx context.Context, name, description string) (*model.Role, error)
-	UpdateRole(ctx context.Context, role *model.Role) error
-	DeleteRole(ctx context.Context, roleID int) error
+	GetRole(ctx context.Context, roleID int) (*model.Role, error)
+	UpdateRole(ctx context.Context, roleID int, req *model.UpdateRoleRequest) (*model.Role, error)
+	// DeleteRole refuses to delete system roles, and roles assigned to users unless force is set
+	DeleteRole(ctx context.Context, roleID int, force bool) error
 	GetAllRoles(ctx context.Context) ([]model.Role, error)
+	ListRoleMembers(ctx context.Context, roleID int, query *model.ListUsersQuery) (*model.UserList, error)
+	// SetRoleParents replaces the parent roles; a change that would create a cycle is rejected
+	SetRoleParents(ctx context.Context, roleID int, parentIDs []int) (*model.Role, error)
 	GetUserOrFail(ctx context.Context, userID uuid.UUID) (*model.User, error)
 	ConfirmEmail(ctx context.Context, userID uuid.UUID) error
 
@@ -35,6 +71,19 @@ type UserService interface {
 	RemovePermission(ctx context.Context, userID uuid.UUID, permissionID int) error
 	CreatePermission(ctx context.Context, name, description string) (*model.Permission, error)
 	GetAllPermissions(ctx context.Context) ([]model.Permission, error)
+	GetPermission(ctx context.Context, permissionID int) (*model.Permission, error)
+	UpdatePermission(ctx context.Context, permissionID int, req *model.UpdatePermissionRequest) (*model.Permission, error)
+	// DeletePermission refuses to delete a permission assigned to roles or users unless force is set
+	DeletePermission(ctx context.Context, permissionID int, force bool) error
+
+	// Role permissions
+	GetRolePermissions(ctx context.Context, roleID int) ([]model.Permission, error)
+	// GetRoleEffectivePermissions returns the role permissions including those inherited from parent roles
+	GetRoleEffectivePermissions(ctx context.Context, roleID int) ([]model.Permission, error)
+	// SetRolePermissions atomically replaces the role's permission set
+	SetRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error)
+	AddRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error)
+	RemoveRolePermissions(ctx context.Context, roleID int, permissionIDs []int) ([]model.Permission, error)
 
 	// Authorization
 	HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error)
@@ -43,3 +92,18 @@ type UserService interface {
 	// User permissions
 	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]model.Permission, error)
 }
+
+// SessionRevoker revokes user sessions when the user is deleted
+type SessionRevoker interface {
+	RevokeAllUserTokens(ctx context.Context, userID uuid.UUID, ipAddress string) error
+}
+
+// ExportService defines the interface for asynchronous user exports
+type ExportService interface {
+	// CreateExport registers an export job and starts it in the background
+	CreateExport(ctx context.Context, requestedBy *uuid.UUID, req *model.CreateExportRequest) (*model.ExportJobDTO, error)
+	// GetExport returns the job status and, once completed, a signed download link
+	GetExport(ctx context.Context, id uuid.UUID) (*model.ExportJobDTO, error)
+	// OpenExport verifies the signed link and returns the completed job with its file path
+	OpenExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*model.ExportJob, string, error)
+}
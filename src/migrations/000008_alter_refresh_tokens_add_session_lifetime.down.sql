@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS session_started_at,
+    DROP COLUMN IF EXISTS session_expires_at,
+    DROP COLUMN IF EXISTS remember_me;
@@ -0,0 +1,18 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN session_started_at TIMESTAMP,
+    ADD COLUMN session_expires_at TIMESTAMP,
+    ADD COLUMN remember_me        BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Для уже выданных токенов сессия начинается в момент создания токена и заканчивается вместе с ним
+UPDATE refresh_tokens
+SET session_started_at = created_at,
+    session_expires_at = expires_at
+WHERE session_started_at IS NULL;
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN session_started_at SET NOT NULL,
+    ALTER COLUMN session_expires_at SET NOT NULL;
+
+COMMENT ON COLUMN refresh_tokens.session_started_at IS 'Время начала сессии (первого входа), сохраняется при ротации';
+COMMENT ON COLUMN refresh_tokens.session_expires_at IS 'Абсолютный срок жизни сессии, не продлевается при ротации';
+COMMENT ON COLUMN refresh_tokens.remember_me IS 'Сессия создана с опцией "запомнить меня"';
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS oauth_device_authorizations
+(
+    id               UUID PRIMARY KEY,
+    device_code_hash VARCHAR(64)  NOT NULL UNIQUE,
+    user_code        VARCHAR(16)  NOT NULL,
+    client_id        VARCHAR(255) NOT NULL,
+    scope            TEXT         NOT NULL DEFAULT '',
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    user_id          UUID REFERENCES users (id) ON DELETE CASCADE,
+    poll_interval    INTEGER      NOT NULL,
+    last_polled_at   TIMESTAMP,
+    expires_at       TIMESTAMP    NOT NULL,
+    approved_at      TIMESTAMP,
+    created_at       TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- user_code вводится вручную, поэтому уникален только среди ожидающих подтверждения запросов
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_device_authorizations_user_code_pending
+    ON oauth_device_authorizations (user_code) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_oauth_device_authorizations_expires_at ON oauth_device_authorizations (expires_at);
+
+COMMENT ON TABLE oauth_device_authorizations IS 'Запросы авторизации устройств (RFC 8628)';
+COMMENT ON COLUMN oauth_device_authorizations.device_code_hash IS 'SHA-256 от device_code, сам код не хранится';
+COMMENT ON COLUMN oauth_device_authorizations.status IS 'pending, approved, denied или consumed';
+COMMENT ON COLUMN oauth_device_authorizations.poll_interval IS 'Текущий интервал опроса в секундах, увеличивается при slow_down';
@@ -0,0 +1,13 @@
+DELETE
+FROM public.permissions
+WHERE permission_name IN (
+        'oauth-clients:full',
+        'oauth-clients:create',
+        'oauth-clients:view',
+        'oauth-clients:update',
+        'oauth-clients:delete',
+        'oauth-clients:rotate-secret'
+    );
+
+drop table if exists oauth_client_assertions;
+drop table if exists oauth_clients;
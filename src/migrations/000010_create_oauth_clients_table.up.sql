@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS oauth_clients
+(
+    id                UUID PRIMARY KEY,
+    name              VARCHAR(255) NOT NULL,
+    auth_method       VARCHAR(32)  NOT NULL,
+    secret_hash       VARCHAR(255),
+    public_key        TEXT,
+    scopes            TEXT[]       NOT NULL DEFAULT '{}',
+    active            BOOLEAN      NOT NULL DEFAULT TRUE,
+    secret_rotated_at TIMESTAMP,
+    created_at        TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT oauth_clients_auth_method_check
+        CHECK (auth_method IN ('client_secret_basic', 'client_secret_post', 'private_key_jwt'))
+);
+
+CREATE TRIGGER update_oauth_clients_updated_at
+    BEFORE UPDATE
+    ON oauth_clients
+    FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Использованные client_assertion (private_key_jwt) для защиты от повторного предъявления
+CREATE TABLE IF NOT EXISTS oauth_client_assertions
+(
+    jti        VARCHAR(255) PRIMARY KEY,
+    client_id  UUID      NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_client_assertions_expires_at ON oauth_client_assertions (expires_at);
+
+COMMENT ON TABLE oauth_clients IS 'Конфиденциальные OAuth2 клиенты (сервисные учетные записи)';
+COMMENT ON COLUMN oauth_clients.auth_method IS 'Способ аутентификации клиента на token endpoint';
+COMMENT ON COLUMN oauth_clients.secret_hash IS 'bcrypt-хеш client_secret';
+COMMENT ON COLUMN oauth_clients.public_key IS 'Публичный ключ в PEM для private_key_jwt';
+COMMENT ON COLUMN oauth_clients.scopes IS 'Разрешения, которые клиент может запросить';
+
+INSERT INTO public.permissions (permission_name, description)
+VALUES ('oauth-clients:full', 'Полные права на OAuth клиентов'),
+       ('oauth-clients:create', 'Право на создание OAuth клиентов'),
+       ('oauth-clients:view', 'Право на просмотр OAuth клиентов'),
+       ('oauth-clients:update', 'Право на изменение OAuth клиентов'),
+       ('oauth-clients:delete', 'Право на удаление OAuth клиентов'),
+       ('oauth-clients:rotate-secret', 'Право на перевыпуск секретов OAuth клиентов');
@@ -0,0 +1 @@
+drop table if exists oauth_token_exchange_rules;
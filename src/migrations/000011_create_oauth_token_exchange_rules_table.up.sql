@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS oauth_token_exchange_rules
+(
+    id         UUID PRIMARY KEY,
+    client_id  UUID         NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    audience   VARCHAR(255) NOT NULL,
+    scopes     TEXT[]       NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT oauth_token_exchange_rules_client_audience_key UNIQUE (client_id, audience)
+);
+
+CREATE TRIGGER update_oauth_token_exchange_rules_updated_at
+    BEFORE UPDATE
+    ON oauth_token_exchange_rules
+    FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE oauth_token_exchange_rules IS 'Разрешенные клиенту обмены токенов (RFC 8693)';
+COMMENT ON COLUMN oauth_token_exchange_rules.audience IS 'Сервис-получатель, для которого клиент может получить токен';
+COMMENT ON COLUMN oauth_token_exchange_rules.scopes IS 'Максимальный набор разрешений токена для этого получателя';
@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN dpop_jkt VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN refresh_tokens.dpop_jkt IS 'Отпечаток ключа DPoP, к которому привязана сессия (RFC 9449); пустая строка - сессия без привязки';
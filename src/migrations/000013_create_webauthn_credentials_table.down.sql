@@ -0,0 +1,2 @@
+drop table if exists webauthn_challenges;
+drop table if exists webauthn_credentials;
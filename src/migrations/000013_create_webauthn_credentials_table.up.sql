@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS webauthn_credentials
+(
+    id              UUID PRIMARY KEY,
+    user_id         UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    credential_id   BYTEA        NOT NULL,
+    public_key      BYTEA        NOT NULL,
+    algorithm       INTEGER      NOT NULL,
+    sign_count      BIGINT       NOT NULL DEFAULT 0,
+    transports      TEXT[]       NOT NULL DEFAULT '{}',
+    name            VARCHAR(255) NOT NULL,
+    aaguid          UUID         NOT NULL,
+    backup_eligible BOOLEAN      NOT NULL DEFAULT FALSE,
+    backup_state    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at    TIMESTAMP,
+    CONSTRAINT webauthn_credentials_credential_id_key UNIQUE (credential_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
+
+-- Выданные challenge регистрации и входа; запись удаляется при использовании
+CREATE TABLE IF NOT EXISTS webauthn_challenges
+(
+    challenge  BYTEA PRIMARY KEY,
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE,
+    ceremony   VARCHAR(16) NOT NULL,
+    expires_at TIMESTAMP   NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webauthn_challenges_ceremony_check CHECK (ceremony IN ('registration', 'authentication'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at ON webauthn_challenges (expires_at);
+
+COMMENT ON TABLE webauthn_credentials IS 'Passkey (учетные данные WebAuthn) пользователей';
+COMMENT ON COLUMN webauthn_credentials.public_key IS 'Публичный ключ в формате COSE_Key';
+COMMENT ON COLUMN webauthn_credentials.sign_count IS 'Последнее значение счетчика подписей аутентификатора';
+COMMENT ON COLUMN webauthn_credentials.backup_state IS 'Passkey синхронизируется между устройствами';
+COMMENT ON COLUMN webauthn_challenges.user_id IS 'Пользователь церемонии; пусто при входе без указания email';
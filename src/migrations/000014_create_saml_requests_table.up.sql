@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS saml_requests
+(
+    id            VARCHAR(64)  PRIMARY KEY,
+    idp_entity_id VARCHAR(512) NOT NULL,
+    remember_me   BOOLEAN      NOT NULL DEFAULT FALSE,
+    expires_at    TIMESTAMP    NOT NULL,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saml_requests_expires_at ON saml_requests (expires_at);
+
+COMMENT ON TABLE saml_requests IS 'Отправленные IdP запросы AuthnRequest, ожидающие ответа';
+COMMENT ON COLUMN saml_requests.id IS 'ID запроса; IdP возвращает его в InResponseTo';
+COMMENT ON COLUMN saml_requests.remember_me IS 'Профиль сессии, выбранный пользователем до перехода на IdP';
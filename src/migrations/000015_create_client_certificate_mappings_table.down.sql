@@ -0,0 +1 @@
+drop table if exists client_certificate_mappings;
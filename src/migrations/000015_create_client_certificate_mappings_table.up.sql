@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS client_certificate_mappings
+(
+    id             UUID PRIMARY KEY,
+    client_id      UUID          NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    identity_type  VARCHAR(16)   NOT NULL,
+    identity_value VARCHAR(1024) NOT NULL,
+    created_at     TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT client_certificate_mappings_identity_key UNIQUE (identity_type, identity_value),
+    CONSTRAINT client_certificate_mappings_identity_type_check
+        CHECK (identity_type IN ('spiffe', 'uri', 'dns', 'email', 'subject'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_certificate_mappings_client_id ON client_certificate_mappings (client_id);
+
+COMMENT ON TABLE client_certificate_mappings IS 'Сопоставление клиентских TLS-сертификатов сервисным клиентам';
+COMMENT ON COLUMN client_certificate_mappings.identity_type IS 'Поле сертификата: SPIFFE ID, URI/DNS/email SAN или subject DN';
+COMMENT ON COLUMN client_certificate_mappings.identity_value IS 'Значение поля; DNS и email в нижнем регистре';
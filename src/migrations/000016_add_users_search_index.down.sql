@@ -0,0 +1,7 @@
+drop index if exists idx_users_search_text_trgm;
+drop index if exists idx_users_search_vector;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS search_text;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,19 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', last_name || ' ' || first_name || ' ' || middle_name), 'A') ||
+        setweight(to_tsvector('simple', email), 'B') ||
+        setweight(to_tsvector('simple', phone || ' ' || position), 'C')
+    ) STORED;
+
+ALTER TABLE users
+    ADD COLUMN search_text TEXT GENERATED ALWAYS AS (
+        lower(last_name || ' ' || first_name || ' ' || middle_name || ' ' || email || ' ' || phone || ' ' || position)
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_search_text_trgm ON users USING GIN (search_text gin_trgm_ops) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN users.search_vector IS 'Полнотекстовый индекс по ФИО (вес A), email (B), телефону и должности (C)';
+COMMENT ON COLUMN users.search_text IS 'Нормализованный текст для нечёткого поиска по триграммам';
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN users.version IS 'Версия записи для оптимистичной блокировки; увеличивается при каждом изменении профиля';
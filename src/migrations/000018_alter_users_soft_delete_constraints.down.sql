@@ -0,0 +1,12 @@
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT IF EXISTS refresh_tokens_user_id_fkey;
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT refresh_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+drop index if exists idx_users_deleted_at;
+drop index if exists users_email_active_key;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email) WHERE deleted_at IS NULL;
+ALTER TABLE users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+COMMENT ON COLUMN users.email IS 'Email пользователя (уникальный)';
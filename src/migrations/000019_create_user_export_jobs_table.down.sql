@@ -0,0 +1,5 @@
+DELETE
+FROM permissions
+WHERE permission_name = 'users:export';
+
+drop table if exists user_export_jobs;
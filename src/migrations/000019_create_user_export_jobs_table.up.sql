@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS user_export_jobs
+(
+    id           UUID PRIMARY KEY,
+    requested_by UUID         REFERENCES users (id) ON DELETE SET NULL,
+    format       VARCHAR(16)  NOT NULL,
+    filters      JSONB        NOT NULL DEFAULT '{}',
+    status       VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    file_path    VARCHAR(1024) NOT NULL DEFAULT '',
+    row_count    INTEGER      NOT NULL DEFAULT 0,
+    error        TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at   TIMESTAMP,
+    finished_at  TIMESTAMP,
+    expires_at   TIMESTAMP,
+    CONSTRAINT user_export_jobs_format_check CHECK (format IN ('csv', 'jsonl', 'xlsx')),
+    CONSTRAINT user_export_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_export_jobs_expires_at ON user_export_jobs (expires_at) WHERE expires_at IS NOT NULL;
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('users:export', 'Право на выгрузку пользователей')
+ON CONFLICT (permission_name) DO NOTHING;
+
+COMMENT ON TABLE user_export_jobs IS 'Задания асинхронной выгрузки пользователей';
+COMMENT ON COLUMN user_export_jobs.requested_by IS 'Пользователь, создавший задание; NULL - сервисный клиент';
+COMMENT ON COLUMN user_export_jobs.filters IS 'Фильтры выборки пользователей';
+COMMENT ON COLUMN user_export_jobs.file_path IS 'Имя файла выгрузки в каталоге EXPORT_DIR';
+COMMENT ON COLUMN user_export_jobs.expires_at IS 'Время, после которого файл выгрузки удаляется';
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN avatar_key VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN users.avatar_key IS 'Префикс ключей файлов аватара в хранилище; пустая строка - аватар не загружен';
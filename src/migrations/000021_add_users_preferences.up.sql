@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN preferences JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN users.preferences IS 'Пользовательские настройки (язык, часовой пояс, тема, уведомления)';
@@ -0,0 +1,5 @@
+DELETE
+FROM permissions
+WHERE permission_name = 'users:suspend';
+
+drop table if exists user_suspensions;
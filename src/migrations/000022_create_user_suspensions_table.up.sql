@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS user_suspensions
+(
+    id           UUID PRIMARY KEY,
+    user_id      UUID      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reason       TEXT      NOT NULL,
+    suspended_by UUID      REFERENCES users (id) ON DELETE SET NULL,
+    starts_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at      TIMESTAMP,
+    lifted_at    TIMESTAMP,
+    lifted_by    UUID      REFERENCES users (id) ON DELETE SET NULL,
+    lift_reason  TEXT      NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT user_suspensions_period_check CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_suspensions_user_id ON user_suspensions (user_id, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_suspensions_not_lifted ON user_suspensions (user_id) WHERE lifted_at IS NULL;
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('users:suspend', 'Право на блокировку пользователей и просмотр истории блокировок')
+ON CONFLICT (permission_name) DO NOTHING;
+
+COMMENT ON TABLE user_suspensions IS 'Блокировки пользователей';
+COMMENT ON COLUMN user_suspensions.suspended_by IS 'Пользователь, заблокировавший учётную запись; NULL - сервисный клиент';
+COMMENT ON COLUMN user_suspensions.ends_at IS 'Время автоматического снятия блокировки; NULL - бессрочно';
+COMMENT ON COLUMN user_suspensions.lifted_at IS 'Время досрочного снятия блокировки';
+COMMENT ON COLUMN user_suspensions.lifted_by IS 'Пользователь, снявший блокировку';
@@ -0,0 +1,6 @@
+DELETE
+FROM permissions
+WHERE permission_name = 'roles:update';
+
+ALTER TABLE roles
+    DROP COLUMN IF EXISTS is_system;
@@ -0,0 +1,12 @@
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles
+SET is_system = TRUE
+WHERE role_name = 'admin';
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('roles:update', 'Право на изменение ролей')
+ON CONFLICT (permission_name) DO NOTHING;
+
+COMMENT ON COLUMN roles.is_system IS 'Системная роль: не удаляется и не переименовывается через API';
@@ -0,0 +1,7 @@
+DELETE
+FROM permissions
+WHERE permission_name IN (
+                          'permissions:update',
+                          'permissions:assign',
+                          'permissions:revoke'
+    );
@@ -0,0 +1,5 @@
+INSERT INTO permissions (permission_name, description)
+VALUES ('permissions:update', 'Право на изменение разрешений'),
+       ('permissions:assign', 'Право на добавление разрешений ролям'),
+       ('permissions:revoke', 'Право на отзыв разрешений у ролей')
+ON CONFLICT (permission_name) DO NOTHING;
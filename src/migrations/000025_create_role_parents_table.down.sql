@@ -0,0 +1,3 @@
+drop view if exists role_effective_permissions;
+drop view if exists role_closure;
+drop table if exists role_parents;
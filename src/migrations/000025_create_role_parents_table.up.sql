@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS role_parents
+(
+    role_id        INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    parent_role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, parent_role_id),
+    CONSTRAINT role_parents_not_self_check CHECK (role_id <> parent_role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_parents_parent_role_id ON role_parents (parent_role_id);
+
+-- Транзитивное замыкание иерархии: каждая роль связана с собой и со всеми предками.
+-- UNION отбрасывает повторы, поэтому рекурсия завершается даже при цикле в данных
+CREATE OR REPLACE VIEW role_closure AS
+WITH RECURSIVE closure (role_id, ancestor_id) AS (
+    SELECT id, id
+    FROM roles
+    UNION
+    SELECT c.role_id, rp.parent_role_id
+    FROM closure c
+             JOIN role_parents rp ON rp.role_id = c.ancestor_id
+)
+SELECT role_id, ancestor_id
+FROM closure;
+
+CREATE OR REPLACE VIEW role_effective_permissions AS
+SELECT DISTINCT rc.role_id, rp.permission_id
+FROM role_closure rc
+         JOIN role_permissions rp ON rp.role_id = rc.ancestor_id;
+
+COMMENT ON TABLE role_parents IS 'Наследование ролей: роль получает разрешения родительских ролей';
+COMMENT ON VIEW role_closure IS 'Роль и все её предки по иерархии role_parents';
+COMMENT ON VIEW role_effective_permissions IS 'Разрешения роли с учётом унаследованных от родительских ролей';
@@ -0,0 +1,44 @@
+UPDATE oauth_token_exchange_rules
+SET scopes = ARRAY(
+        SELECT DISTINCT regexp_replace(regexp_replace(scope, '^\*$', 'full'), ':\*$', ':full')
+        FROM unnest(scopes) scope
+             );
+
+UPDATE oauth_clients
+SET scopes = ARRAY(
+        SELECT DISTINCT regexp_replace(regexp_replace(scope, '^\*$', 'full'), ':\*$', ':full')
+        FROM unnest(scopes) scope
+             );
+
+UPDATE permissions
+SET permission_name = regexp_replace(permission_name, ':\*$', ':full')
+WHERE permission_name LIKE '%:*';
+
+UPDATE permissions
+SET permission_name = 'full'
+WHERE permission_name = '*';
+
+-- admin восстанавливается у всех, у кого есть full
+INSERT INTO permissions (permission_name, description)
+VALUES ('admin', 'Административный доступ ко всей системе')
+ON CONFLICT (permission_name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, admin.id
+FROM role_permissions rp
+         JOIN permissions p ON p.id = rp.permission_id
+         CROSS JOIN (SELECT id FROM permissions WHERE permission_name = 'admin') admin
+WHERE p.permission_name = 'full'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_permissions (user_id, permission_id)
+SELECT up.user_id, admin.id
+FROM user_permissions up
+         JOIN permissions p ON p.id = up.permission_id
+         CROSS JOIN (SELECT id FROM permissions WHERE permission_name = 'admin') admin
+WHERE p.permission_name = 'full'
+ON CONFLICT DO NOTHING;
+
+DELETE
+FROM permissions
+WHERE permission_name IN ('users:update-restricted', 'users:purge');
@@ -0,0 +1,43 @@
+INSERT INTO permissions (permission_name, description)
+VALUES ('*', 'Полный доступ ко всем ресурсам'),
+       ('users:update-restricted', 'Право на изменение активности и data_role пользователей'),
+       ('users:purge', 'Право на безвозвратное удаление пользователей')
+ON CONFLICT (permission_name) DO NOTHING;
+
+-- Назначения full и admin переносятся на '*'
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, wildcard.id
+FROM role_permissions rp
+         JOIN permissions p ON p.id = rp.permission_id
+         CROSS JOIN (SELECT id FROM permissions WHERE permission_name = '*') wildcard
+WHERE p.permission_name IN ('full', 'admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_permissions (user_id, permission_id)
+SELECT up.user_id, wildcard.id
+FROM user_permissions up
+         JOIN permissions p ON p.id = up.permission_id
+         CROSS JOIN (SELECT id FROM permissions WHERE permission_name = '*') wildcard
+WHERE p.permission_name IN ('full', 'admin')
+ON CONFLICT DO NOTHING;
+
+DELETE
+FROM permissions
+WHERE permission_name IN ('full', 'admin');
+
+-- <ресурс>:full становится <ресурс>:*
+UPDATE permissions
+SET permission_name = regexp_replace(permission_name, ':full$', ':*')
+WHERE permission_name LIKE '%:full';
+
+UPDATE oauth_clients
+SET scopes = ARRAY(
+        SELECT DISTINCT regexp_replace(regexp_replace(scope, '^(full|admin)$', '*'), ':full$', ':*')
+        FROM unnest(scopes) scope
+             );
+
+UPDATE oauth_token_exchange_rules
+SET scopes = ARRAY(
+        SELECT DISTINCT regexp_replace(regexp_replace(scope, '^(full|admin)$', '*'), ':full$', ':*')
+        FROM unnest(scopes) scope
+             );
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_data_role;
+
+DELETE
+FROM permissions
+WHERE permission_name IN (
+                          'users:view:data-role',
+                          'users:update:data-role',
+                          'users:export:data-role'
+    );
@@ -0,0 +1,8 @@
+INSERT INTO permissions (permission_name, description)
+VALUES ('users:view:data-role', 'Право на просмотр пользователей со своим data_role'),
+       ('users:update:data-role', 'Право на изменение пользователей со своим data_role'),
+       ('users:export:data-role', 'Право на выгрузку пользователей со своим data_role')
+ON CONFLICT (permission_name) DO NOTHING;
+
+-- Индекс для ограничения выборки по data_role
+CREATE INDEX IF NOT EXISTS idx_users_data_role ON users (data_role) WHERE deleted_at IS NULL;
@@ -0,0 +1,39 @@
+DELETE
+FROM permissions
+WHERE permission_name IN (
+                          'organizations:view',
+                          'organizations:create',
+                          'organizations:update',
+                          'platform:superadmin'
+    );
+
+DROP TRIGGER IF EXISTS role_parents_organization_check ON role_parents;
+DROP FUNCTION IF EXISTS check_role_parent_organization();
+DROP TRIGGER IF EXISTS user_roles_organization_check ON user_roles;
+DROP FUNCTION IF EXISTS check_user_role_organization();
+
+-- Роли организаций удаляются: без organization_id их названия могут совпадать
+DELETE
+FROM roles
+WHERE organization_id IS NOT NULL;
+
+DROP INDEX IF EXISTS roles_organization_name_key;
+DROP INDEX IF EXISTS roles_global_name_key;
+ALTER TABLE roles
+    ADD CONSTRAINT roles_role_name_key UNIQUE (role_name);
+ALTER TABLE roles
+    DROP COLUMN IF EXISTS organization_id;
+
+DROP INDEX IF EXISTS idx_user_export_jobs_organization_id;
+ALTER TABLE user_export_jobs
+    DROP COLUMN IF EXISTS organization_id;
+
+DROP INDEX IF EXISTS idx_oauth_clients_organization_id;
+ALTER TABLE oauth_clients
+    DROP COLUMN IF EXISTS organization_id;
+
+DROP INDEX IF EXISTS idx_users_organization_id;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,98 @@
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         UUID PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_organizations_updated_at
+    BEFORE UPDATE
+    ON organizations
+    FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Пользователи и роли без организации относятся к уровню платформы
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations (id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_users_organization_id ON users (organization_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations (id) ON DELETE CASCADE;
+
+-- Клиент действует только в организации, в которой зарегистрирован; без организации - уровень платформы
+ALTER TABLE oauth_clients
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_organization_id ON oauth_clients (organization_id);
+
+-- Задание выгрузки доступно только в организации инициатора; без организации - уровень платформы
+ALTER TABLE user_export_jobs
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_user_export_jobs_organization_id ON user_export_jobs (organization_id);
+
+-- Названия глобальных ролей уникальны на платформе, ролей организации - внутри организации
+ALTER TABLE roles
+    DROP CONSTRAINT IF EXISTS roles_role_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS roles_global_name_key ON roles (role_name) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS roles_organization_name_key ON roles (organization_id, role_name)
+    WHERE organization_id IS NOT NULL;
+
+-- Роль можно назначить пользователю своей организации, а глобальную роль - любому пользователю
+CREATE OR REPLACE FUNCTION check_user_role_organization()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NOT EXISTS (SELECT 1
+                   FROM users u
+                            JOIN roles r ON r.id = NEW.role_id
+                   WHERE u.id = NEW.user_id
+                     AND (r.organization_id IS NULL OR r.organization_id = u.organization_id)) THEN
+        RAISE EXCEPTION 'role % belongs to another organization than user %', NEW.role_id, NEW.user_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_roles_organization_check
+    BEFORE INSERT OR UPDATE
+    ON user_roles
+    FOR EACH ROW
+EXECUTE FUNCTION check_user_role_organization();
+
+-- Роль наследует глобальные роли и роли своей организации; глобальная роль - только глобальные
+CREATE OR REPLACE FUNCTION check_role_parent_organization()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NOT EXISTS (SELECT 1
+                   FROM roles child
+                            JOIN roles parent ON parent.id = NEW.parent_role_id
+                   WHERE child.id = NEW.role_id
+                     AND (parent.organization_id IS NULL OR parent.organization_id = child.organization_id)) THEN
+        RAISE EXCEPTION 'role % cannot inherit role % of another organization', NEW.role_id, NEW.parent_role_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER role_parents_organization_check
+    BEFORE INSERT OR UPDATE
+    ON role_parents
+    FOR EACH ROW
+EXECUTE FUNCTION check_role_parent_organization();
+
+INSERT INTO permissions (permission_name, description)
+VALUES ('organizations:view', 'Право на просмотр организаций'),
+       ('organizations:create', 'Право на создание организаций'),
+       ('organizations:update', 'Право на изменение организаций'),
+       ('platform:superadmin', 'Доступ к данным всех организаций (только для пользователей платформы)')
+ON CONFLICT (permission_name) DO NOTHING;
+
+COMMENT ON TABLE organizations IS 'Организации (арендаторы)';
+COMMENT ON COLUMN users.organization_id IS 'Организация пользователя; NULL - пользователь платформы';
+COMMENT ON COLUMN roles.organization_id IS 'Организация роли; NULL - глобальная роль';
+COMMENT ON COLUMN oauth_clients.organization_id IS 'Организация клиента; NULL - клиент платформы';
+COMMENT ON COLUMN user_export_jobs.organization_id IS 'Организация инициатора; NULL - уровень платформы';
@@ -0,0 +1,21 @@
+DROP POLICY IF EXISTS refresh_tokens_isolation ON refresh_tokens;
+ALTER TABLE refresh_tokens
+    NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens
+    DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS user_roles_isolation ON user_roles;
+ALTER TABLE user_roles
+    NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_roles
+    DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS users_isolation ON users;
+ALTER TABLE users
+    NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users
+    DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_rls_is_current_user(UUID);
+DROP FUNCTION IF EXISTS app_rls_tenant_allows(UUID);
+DROP FUNCTION IF EXISTS app_rls_unrestricted();
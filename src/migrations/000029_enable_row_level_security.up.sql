@@ -0,0 +1,64 @@
+-- Политики row-level security повторяют изоляцию организаций на уровне БД.
+-- Приложение задаёт параметры сессии в каждой транзакции (SET LOCAL):
+--   app.user_id    - пользователь запроса
+--   app.tenant_id  - организация запроса: UUID, 'platform' (данные без организации) или '*' (все организации)
+--   app.roles      - названия ролей пользователя через запятую (для политик, зависящих от ролей)
+--   app.bypass_rls - 'on' для служебных задач, которым явно нужен доступ ко всем строкам
+-- Без параметров сессии политики строк не возвращают: вход в систему, обновление токенов и фоновые
+-- задачи устанавливают app.bypass_rls явно. Миграции, изменяющие строки этих таблиц от имени
+-- владельца таблиц, должны выполнять SET LOCAL app.bypass_rls = 'on'
+
+CREATE OR REPLACE FUNCTION app_rls_unrestricted()
+    RETURNS BOOLEAN AS
+$$
+SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on'
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_rls_tenant_allows(organization_id UUID)
+    RETURNS BOOLEAN AS
+$$
+SELECT CASE COALESCE(current_setting('app.tenant_id', true), '')
+           WHEN '*' THEN TRUE
+           WHEN 'platform' THEN organization_id IS NULL
+           WHEN '' THEN FALSE
+           ELSE organization_id::text = current_setting('app.tenant_id', true)
+           END
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_rls_is_current_user(user_id UUID)
+    RETURNS BOOLEAN AS
+$$
+SELECT user_id::text = COALESCE(current_setting('app.user_id', true), '')
+$$ LANGUAGE sql STABLE;
+
+-- FORCE: приложение обычно подключается владельцем таблиц, на которого политики иначе не действуют
+ALTER TABLE users
+    ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users
+    FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY users_isolation ON users
+    USING (app_rls_unrestricted()
+        OR app_rls_tenant_allows(organization_id)
+        OR app_rls_is_current_user(id));
+
+-- Назначения ролей видны вместе с пользователем: подзапрос к users ограничен его политикой
+ALTER TABLE user_roles
+    ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_roles
+    FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY user_roles_isolation ON user_roles
+    USING (app_rls_unrestricted()
+        OR EXISTS (SELECT 1 FROM users u WHERE u.id = user_roles.user_id));
+
+-- Свои токены пользователь видит всегда, токены других пользователей - в пределах организации
+ALTER TABLE refresh_tokens
+    ENABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens
+    FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY refresh_tokens_isolation ON refresh_tokens
+    USING (app_rls_unrestricted()
+        OR app_rls_is_current_user(user_id)
+        OR EXISTS (SELECT 1 FROM users u WHERE u.id = refresh_tokens.user_id));
@@ -0,0 +1,54 @@
+package dpop
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayCache запоминает jti предъявленных proof, чтобы каждый proof принимался один раз
+type ReplayCache interface {
+	// Remember сохраняет jti до expiresAt. Возвращает false, если jti уже встречался
+	Remember(jti string, expiresAt time.Time) bool
+}
+
+// sweepInterval как часто MemoryReplayCache удаляет просроченные записи
+const sweepInterval = time.Minute
+
+// MemoryReplayCache хранит jti в памяти процесса.
+// При запуске нескольких экземпляров приложения нужна общая реализация ReplayCache
+type MemoryReplayCache struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryReplayCache создает пустой кэш
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		entries:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Remember сохраняет jti до expiresAt. Возвращает false, если jti уже встречался
+func (c *MemoryReplayCache) Remember(jti string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > sweepInterval {
+		for key, exp := range c.entries {
+			if exp.Before(now) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if exp, ok := c.entries[jti]; ok && exp.After(now) {
+		return false
+	}
+
+	c.entries[jti] = expiresAt
+	return true
+}
@@ -0,0 +1,261 @@
+package dpop
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HeaderName заголовок, в котором клиент передает DPoP proof
+const HeaderName = "DPoP"
+
+// proofType значение typ в заголовке DPoP proof
+const proofType = "dpop+jwt"
+
+// clockSkew допустимое расхождение часов клиента и сервера для iat из будущего
+const clockSkew = 30 * time.Second
+
+// signingMethods асимметричные алгоритмы, допустимые для DPoP proof
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var (
+	// ErrMissingProof запрос не содержит DPoP proof
+	ErrMissingProof = errors.New("dpop proof is missing")
+	// ErrInvalidProof proof не прошел проверку
+	ErrInvalidProof = errors.New("invalid dpop proof")
+)
+
+// Proof проверенный DPoP proof
+type Proof struct {
+	// JKT отпечаток публичного ключа клиента (RFC 7638)
+	JKT      string
+	JTI      string
+	Method   string
+	URL      string
+	IssuedAt time.Time
+}
+
+type proofClaims struct {
+	jwt.RegisteredClaims
+	HTM string `json:"htm"`
+	HTU string `json:"htu"`
+	ATH string `json:"ath,omitempty"`
+}
+
+// Verifier проверяет DPoP proof (RFC 9449, раздел 4.3)
+type Verifier struct {
+	maxAge time.Duration
+	cache  ReplayCache
+
+	// trustedProxies подсети прокси, от которых принимаются X-Forwarded-Proto и X-Forwarded-Host
+	trustedProxies []*net.IPNet
+}
+
+// NewVerifier создает проверку proof с максимальным возрастом maxAge.
+// Повторное использование proof отслеживается в cache: MemoryReplayCache работает только
+// в пределах одного процесса, при нескольких экземплярах приложения нужен общий ReplayCache
+func NewVerifier(maxAge time.Duration, cache ReplayCache) *Verifier {
+	return &Verifier{
+		maxAge: maxAge,
+		cache:  cache,
+	}
+}
+
+// SetTrustedProxies задает IP-адреса и подсети (CIDR) обратных прокси, как gin.Engine.SetTrustedProxies.
+// Заголовки X-Forwarded-* учитываются, только если запрос пришел непосредственно от такого прокси
+func (v *Verifier) SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	v.trustedProxies = nets
+	return nil
+}
+
+// VerifyRequest проверяет proof из заголовка запроса.
+// accessToken передается при обращении к защищенному ресурсу, для token endpoint - пустая строка
+func (v *Verifier) VerifyRequest(r *http.Request, accessToken string) (*Proof, error) {
+	values := r.Header.Values(HeaderName)
+	if len(values) == 0 {
+		return nil, ErrMissingProof
+	}
+	if len(values) > 1 {
+		return nil, fmt.Errorf("%w: multiple proofs", ErrInvalidProof)
+	}
+
+	return v.Verify(values[0], r.Method, v.RequestURL(r), accessToken)
+}
+
+// OptionalKey проверяет необязательный proof запроса к endpoint выдачи токенов.
+// Возвращает отпечаток ключа клиента или пустую строку, если клиент не использует DPoP
+func (v *Verifier) OptionalKey(r *http.Request) (string, error) {
+	proof, err := v.VerifyRequest(r, "")
+	if errors.Is(err, ErrMissingProof) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return proof.JKT, nil
+}
+
+// Verify проверяет подпись, метод, адрес, время создания, уникальность и привязку proof к access token
+func (v *Verifier) Verify(proof, method, targetURL, accessToken string) (*Proof, error) {
+	var key *JWK
+	var claims proofClaims
+
+	token, err := jwt.ParseWithClaims(proof, &claims,
+		func(token *jwt.Token) (interface{}, error) {
+			if typ, _ := token.Header["typ"].(string); typ != proofType {
+				return nil, errors.New("unexpected typ")
+			}
+
+			var err error
+			key, err = parseJWK(token.Header["jwk"])
+			if err != nil {
+				return nil, err
+			}
+
+			return key.PublicKey()
+		},
+		jwt.WithValidMethods(signingMethods),
+	)
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	if claims.ID == "" {
+		return nil, fmt.Errorf("%w: jti is required", ErrInvalidProof)
+	}
+	if claims.HTM != method {
+		return nil, fmt.Errorf("%w: htm mismatch", ErrInvalidProof)
+	}
+	if !sameURL(claims.HTU, targetURL) {
+		return nil, fmt.Errorf("%w: htu mismatch", ErrInvalidProof)
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: iat is required", ErrInvalidProof)
+	}
+	issuedAt := claims.IssuedAt.Time
+	now := time.Now()
+	if issuedAt.After(now.Add(clockSkew)) || issuedAt.Before(now.Add(-v.maxAge)) {
+		return nil, fmt.Errorf("%w: iat is out of range", ErrInvalidProof)
+	}
+
+	if accessToken != "" {
+		if claims.ATH == "" || claims.ATH != AccessTokenHash(accessToken) {
+			return nil, fmt.Errorf("%w: ath mismatch", ErrInvalidProof)
+		}
+	}
+
+	jkt, err := key.Thumbprint()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	// jti уникален в пределах ключа; запись живет, пока proof мог бы пройти проверку iat
+	if !v.cache.Remember(jkt+":"+claims.ID, issuedAt.Add(v.maxAge+clockSkew)) {
+		return nil, fmt.Errorf("%w: proof has already been used", ErrInvalidProof)
+	}
+
+	return &Proof{
+		JKT:      jkt,
+		JTI:      claims.ID,
+		Method:   claims.HTM,
+		URL:      claims.HTU,
+		IssuedAt: issuedAt,
+	}, nil
+}
+
+// AccessTokenHash значение ath для access token
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// RequestURL восстанавливает адрес запроса без query и fragment, как его видит клиент.
+// Схема и хост берутся из X-Forwarded-Proto и X-Forwarded-Host, только если запрос пришел
+// от доверенного прокси: иначе клиент мог бы подставить адрес, на который выписан чужой proof
+func (v *Verifier) RequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if v.fromTrustedProxy(r) {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+		}
+		if forwardedHost := r.Header.Get("X-Forwarded-Host"); forwardedHost != "" {
+			host = strings.TrimSpace(strings.Split(forwardedHost, ",")[0])
+		}
+	}
+
+	return scheme + "://" + host + r.URL.Path
+}
+
+// fromTrustedProxy проверяет, что непосредственный отправитель запроса - доверенный прокси
+func (v *Verifier) fromTrustedProxy(r *http.Request) bool {
+	if len(v.trustedProxies) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		host = strings.TrimSpace(r.RemoteAddr)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range v.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameURL сравнивает htu с адресом запроса без учета query, fragment и регистра схемы и хоста
+func sameURL(htu, target string) bool {
+	a, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Host, b.Host) &&
+		a.EscapedPath() == b.EscapedPath()
+}
@@ -0,0 +1,116 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+
+	// Регистрация декодеров поддерживаемых форматов
+	_ "image/gif"
+	_ "image/png"
+)
+
+var (
+	// ErrUnsupportedType содержимое не является изображением JPEG, PNG или GIF
+	ErrUnsupportedType = errors.New("unsupported image type")
+	// ErrTooLarge размеры изображения превышают допустимые
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// SupportedTypes MIME-типы, которые можно декодировать
+var SupportedTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// SniffType определяет MIME-тип по содержимому (заголовок Content-Type клиента не учитывается)
+func SniffType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	for _, supported := range SupportedTypes {
+		if contentType == supported {
+			return contentType, nil
+		}
+	}
+	return contentType, ErrUnsupportedType
+}
+
+// Decode декодирует изображение, предварительно проверив его размеры по заголовку,
+// чтобы не распаковывать в память изображения-бомбы
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	return img, nil
+}
+
+// SquareThumbnail вырезает центральный квадрат и уменьшает его до size x size.
+// Изображения меньше size не увеличиваются
+func SquareThumbnail(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	return resize(src, crop, min(size, side))
+}
+
+// resize уменьшает область rect изображения до квадрата size x size усреднением пикселей (box filter)
+func resize(src image.Image, rect image.Rectangle, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	scale := float64(rect.Dx()) / float64(size)
+
+	for y := 0; y < size; y++ {
+		y0 := rect.Min.Y + int(float64(y)*scale)
+		y1 := max(rect.Min.Y+int(float64(y+1)*scale), y0+1)
+		for x := 0; x < size; x++ {
+			x0 := rect.Min.X + int(float64(x)*scale)
+			x1 := max(rect.Min.X+int(float64(x+1)*scale), x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG кодирует изображение в JPEG; прозрачные области заливаются белым
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	canvas := image.NewRGBA(img.Bounds())
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
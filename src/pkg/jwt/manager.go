@@ -3,6 +3,7 @@ package jwt
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,9 +12,52 @@ import (
 // UserClaims расширяет стандартные JWT claims
 type UserClaims struct {
 	jwt.RegisteredClaims
-	UserID      string   `json:"user_id"`
+	UserID      string   `json:"user_id,omitempty"`
 	Roles       []string `json:"roles,omitempty"`
 	Permissions []string `json:"permissions,omitempty"`
+	// ClientID идентификатор OAuth2 клиента, которому выдан токен
+	ClientID string `json:"client_id,omitempty"`
+	// Scope разрешения токена через пробел (RFC 9068)
+	Scope string `json:"scope,omitempty"`
+	// Act сторона, действующая от имени пользователя (RFC 8693, раздел 4.1)
+	Act *ActorClaim `json:"act,omitempty"`
+	// Cnf ключ, к которому привязан токен (RFC 9449, раздел 6)
+	Cnf *Confirmation `json:"cnf,omitempty"`
+	// OrganizationID организация пользователя; отсутствует у пользователей платформы
+	OrganizationID string `json:"org_id,omitempty"`
+}
+
+// Confirmation привязка токена к ключу клиента
+type Confirmation struct {
+	// JKT SHA-256 отпечаток публичного ключа DPoP (RFC 7638)
+	JKT string `json:"jkt"`
+}
+
+// TokenOption дополнительный параметр выпускаемого токена
+type TokenOption func(claims *UserClaims)
+
+// WithDPoPKey привязывает токен к ключу DPoP: без proof, подписанного этим ключом, токен не принимается
+func WithDPoPKey(jkt string) TokenOption {
+	return func(claims *UserClaims) {
+		if jkt != "" {
+			claims.Cnf = &Confirmation{JKT: jkt}
+		}
+	}
+}
+
+// WithOrganization указывает организацию пользователя, для которой выпущен токен
+func WithOrganization(organizationID string) TokenOption {
+	return func(claims *UserClaims) {
+		claims.OrganizationID = organizationID
+	}
+}
+
+// ActorClaim описывает клиента, которому делегирован токен пользователя.
+// Вложенный Act сохраняет предыдущие звенья цепочки делегирования
+type ActorClaim struct {
+	Subject  string      `json:"sub"`
+	ClientID string      `json:"client_id,omitempty"`
+	Act      *ActorClaim `json:"act,omitempty"`
 }
 
 // Manager предоставляет методы для работы с JWT токенами
@@ -31,7 +75,7 @@ func NewManager(secret string, expirationMinutes int) *Manager {
 }
 
 // GenerateToken создает JWT токен для пользователя с указанными ролями
-func (m *Manager) GenerateToken(userID string, roles []string, permissions []string) (string, error) {
+func (m *Manager) GenerateToken(userID string, roles []string, permissions []string, opts ...TokenOption) (string, error) {
 	// Время жизни токена
 	expiresAt := time.Now().Add(time.Duration(m.expirationMinutes) * time.Minute)
 
@@ -47,6 +91,9 @@ func (m *Manager) GenerateToken(userID string, roles []string, permissions []str
 		Roles:       roles,
 		Permissions: permissions,
 	}
+	for _, opt := range opts {
+		opt(&claims)
+	}
 
 	// Создание токена
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -60,6 +107,76 @@ func (m *Manager) GenerateToken(userID string, roles []string, permissions []str
 	return tokenString, nil
 }
 
+// GenerateClientToken создает JWT токен для машинного клиента (client credentials grant).
+// Разрешения токена ограничены запрошенными scope
+func (m *Manager) GenerateClientToken(clientID string, scopes []string, opts ...TokenOption) (string, error) {
+	expiresAt := time.Now().Add(time.Duration(m.expirationMinutes) * time.Minute)
+
+	claims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+		ClientID:    clientID,
+		Permissions: scopes,
+		Scope:       strings.Join(scopes, " "),
+	}
+	for _, opt := range opts {
+		opt(&claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(m.secret))
+	if err != nil {
+		return "", fmt.Errorf("ошибка подписания токена: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// GenerateDelegatedToken создает токен пользователя для вызова стороннего сервиса (token exchange).
+// Токен ограничен получателем и scope, клиент указывается в act, срок действия не превышает исходный токен
+func (m *Manager) GenerateDelegatedToken(subject *UserClaims, actorClientID string, audience string, scopes []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(m.expirationMinutes) * time.Minute)
+	if subject.ExpiresAt != nil && subject.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt.Time
+	}
+
+	claims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject.UserID,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		UserID:      subject.UserID,
+		Permissions: scopes,
+		ClientID:    actorClientID,
+		Scope:       strings.Join(scopes, " "),
+		// Делегированный токен действует в той же организации, что и исходный
+		OrganizationID: subject.OrganizationID,
+		Act: &ActorClaim{
+			Subject:  actorClientID,
+			ClientID: actorClientID,
+			Act:      subject.Act,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(m.secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка подписания токена: %w", err)
+	}
+
+	return tokenString, expiresAt, nil
+}
+
 // ValidateToken проверяет токен и возвращает его claims
 func (m *Manager) ValidateToken(tokenString string) (*UserClaims, error) {
 	// Парсинг токена с проверкой подписи
@@ -103,6 +220,30 @@ func (m *Manager) Secret() string {
 	return m.secret
 }
 
+// IsClientToken проверяет, выдан ли токен машинному клиенту, а не пользователю
+func (claims *UserClaims) IsClientToken() bool {
+	return claims.UserID == "" && claims.ClientID != ""
+}
+
+// IsDPoPBound проверяет, привязан ли токен к ключу DPoP
+func (claims *UserClaims) IsDPoPBound() bool {
+	return claims.Cnf != nil && claims.Cnf.JKT != ""
+}
+
+// IsIntendedFor проверяет, может ли токен быть предъявлен указанному получателю.
+// Токены без aud выпускаются для собственного API и принимаются только им
+func (claims *UserClaims) IsIntendedFor(audience string) bool {
+	if len(claims.Audience) == 0 {
+		return true
+	}
+	for _, aud := range claims.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
 // HasRole проверяет наличие указанной роли в claims
 func (claims *UserClaims) HasRole(role string) bool {
 	for _, r := range claims.Roles {
@@ -0,0 +1,144 @@
+package mtls
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrRevoked сертификат отозван
+var ErrRevoked = errors.New("certificate is revoked")
+
+// RevocationChecker проверяет сертификаты по списку отзыва из файла.
+// Файл перечитывается при изменении, поэтому обновлять CRL можно без перезапуска сервиса
+type RevocationChecker struct {
+	path    string
+	issuers []*x509.Certificate
+	now     func() time.Time
+
+	mu      sync.RWMutex
+	modTime time.Time
+	lists   []*x509.RevocationList
+	revoked map[string]struct{}
+}
+
+// NewRevocationChecker создает проверку по CRL-файлу (PEM с одним или несколькими списками или DER).
+// issuers - сертификаты УЦ, которыми должны быть подписаны списки
+func NewRevocationChecker(path string, issuers []*x509.Certificate) (*RevocationChecker, error) {
+	checker := &RevocationChecker{
+		path:    path,
+		issuers: issuers,
+		now:     time.Now,
+	}
+	if err := checker.reload(); err != nil {
+		return nil, err
+	}
+	return checker, nil
+}
+
+// Check возвращает ErrRevoked для отозванного сертификата.
+// Просроченный или непроверенный CRL - ошибка: при недоступном списке отзыва доступ не выдается
+func (c *RevocationChecker) Check(cert *x509.Certificate) error {
+	if err := c.reloadIfChanged(); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	for _, list := range c.lists {
+		if !list.NextUpdate.IsZero() && now.After(list.NextUpdate) {
+			return fmt.Errorf("crl issued by %s has expired", list.Issuer)
+		}
+	}
+
+	if _, revoked := c.revoked[revocationKey(cert.RawIssuer, cert.SerialNumber.String())]; revoked {
+		return ErrRevoked
+	}
+	return nil
+}
+
+func (c *RevocationChecker) reloadIfChanged() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return fmt.Errorf("stat crl: %w", err)
+	}
+
+	c.mu.RLock()
+	changed := !info.ModTime().Equal(c.modTime)
+	c.mu.RUnlock()
+
+	if !changed {
+		return nil
+	}
+	return c.reload()
+}
+
+func (c *RevocationChecker) reload() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return fmt.Errorf("stat crl: %w", err)
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("read crl: %w", err)
+	}
+
+	var ders [][]byte
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "X509 CRL" {
+			ders = append(ders, block.Bytes)
+		}
+	}
+	if len(ders) == 0 {
+		ders = [][]byte{data}
+	}
+
+	lists := make([]*x509.RevocationList, 0, len(ders))
+	revoked := make(map[string]struct{})
+	for _, der := range ders {
+		list, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return fmt.Errorf("parse crl: %w", err)
+		}
+		if err := c.verifyIssuer(list); err != nil {
+			return err
+		}
+		for _, entry := range list.RevokedCertificateEntries {
+			revoked[revocationKey(list.RawIssuer, entry.SerialNumber.String())] = struct{}{}
+		}
+		lists = append(lists, list)
+	}
+
+	c.mu.Lock()
+	c.modTime = info.ModTime()
+	c.lists = lists
+	c.revoked = revoked
+	c.mu.Unlock()
+
+	return nil
+}
+
+// verifyIssuer проверяет, что список подписан одним из доверенных УЦ
+func (c *RevocationChecker) verifyIssuer(list *x509.RevocationList) error {
+	for _, issuer := range c.issuers {
+		if list.CheckSignatureFrom(issuer) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("crl issued by %s is not signed by a trusted ca", list.Issuer)
+}
+
+func revocationKey(rawIssuer []byte, serial string) string {
+	return string(rawIssuer) + "|" + serial
+}
@@ -0,0 +1,72 @@
+package mtls
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+)
+
+// Типы идентификаторов сертификата, по которым он сопоставляется с клиентом
+const (
+	IdentitySPIFFE  = "spiffe"
+	IdentityURI     = "uri"
+	IdentityDNS     = "dns"
+	IdentityEmail   = "email"
+	IdentitySubject = "subject"
+)
+
+// IdentityTypes все поддерживаемые типы в порядке приоритета сопоставления
+var IdentityTypes = []string{IdentitySPIFFE, IdentityURI, IdentityDNS, IdentityEmail, IdentitySubject}
+
+// Identity идентификатор из сертификата
+type Identity struct {
+	Type  string
+	Value string
+}
+
+// Identities возвращает идентификаторы сертификата в порядке приоритета:
+// SPIFFE ID, прочие URI SAN, DNS SAN, email SAN и в конце subject DN.
+// Более специфичный идентификатор выигрывает, если сопоставлены несколько
+func Identities(cert *x509.Certificate) []Identity {
+	var identities []Identity
+
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			identities = append(identities, Identity{Type: IdentitySPIFFE, Value: uri.String()})
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme != "spiffe" {
+			identities = append(identities, Identity{Type: IdentityURI, Value: uri.String()})
+		}
+	}
+	for _, name := range cert.DNSNames {
+		identities = append(identities, Identity{Type: IdentityDNS, Value: strings.ToLower(name)})
+	}
+	for _, email := range cert.EmailAddresses {
+		identities = append(identities, Identity{Type: IdentityEmail, Value: strings.ToLower(email)})
+	}
+	if subject := cert.Subject.String(); subject != "" {
+		identities = append(identities, Identity{Type: IdentitySubject, Value: subject})
+	}
+
+	return identities
+}
+
+// NormalizeIdentity приводит значение к виду, в котором его возвращает Identities
+func NormalizeIdentity(identityType, value string) string {
+	value = strings.TrimSpace(value)
+	switch identityType {
+	case IdentityDNS, IdentityEmail:
+		return strings.ToLower(value)
+	default:
+		return value
+	}
+}
+
+// Fingerprint SHA-256 отпечаток сертификата для журналов аудита
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
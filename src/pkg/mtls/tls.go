@@ -0,0 +1,77 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// LoadCertificates читает PEM-файл с сертификатами УЦ
+func LoadCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certificates, nil
+}
+
+// ServerConfig настройки TLS сервера, который запрашивает, но не требует клиентский сертификат:
+// клиенты без сертификата продолжают аутентифицироваться по Bearer токену
+func ServerConfig(certFile, keyFile string, clientCAs []*x509.Certificate) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(clientCAs) > 0 {
+		pool := x509.NewCertPool()
+		for _, ca := range clientCAs {
+			pool.AddCert(ca)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return cfg, nil
+}
+
+// PeerCertificate возвращает клиентский сертификат, проверенный при установке TLS-соединения
+func PeerCertificate(r *http.Request) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	// Без построенной цепочки сертификат не проверен (например, при ClientAuth=RequestClientCert)
+	if len(r.TLS.VerifiedChains) == 0 {
+		return nil, errors.New("client certificate is not verified")
+	}
+	return r.TLS.VerifiedChains[0][0], nil
+}
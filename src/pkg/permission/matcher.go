@@ -0,0 +1,177 @@
+// Package permission реализует грамматику разрешений и их сопоставление с учётом шаблонов.
+//
+// Разрешение состоит из сегментов, разделённых двоеточием: "users:view", "reports:sales:export".
+// Сегмент "*" в середине разрешения совпадает ровно с одним сегментом ("*:view" покрывает
+// "users:view", но не "reports:sales:view"), а последний сегмент "*" - с одним или несколькими
+// оставшимися сегментами ("users:*" покрывает "users:view" и "users:exports:download").
+// Разрешение "*" покрывает любое другое. Исключение - ограниченные разрешения, переданные
+// в CompileRestricted: шаблоны ресурса их не покрывают, они выдаются явно или через "*".
+package permission
+
+import (
+	"strings"
+)
+
+const (
+	// Separator разделитель сегментов разрешения
+	Separator = ":"
+	// Wildcard сегмент-шаблон
+	Wildcard = "*"
+	// All разрешение, покрывающее все остальные
+	All = Wildcard
+)
+
+// Valid проверяет, что имя разрешения соответствует грамматике: непустые сегменты из
+// строчных латинских букв, цифр, "-", "_", "." или шаблон "*"
+func Valid(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, segment := range strings.Split(name, Separator) {
+		if segment == Wildcard {
+			continue
+		}
+		if segment == "" {
+			return false
+		}
+		for _, c := range segment {
+			switch {
+			case 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Matcher набор выданных разрешений, скомпилированный в префиксное дерево по сегментам.
+// Проверка не зависит от числа выданных разрешений, только от длины требуемого.
+// После создания Matcher не изменяется и безопасен для использования из нескольких горутин
+type Matcher struct {
+	root *node
+
+	// restricted разрешения, которые покрываются только явной выдачей или "*"
+	restricted map[string]bool
+}
+
+type node struct {
+	children map[string]*node
+	// any потомок для сегмента "*" в середине разрешения
+	any *node
+	// terminal на этом узле заканчивается выданное разрешение
+	terminal bool
+	// rest выдано разрешение с "*" в конце: покрыты все продолжения этого префикса
+	rest bool
+}
+
+// Compile компилирует выданные разрешения. Пустые строки пропускаются
+func Compile(grants ...string) *Matcher {
+	return CompileRestricted(nil, grants...)
+}
+
+// CompileRestricted компилирует выданные разрешения так же, как Compile, но разрешения из restricted
+// покрываются только явной выдачей или "*": шаблоны вроде "users:*" и "*:purge" их не включают
+func CompileRestricted(restricted []string, grants ...string) *Matcher {
+	m := &Matcher{root: &node{}}
+	if len(restricted) > 0 {
+		m.restricted = make(map[string]bool, len(restricted))
+		for _, name := range restricted {
+			m.restricted[name] = true
+		}
+	}
+	for _, grant := range grants {
+		m.add(grant)
+	}
+	return m
+}
+
+func (m *Matcher) add(grant string) {
+	if grant == "" {
+		return
+	}
+
+	segments := strings.Split(grant, Separator)
+	current := m.root
+	for i, segment := range segments {
+		if segment == Wildcard && i == len(segments)-1 {
+			current.rest = true
+			return
+		}
+
+		if segment == Wildcard {
+			if current.any == nil {
+				current.any = &node{}
+			}
+			current = current.any
+			continue
+		}
+
+		if current.children == nil {
+			current.children = make(map[string]*node)
+		}
+		next, ok := current.children[segment]
+		if !ok {
+			next = &node{}
+			current.children[segment] = next
+		}
+		current = next
+	}
+	current.terminal = true
+}
+
+// Allows проверяет, покрывает ли набор требуемое разрешение
+func (m *Matcher) Allows(required string) bool {
+	if m == nil || required == "" {
+		return false
+	}
+
+	segments := strings.Split(required, Separator)
+	if m.restricted[required] {
+		return m.root.rest || m.root.matchExact(segments)
+	}
+	return m.root.match(segments)
+}
+
+// AllowsAny проверяет, покрывает ли набор хотя бы одно из требуемых разрешений
+func (m *Matcher) AllowsAny(required ...string) bool {
+	for _, permission := range required {
+		if m.Allows(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) match(segments []string) bool {
+	if len(segments) == 0 {
+		return n.terminal
+	}
+	if n.rest {
+		return true
+	}
+
+	if next, ok := n.children[segments[0]]; ok && next.match(segments[1:]) {
+		return true
+	}
+	return n.any != nil && n.any.match(segments[1:])
+}
+
+// matchExact ищет выданное разрешение, совпадающее с требуемым без шаблонов
+func (n *node) matchExact(segments []string) bool {
+	for _, segment := range segments {
+		next, ok := n.children[segment]
+		if !ok {
+			return false
+		}
+		n = next
+	}
+	return n.terminal
+}
+
+// Match проверяет, покрывает ли одно выданное разрешение требуемое
+func Match(grant, required string) bool {
+	return Compile(grant).Allows(required)
+}
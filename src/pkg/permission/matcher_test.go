@@ -0,0 +1,104 @@
+package permission
+
+import "testing"
+
+var testRestricted = []string{"users:update-restricted", "users:purge"}
+
+func TestMatcherAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		grants   []string
+		required string
+		want     bool
+	}{
+		{name: "exact", grants: []string{"users:view"}, required: "users:view", want: true},
+		{name: "exact other action", grants: []string{"users:view"}, required: "users:update", want: false},
+		{name: "exact other resource", grants: []string{"users:view"}, required: "roles:view", want: false},
+		{name: "exact is not a prefix", grants: []string{"users"}, required: "users:view", want: false},
+		{name: "longer grant", grants: []string{"users:view:own"}, required: "users:view", want: false},
+		{name: "three segments", grants: []string{"reports:sales:export"}, required: "reports:sales:export", want: true},
+		{name: "no grants", grants: nil, required: "users:view", want: false},
+		{name: "empty required", grants: []string{"*"}, required: "", want: false},
+
+		{name: "all", grants: []string{"*"}, required: "users:view", want: true},
+		{name: "all covers nested", grants: []string{"*"}, required: "reports:sales:export", want: true},
+		{name: "resource wildcard", grants: []string{"users:*"}, required: "users:view", want: true},
+		{name: "resource wildcard nested", grants: []string{"users:*"}, required: "users:exports:download", want: true},
+		{name: "resource wildcard other resource", grants: []string{"users:*"}, required: "roles:view", want: false},
+		{name: "resource wildcard needs a segment", grants: []string{"users:*"}, required: "users", want: false},
+		{name: "action wildcard", grants: []string{"*:view"}, required: "roles:view", want: true},
+		{name: "action wildcard other action", grants: []string{"*:view"}, required: "roles:update", want: false},
+		{name: "middle wildcard is one segment", grants: []string{"*:view"}, required: "reports:sales:view", want: false},
+		{name: "nested wildcard", grants: []string{"reports:sales:*"}, required: "reports:sales:export", want: true},
+		{name: "nested wildcard sibling", grants: []string{"reports:sales:*"}, required: "reports:hr:export", want: false},
+		{name: "backtracking across branches", grants: []string{"*:sales:view", "reports:hr:*"}, required: "reports:sales:view", want: true},
+
+		{name: "restricted exact", grants: []string{"users:purge"}, required: "users:purge", want: true},
+		{name: "restricted by all", grants: []string{"*"}, required: "users:purge", want: true},
+		{name: "restricted not by resource wildcard", grants: []string{"users:*"}, required: "users:purge", want: false},
+		{name: "restricted not by action wildcard", grants: []string{"*:purge"}, required: "users:purge", want: false},
+		{name: "restricted not by both wildcards", grants: []string{"*:*"}, required: "users:update-restricted", want: false},
+		{name: "restricted does not affect others", grants: []string{"users:*"}, required: "users:update", want: true},
+
+		{name: "malformed empty segment", grants: []string{"users:view"}, required: "users::view", want: false},
+		{name: "malformed trailing separator", grants: []string{"users:view"}, required: "users:view:", want: false},
+		{name: "malformed grant with empty segment", grants: []string{"users::view"}, required: "users:view", want: false},
+		{name: "empty grant skipped", grants: []string{""}, required: "users:view", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CompileRestricted(testRestricted, tt.grants...)
+			if got := m.Allows(tt.required); got != tt.want {
+				t.Errorf("CompileRestricted(%q).Allows(%q) = %v, want %v", tt.grants, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileWithoutRestricted(t *testing.T) {
+	if !Compile("users:*").Allows("users:purge") {
+		t.Error(`Compile("users:*").Allows("users:purge") = false, want true without restricted permissions`)
+	}
+}
+
+func TestMatcherNil(t *testing.T) {
+	var m *Matcher
+	if m.Allows("users:view") {
+		t.Error("nil Matcher allows users:view")
+	}
+	if m.AllowsAny("users:view", "*") {
+		t.Error("nil Matcher allows any")
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "users:view", want: true},
+		{name: "reports:sales:export", want: true},
+		{name: "users:*", want: true},
+		{name: "*:view", want: true},
+		{name: "*", want: true},
+		{name: "users:update-restricted", want: true},
+		{name: "billing.v2:read_all", want: true},
+		{name: "", want: false},
+		{name: "users:", want: false},
+		{name: ":view", want: false},
+		{name: "users::view", want: false},
+		{name: "Users:view", want: false},
+		{name: "users:view all", want: false},
+		{name: "users:ви", want: false},
+		{name: "users:v*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.name); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
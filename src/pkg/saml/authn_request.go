@@ -0,0 +1,84 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+	"time"
+)
+
+// authnRequest запрос аутентификации SP-initiated SSO.
+// Префиксы заданы явно: encoding/xml иначе повторяет xmlns на каждом элементе
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"samlp:AuthnRequest"`
+	XMLNSProtocol               string       `xml:"xmlns:samlp,attr"`
+	XMLNSAssertion              string       `xml:"xmlns:saml,attr"`
+	ID                          string       `xml:"ID,attr"`
+	Version                     string       `xml:"Version,attr"`
+	IssueInstant                string       `xml:"IssueInstant,attr"`
+	Destination                 string       `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string       `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string       `xml:"ProtocolBinding,attr"`
+	Issuer                      string       `xml:"saml:Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"samlp:NameIDPolicy"`
+}
+
+type nameIDPolicy struct {
+	Format      string `xml:"Format,attr"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+// AuthnRequest формирует AuthnRequest для IdP
+func (sp *ServiceProvider) AuthnRequest(idp *IdentityProvider, requestID string, issuedAt time.Time) ([]byte, error) {
+	return xml.Marshal(authnRequest{
+		XMLNSProtocol:               NamespaceProtocol,
+		XMLNSAssertion:              NamespaceAssertion,
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                issuedAt.UTC().Format(time.RFC3339),
+		Destination:                 idp.SSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             BindingHTTPPost,
+		Issuer:                      sp.EntityID,
+		NameIDPolicy: nameIDPolicy{
+			Format:      NameIDFormatEmail,
+			AllowCreate: true,
+		},
+	})
+}
+
+// RedirectURL формирует адрес перенаправления на IdP по привязке HTTP-Redirect
+func (sp *ServiceProvider) RedirectURL(idp *IdentityProvider, requestID string, relayState string) (string, error) {
+	request, err := sp.AuthnRequest(idp, requestID, sp.now())
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	writer, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := writer.Write(request); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(idp.SSOURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := target.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	target.RawQuery = query.Encode()
+
+	return target.String(), nil
+}
@@ -0,0 +1,164 @@
+package saml
+
+import (
+	"sort"
+	"strings"
+)
+
+// Алгоритмы канонизации и преобразований XML-DSig
+const (
+	AlgorithmExcC14N            = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	AlgorithmEnvelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+// canonicalize сериализует поддерево по правилам Exclusive XML Canonicalization 1.0 без комментариев.
+// skip - узел, исключаемый из вывода (подпись при преобразовании enveloped-signature).
+// inclusivePrefixes - список InclusiveNamespaces PrefixList, "#default" означает пространство по умолчанию
+func canonicalize(el *Element, skip *Element, inclusivePrefixes []string) []byte {
+	inclusive := make(map[string]bool, len(inclusivePrefixes))
+	for _, p := range inclusivePrefixes {
+		if p == "#default" {
+			p = ""
+		}
+		inclusive[p] = true
+	}
+
+	var sb strings.Builder
+	writeCanonical(&sb, el, skip, map[string]string{}, inclusive)
+	return []byte(sb.String())
+}
+
+type nsDecl struct {
+	prefix string
+	uri    string
+}
+
+type canonicalAttr struct {
+	name  string
+	uri   string
+	local string
+	value string
+}
+
+func writeCanonical(sb *strings.Builder, el *Element, skip *Element, rendered map[string]string, inclusive map[string]bool) {
+	// Пространства имен, видимо используемые элементом и его атрибутами
+	utilized := map[string]bool{el.Prefix: true}
+	for _, a := range el.Attrs {
+		if a.Prefix != "" && a.Prefix != "xmlns" && a.Prefix != "xml" {
+			utilized[a.Prefix] = true
+		}
+	}
+
+	var decls []nsDecl
+	scope := make(map[string]string, len(rendered)+len(utilized))
+	for k, v := range rendered {
+		scope[k] = v
+	}
+
+	consider := func(prefix string, mustRender bool) {
+		uri, ok := el.LookupNamespace(prefix)
+		if !ok {
+			return
+		}
+		previous, wasRendered := rendered[prefix]
+		if prefix == "" && uri == "" {
+			// xmlns="" выводится, только если выше было выведено непустое пространство по умолчанию
+			if wasRendered && previous != "" {
+				decls = append(decls, nsDecl{prefix: "", uri: ""})
+				scope[""] = ""
+			}
+			return
+		}
+		if wasRendered && previous == uri {
+			return
+		}
+		if mustRender || inclusive[prefix] {
+			decls = append(decls, nsDecl{prefix: prefix, uri: uri})
+			scope[prefix] = uri
+		}
+	}
+
+	for prefix := range utilized {
+		consider(prefix, true)
+	}
+	for prefix := range inclusive {
+		if !utilized[prefix] && prefix != "xml" {
+			consider(prefix, false)
+		}
+	}
+
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+
+	var attrs []canonicalAttr
+	for _, a := range el.Attrs {
+		if a.Prefix == "xmlns" || (a.Prefix == "" && a.Local == "xmlns") {
+			continue
+		}
+		attr := canonicalAttr{name: a.Local, local: a.Local, value: a.Value}
+		if a.Prefix != "" {
+			attr.name = a.Prefix + ":" + a.Local
+			attr.uri, _ = el.LookupNamespace(a.Prefix)
+		}
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := el.Local
+	if el.Prefix != "" {
+		name = el.Prefix + ":" + el.Local
+	}
+
+	sb.WriteByte('<')
+	sb.WriteString(name)
+	for _, d := range decls {
+		if d.prefix == "" {
+			sb.WriteString(` xmlns="`)
+		} else {
+			sb.WriteString(` xmlns:`)
+			sb.WriteString(d.prefix)
+			sb.WriteString(`="`)
+		}
+		sb.WriteString(escapeAttr(d.uri))
+		sb.WriteByte('"')
+	}
+	for _, a := range attrs {
+		sb.WriteByte(' ')
+		sb.WriteString(a.name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeAttr(a.value))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('>')
+
+	for _, child := range el.Children {
+		switch c := child.(type) {
+		case *Element:
+			if c != skip {
+				writeCanonical(sb, c, skip, scope, inclusive)
+			}
+		case CharData:
+			sb.WriteString(escapeText(string(c)))
+		}
+	}
+
+	sb.WriteString("</")
+	sb.WriteString(name)
+	sb.WriteByte('>')
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
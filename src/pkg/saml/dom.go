@@ -0,0 +1,183 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Пространства имен, используемые при разборе ответов IdP
+const (
+	NamespaceProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	NamespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	NamespaceMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	NamespaceDSig      = "http://www.w3.org/2000/09/xmldsig#"
+
+	namespaceXML = "http://www.w3.org/XML/1998/namespace"
+)
+
+// Element узел XML-документа с сохранением префиксов и объявлений пространств имен
+// в исходном виде: это нужно для канонизации при проверке подписи
+type Element struct {
+	Prefix   string
+	Local    string
+	Attrs    []Attr
+	Children []Node
+	Parent   *Element
+}
+
+// Attr атрибут элемента; объявления пространств имен хранятся как атрибуты xmlns
+type Attr struct {
+	Prefix string
+	Local  string
+	Value  string
+}
+
+// Node дочерний узел элемента: *Element или CharData
+type Node interface{}
+
+// CharData текстовое содержимое элемента
+type CharData string
+
+// ParseDocument разбирает XML-документ.
+// DTD запрещены: SAML они не нужны, а объявления сущностей - известный вектор атак.
+// Комментарии тоже запрещены: канонизация их отбрасывает, поэтому вставка комментария
+// в подписанный текст не ломает подпись, но меняет значение для наивного чтения
+func ParseDocument(data []byte) (*Element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root, current *Element
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &Element{Prefix: t.Name.Space, Local: t.Name.Local, Parent: current}
+			for _, a := range t.Attr {
+				el.Attrs = append(el.Attrs, Attr{Prefix: a.Name.Space, Local: a.Name.Local, Value: a.Value})
+			}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("multiple root elements")
+				}
+				root = el
+			} else {
+				current.Children = append(current.Children, el)
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("unexpected end element")
+			}
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				current.Children = append(current.Children, CharData(t))
+			}
+		case xml.Directive:
+			return nil, errors.New("xml directives are not allowed")
+		case xml.Comment:
+			return nil, errors.New("xml comments are not allowed")
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("empty document")
+	}
+	if current != nil {
+		return nil, errors.New("unexpected end of document")
+	}
+	return root, nil
+}
+
+// LookupNamespace возвращает пространство имен, связанное с префиксом в области видимости элемента
+func (e *Element) LookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return namespaceXML, true
+	}
+	for el := e; el != nil; el = el.Parent {
+		for _, a := range el.Attrs {
+			if (prefix == "" && a.Prefix == "" && a.Local == "xmlns") || (prefix != "" && a.Prefix == "xmlns" && a.Local == prefix) {
+				return a.Value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// Namespace пространство имен элемента
+func (e *Element) Namespace() string {
+	ns, _ := e.LookupNamespace(e.Prefix)
+	return ns
+}
+
+// Is проверяет пространство имен и локальное имя элемента
+func (e *Element) Is(namespace, local string) bool {
+	return e.Local == local && e.Namespace() == namespace
+}
+
+// Attr возвращает значение атрибута без префикса
+func (e *Element) Attr(local string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Prefix == "" && a.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// ChildElements возвращает дочерние элементы с заданным именем
+func (e *Element) ChildElements(namespace, local string) []*Element {
+	var result []*Element
+	for _, child := range e.Children {
+		if el, ok := child.(*Element); ok && el.Is(namespace, local) {
+			result = append(result, el)
+		}
+	}
+	return result
+}
+
+// ChildElement возвращает единственный дочерний элемент с заданным именем
+func (e *Element) ChildElement(namespace, local string) (*Element, error) {
+	children := e.ChildElements(namespace, local)
+	switch len(children) {
+	case 0:
+		return nil, fmt.Errorf("missing %s element", local)
+	case 1:
+		return children[0], nil
+	default:
+		return nil, fmt.Errorf("multiple %s elements", local)
+	}
+}
+
+// OptionalChildElement возвращает дочерний элемент, если он есть, и ошибку, если их несколько
+func (e *Element) OptionalChildElement(namespace, local string) (*Element, error) {
+	children := e.ChildElements(namespace, local)
+	if len(children) > 1 {
+		return nil, fmt.Errorf("multiple %s elements", local)
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return children[0], nil
+}
+
+// Text возвращает текстовое содержимое элемента без окружающих пробелов
+func (e *Element) Text() string {
+	var sb strings.Builder
+	for _, child := range e.Children {
+		if text, ok := child.(CharData); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
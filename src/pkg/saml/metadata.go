@@ -0,0 +1,49 @@
+package saml
+
+import "encoding/xml"
+
+type entityDescriptor struct {
+	XMLName         xml.Name        `xml:"md:EntityDescriptor"`
+	XMLNSMetadata   string          `xml:"xmlns:md,attr"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string                   `xml:"md:NameIDFormat"`
+	AssertionConsumerService   assertionConsumerService `xml:"md:AssertionConsumerService"`
+}
+
+type assertionConsumerService struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// Metadata возвращает метаданные SP для регистрации на стороне IdP
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	data, err := xml.MarshalIndent(entityDescriptor{
+		XMLNSMetadata: NamespaceMetadata,
+		EntityID:      sp.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: NamespaceProtocol,
+			NameIDFormat:               NameIDFormatEmail,
+			AssertionConsumerService: assertionConsumerService{
+				Binding:   BindingHTTPPost,
+				Location:  sp.ACSURL,
+				Index:     0,
+				IsDefault: true,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
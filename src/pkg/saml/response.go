@@ -0,0 +1,285 @@
+package saml
+
+import (
+	"errors"
+	"time"
+)
+
+// Response ответ IdP до проверки подписи: его поля нельзя использовать для принятия решений,
+// кроме поиска соответствующего AuthnRequest
+type Response struct {
+	root         *Element
+	ID           string
+	InResponseTo string
+	Issuer       string
+}
+
+// Assertion проверенное утверждение IdP
+type Assertion struct {
+	ID           string
+	Issuer       string
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+	// Attributes значения атрибутов по Name; атрибуты с FriendlyName доступны и по нему
+	Attributes map[string][]string
+	IssuedAt   time.Time
+}
+
+// Attribute возвращает первое значение атрибута
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseResponse декодирует значение SAMLResponse из привязки HTTP-POST
+func ParseResponse(encoded string) (*Response, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, invalidResponse("malformed encoding")
+	}
+
+	root, err := ParseDocument(data)
+	if err != nil {
+		return nil, invalidResponse("malformed xml: %v", err)
+	}
+	if !root.Is(NamespaceProtocol, "Response") {
+		return nil, invalidResponse("unexpected root element %s", root.Local)
+	}
+
+	response := &Response{root: root}
+	response.ID, _ = root.Attr("ID")
+	response.InResponseTo, _ = root.Attr("InResponseTo")
+	if issuer, _ := root.OptionalChildElement(NamespaceAssertion, "Issuer"); issuer != nil {
+		response.Issuer = issuer.Text()
+	}
+
+	return response, nil
+}
+
+// ValidateResponse проверяет ответ IdP на AuthnRequest requestID и возвращает утверждение.
+// Принимаются только ответы на запросы SP: неожиданные (IdP-initiated) ответы отклоняются
+func (sp *ServiceProvider) ValidateResponse(response *Response, idp *IdentityProvider, requestID string) (*Assertion, error) {
+	root := response.root
+	now := sp.now()
+	skew := sp.clockSkew()
+
+	if version, _ := root.Attr("Version"); version != "2.0" {
+		return nil, invalidResponse("unsupported version")
+	}
+	if requestID == "" || response.InResponseTo != requestID {
+		return nil, invalidResponse("response does not match the request")
+	}
+	if destination, ok := root.Attr("Destination"); ok && destination != sp.ACSURL {
+		return nil, invalidResponse("unexpected destination")
+	}
+	if response.Issuer != "" && response.Issuer != idp.EntityID {
+		return nil, invalidResponse("unexpected response issuer")
+	}
+
+	if err := checkStatus(root); err != nil {
+		return nil, err
+	}
+
+	responseSigned := HasSignature(root)
+	if responseSigned {
+		if err := VerifySignature(root, idp.Certificates); err != nil {
+			return nil, invalidResponse("response signature: %v", err)
+		}
+	}
+
+	if len(root.ChildElements(NamespaceAssertion, "EncryptedAssertion")) > 0 {
+		return nil, invalidResponse("encrypted assertions are not supported")
+	}
+	assertionElement, err := root.ChildElement(NamespaceAssertion, "Assertion")
+	if err != nil {
+		return nil, invalidResponse("%v", err)
+	}
+
+	if HasSignature(assertionElement) {
+		if err := VerifySignature(assertionElement, idp.Certificates); err != nil {
+			return nil, invalidResponse("assertion signature: %v", err)
+		}
+	} else if !responseSigned {
+		return nil, invalidResponse("assertion is not signed")
+	}
+
+	return sp.readAssertion(assertionElement, idp, requestID, now, skew)
+}
+
+func checkStatus(root *Element) error {
+	status, err := root.ChildElement(NamespaceProtocol, "Status")
+	if err != nil {
+		return invalidResponse("%v", err)
+	}
+	code, err := status.ChildElement(NamespaceProtocol, "StatusCode")
+	if err != nil {
+		return invalidResponse("%v", err)
+	}
+	if value, _ := code.Attr("Value"); value != statusSuccess {
+		return invalidResponse("idp returned status %s", value)
+	}
+	return nil
+}
+
+func (sp *ServiceProvider) readAssertion(el *Element, idp *IdentityProvider, requestID string, now time.Time, skew time.Duration) (*Assertion, error) {
+	assertion := &Assertion{Attributes: make(map[string][]string)}
+	assertion.ID, _ = el.Attr("ID")
+
+	if issueInstant, ok := el.Attr("IssueInstant"); ok {
+		issuedAt, err := time.Parse(time.RFC3339, issueInstant)
+		if err != nil {
+			return nil, invalidResponse("malformed IssueInstant")
+		}
+		assertion.IssuedAt = issuedAt
+	}
+
+	issuer, err := el.ChildElement(NamespaceAssertion, "Issuer")
+	if err != nil {
+		return nil, invalidResponse("%v", err)
+	}
+	assertion.Issuer = issuer.Text()
+	if assertion.Issuer != idp.EntityID {
+		return nil, invalidResponse("unexpected assertion issuer")
+	}
+
+	if err := sp.checkSubject(el, assertion, requestID, now, skew); err != nil {
+		return nil, err
+	}
+	if err := sp.checkConditions(el, now, skew); err != nil {
+		return nil, err
+	}
+
+	for _, statement := range el.ChildElements(NamespaceAssertion, "AuthnStatement") {
+		if sessionIndex, ok := statement.Attr("SessionIndex"); ok {
+			assertion.SessionIndex = sessionIndex
+		}
+		if value, ok := statement.Attr("SessionNotOnOrAfter"); ok {
+			notOnOrAfter, err := time.Parse(time.RFC3339, value)
+			if err != nil || !now.Add(-skew).Before(notOnOrAfter) {
+				return nil, invalidResponse("idp session has expired")
+			}
+		}
+	}
+
+	for _, statement := range el.ChildElements(NamespaceAssertion, "AttributeStatement") {
+		for _, attribute := range statement.ChildElements(NamespaceAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.ChildElements(NamespaceAssertion, "AttributeValue") {
+				values = append(values, value.Text())
+			}
+			if name, ok := attribute.Attr("Name"); ok {
+				assertion.Attributes[name] = append(assertion.Attributes[name], values...)
+			}
+			if friendlyName, ok := attribute.Attr("FriendlyName"); ok {
+				assertion.Attributes[friendlyName] = append(assertion.Attributes[friendlyName], values...)
+			}
+		}
+	}
+
+	return assertion, nil
+}
+
+func (sp *ServiceProvider) checkSubject(el *Element, assertion *Assertion, requestID string, now time.Time, skew time.Duration) error {
+	subject, err := el.ChildElement(NamespaceAssertion, "Subject")
+	if err != nil {
+		return invalidResponse("%v", err)
+	}
+
+	nameID, err := subject.ChildElement(NamespaceAssertion, "NameID")
+	if err != nil {
+		return invalidResponse("%v", err)
+	}
+	assertion.NameID = nameID.Text()
+	assertion.NameIDFormat, _ = nameID.Attr("Format")
+
+	// Достаточно одного bearer-подтверждения, прошедшего все проверки (SAML Profiles, 4.1.4.2)
+	var lastErr error = errors.New("no bearer subject confirmation")
+	for _, confirmation := range subject.ChildElements(NamespaceAssertion, "SubjectConfirmation") {
+		if method, _ := confirmation.Attr("Method"); method != subjectConfirmationBearer {
+			continue
+		}
+		data, err := confirmation.ChildElement(NamespaceAssertion, "SubjectConfirmationData")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if lastErr = sp.checkConfirmationData(data, requestID, now, skew); lastErr == nil {
+			return nil
+		}
+	}
+	return invalidResponse("subject confirmation: %v", lastErr)
+}
+
+func (sp *ServiceProvider) checkConfirmationData(data *Element, requestID string, now time.Time, skew time.Duration) error {
+	if recipient, _ := data.Attr("Recipient"); recipient != sp.ACSURL {
+		return errors.New("unexpected recipient")
+	}
+	if inResponseTo, ok := data.Attr("InResponseTo"); ok && inResponseTo != requestID {
+		return errors.New("unexpected InResponseTo")
+	}
+	if _, ok := data.Attr("NotBefore"); ok {
+		return errors.New("NotBefore is not allowed for bearer confirmation")
+	}
+
+	value, ok := data.Attr("NotOnOrAfter")
+	if !ok {
+		return errors.New("missing NotOnOrAfter")
+	}
+	notOnOrAfter, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return errors.New("malformed NotOnOrAfter")
+	}
+	if !now.Add(-skew).Before(notOnOrAfter) {
+		return errors.New("subject confirmation has expired")
+	}
+	return nil
+}
+
+func (sp *ServiceProvider) checkConditions(el *Element, now time.Time, skew time.Duration) error {
+	conditions, err := el.ChildElement(NamespaceAssertion, "Conditions")
+	if err != nil {
+		return invalidResponse("%v", err)
+	}
+
+	if value, ok := conditions.Attr("NotBefore"); ok {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return invalidResponse("malformed NotBefore")
+		}
+		if now.Add(skew).Before(notBefore) {
+			return invalidResponse("assertion is not yet valid")
+		}
+	}
+	if value, ok := conditions.Attr("NotOnOrAfter"); ok {
+		notOnOrAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return invalidResponse("malformed NotOnOrAfter")
+		}
+		if !now.Add(-skew).Before(notOnOrAfter) {
+			return invalidResponse("assertion has expired")
+		}
+	}
+
+	// Каждое AudienceRestriction должно включать нас; хотя бы одно обязательно
+	restrictions := conditions.ChildElements(NamespaceAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return invalidResponse("missing audience restriction")
+	}
+	for _, restriction := range restrictions {
+		allowed := false
+		for _, audience := range restriction.ChildElements(NamespaceAssertion, "Audience") {
+			if audience.Text() == sp.EntityID {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return invalidResponse("assertion is intended for another audience")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,549 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSPEntityID  = "https://sp.example.com/saml/metadata"
+	testACSURL      = "https://sp.example.com/saml/acs"
+	testIdPEntityID = "https://idp.example.com/metadata"
+	testRequestID   = "_request"
+	testNameID      = "alice@example.com"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testIdP локальная замена IdP: выпускает ответы, подписанные собственным ключом
+type testIdP struct {
+	t           *testing.T
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    testNow.Add(-time.Hour),
+		NotAfter:     testNow.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testIdP{t: t, key: key, certificate: certificate}
+}
+
+func (idp *testIdP) identityProvider() *IdentityProvider {
+	return &IdentityProvider{
+		EntityID:     testIdPEntityID,
+		Certificates: []*x509.Certificate{idp.certificate},
+	}
+}
+
+// responseParams содержимое ответа, которое тест может исказить до подписи
+type responseParams struct {
+	responseID   string
+	assertionID  string
+	inResponseTo string
+	nameID       string
+	audience     string
+	recipient    string
+	notOnOrAfter time.Time
+	// signResponse и signAssertion какие элементы подписывает IdP
+	signResponse  bool
+	signAssertion bool
+	// referenceURI и transforms подменяют ссылку и преобразования в подписи;
+	// подпись при этом вычисляется по элементу как обычно и остается криптографически верной
+	referenceURI string
+	transforms   []string
+}
+
+func validParams() responseParams {
+	return responseParams{
+		responseID:    "_response",
+		assertionID:   "_assertion",
+		inResponseTo:  testRequestID,
+		nameID:        testNameID,
+		audience:      testSPEntityID,
+		recipient:     testACSURL,
+		notOnOrAfter:  testNow.Add(5 * time.Minute),
+		signAssertion: true,
+	}
+}
+
+func assertionXML(p responseParams) string {
+	issueInstant := testNow.Format(time.RFC3339)
+	notOnOrAfter := p.notOnOrAfter.Format(time.RFC3339)
+
+	return `<saml:Assertion ID="` + p.assertionID + `" Version="2.0" IssueInstant="` + issueInstant + `">` +
+		`<saml:Issuer>` + testIdPEntityID + `</saml:Issuer>` + signatureMarker(p.assertionID) +
+		`<saml:Subject>` +
+		`<saml:NameID Format="` + NameIDFormatEmail + `">` + p.nameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="` + subjectConfirmationBearer + `">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + p.inResponseTo + `" Recipient="` + p.recipient +
+		`" NotOnOrAfter="` + notOnOrAfter + `"/>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + testNow.Add(-time.Minute).Format(time.RFC3339) + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + p.audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AuthnStatement AuthnInstant="` + issueInstant + `" SessionIndex="_session"/>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="urn:oid:2.5.4.42" FriendlyName="givenName"><saml:AttributeValue>Alice</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+func responseXML(p responseParams, assertion string) string {
+	return `<samlp:Response xmlns:samlp="` + NamespaceProtocol + `" xmlns:saml="` + NamespaceAssertion + `"` +
+		` ID="` + p.responseID + `" Version="2.0" IssueInstant="` + testNow.Format(time.RFC3339) + `"` +
+		` Destination="` + testACSURL + `" InResponseTo="` + p.inResponseTo + `">` +
+		`<saml:Issuer>` + testIdPEntityID + `</saml:Issuer>` + signatureMarker(p.responseID) +
+		`<samlp:Status><samlp:StatusCode Value="` + statusSuccess + `"/></samlp:Status>` +
+		assertion +
+		`</samlp:Response>`
+}
+
+// issue выпускает документ ответа: сначала подписывается утверждение, затем ответ целиком
+func (idp *testIdP) issue(p responseParams) string {
+	doc := responseXML(p, assertionXML(p))
+	if p.signAssertion {
+		doc = idp.sign(doc, p, p.assertionID)
+	}
+	if p.signResponse {
+		doc = idp.sign(doc, p, p.responseID)
+	}
+	return stripSignatureMarkers(doc)
+}
+
+func signatureMarker(id string) string {
+	return "{{signature:" + id + "}}"
+}
+
+func stripSignatureMarkers(doc string) string {
+	for {
+		start := strings.Index(doc, "{{signature:")
+		if start < 0 {
+			return doc
+		}
+		end := strings.Index(doc[start:], "}}")
+		doc = doc[:start] + doc[start+end+2:]
+	}
+}
+
+func signatureXML(p responseParams, id, digest, value string) string {
+	uri := p.referenceURI
+	if uri == "" {
+		uri = "#" + id
+	}
+	algorithms := p.transforms
+	if algorithms == nil {
+		algorithms = []string{AlgorithmEnvelopedSignature, AlgorithmExcC14N}
+	}
+	transforms := ""
+	for _, algorithm := range algorithms {
+		transforms += `<ds:Transform Algorithm="` + algorithm + `"/>`
+	}
+
+	return `<ds:Signature xmlns:ds="` + NamespaceDSig + `">` +
+		`<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="` + AlgorithmExcC14N + `"/>` +
+		`<ds:SignatureMethod Algorithm="` + AlgorithmRSASHA256 + `"/>` +
+		`<ds:Reference URI="` + uri + `">` +
+		`<ds:Transforms>` + transforms + `</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="` + AlgorithmSHA256 + `"/>` +
+		`<ds:DigestValue>` + digest + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>` +
+		`<ds:SignatureValue>` + value + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+}
+
+// sign вставляет enveloped-подпись элемента id на место его маркера
+func (idp *testIdP) sign(doc string, p responseParams, id string) string {
+	idp.t.Helper()
+
+	marker := signatureMarker(id)
+	if !strings.Contains(doc, marker) {
+		idp.t.Fatalf("no signature marker for %s", id)
+	}
+
+	el, signature := idp.signedElement(strings.Replace(doc, marker, signatureXML(p, id, "", ""), 1), id)
+	digest := sha256.Sum256(canonicalize(el, signature, nil))
+	digestValue := base64.StdEncoding.EncodeToString(digest[:])
+
+	_, signature = idp.signedElement(strings.Replace(doc, marker, signatureXML(p, id, digestValue, ""), 1), id)
+	signedInfo, err := signature.ChildElement(NamespaceDSig, "SignedInfo")
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	hashed := sha256.Sum256(canonicalize(signedInfo, nil, nil))
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+
+	return strings.Replace(doc, marker, signatureXML(p, id, digestValue, base64.StdEncoding.EncodeToString(value)), 1)
+}
+
+// signedElement разбирает документ без маркеров и находит элемент id и его подпись
+func (idp *testIdP) signedElement(doc, id string) (*Element, *Element) {
+	idp.t.Helper()
+
+	root, err := ParseDocument([]byte(stripSignatureMarkers(doc)))
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	el := findByID(root, id)
+	if el == nil {
+		idp.t.Fatalf("element %s not found", id)
+	}
+	signature, err := el.ChildElement(NamespaceDSig, "Signature")
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return el, signature
+}
+
+func findByID(el *Element, id string) *Element {
+	if value, ok := el.Attr("ID"); ok && value == id {
+		return el
+	}
+	for _, child := range el.Children {
+		if c, ok := child.(*Element); ok {
+			if found := findByID(c, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// replaceOnce заменяет первое вхождение old и проверяет, что оно было
+func replaceOnce(t *testing.T, doc, old, replacement string) string {
+	t.Helper()
+
+	if !strings.Contains(doc, old) {
+		t.Fatalf("%q not found in document", old)
+	}
+	return strings.Replace(doc, old, replacement, 1)
+}
+
+// signedAssertion вырезает из документа подписанное утверждение
+func signedAssertion(t *testing.T, doc string) string {
+	t.Helper()
+
+	start := strings.Index(doc, "<saml:Assertion")
+	end := strings.Index(doc, "</saml:Assertion>")
+	if start < 0 || end < 0 {
+		t.Fatal("assertion not found")
+	}
+	return doc[start : end+len("</saml:Assertion>")]
+}
+
+func TestValidateResponse(t *testing.T) {
+	idp := newTestIdP(t)
+
+	tests := []struct {
+		name   string
+		modify func(p *responseParams)
+		// tamper изменяет подписанный документ так, как это мог бы сделать атакующий
+		tamper    func(t *testing.T, doc string) string
+		requestID string
+		// wantErr причина отказа; пустая строка - ответ принимается
+		wantErr string
+		// wantParseErr причина отказа при разборе документа
+		wantParseErr string
+		wantNameID   string
+	}{
+		{name: "signed assertion"},
+		{
+			name:   "signed response",
+			modify: func(p *responseParams) { p.signResponse, p.signAssertion = true, false },
+		},
+		{
+			name:   "signed response and assertion",
+			modify: func(p *responseParams) { p.signResponse = true },
+		},
+		{
+			name:    "unsigned",
+			modify:  func(p *responseParams) { p.signAssertion = false },
+			wantErr: "assertion is not signed",
+		},
+		{
+			name: "untrusted key",
+			tamper: func(t *testing.T, _ string) string {
+				return newTestIdP(t).issue(validParams())
+			},
+			wantErr: "signature does not match any trusted certificate",
+		},
+		{
+			name: "duplicated assertion",
+			tamper: func(t *testing.T, doc string) string {
+				evil := validParams()
+				evil.nameID = "admin@example.com"
+				return replaceOnce(t, doc, "</samlp:Response>", stripSignatureMarkers(assertionXML(evil))+"</samlp:Response>")
+			},
+			wantErr: "multiple Assertion elements",
+		},
+		{
+			name: "moved assertion",
+			tamper: func(t *testing.T, doc string) string {
+				original := signedAssertion(t, doc)
+				evil := validParams()
+				evil.nameID = "admin@example.com"
+				wrapped := `<samlp:Extensions>` + original + `</samlp:Extensions>` + stripSignatureMarkers(assertionXML(evil))
+				return replaceOnce(t, doc, original, wrapped)
+			},
+			wantErr: "assertion is not signed",
+		},
+		{
+			name: "moved assertion with copied signature",
+			tamper: func(t *testing.T, doc string) string {
+				original := signedAssertion(t, doc)
+				evil := strings.Replace(original, testNameID, "admin@example.com", 1)
+				return replaceOnce(t, doc, original, `<samlp:Extensions>`+original+`</samlp:Extensions>`+evil)
+			},
+			wantErr: "digest mismatch",
+		},
+		{
+			name: "wrapped assertion",
+			tamper: func(t *testing.T, doc string) string {
+				// Подписанное утверждение спрятано внутри поддельного с тем же ID
+				original := signedAssertion(t, doc)
+				evil := validParams()
+				evil.nameID = "admin@example.com"
+				wrapper := replaceOnce(t, stripSignatureMarkers(assertionXML(evil)), "<saml:Subject>",
+					"<saml:Advice>"+original+"</saml:Advice><saml:Subject>")
+				return replaceOnce(t, doc, original, wrapper)
+			},
+			wantErr: "assertion is not signed",
+		},
+		{
+			name: "wrapped assertion with copied signature",
+			tamper: func(t *testing.T, doc string) string {
+				original := signedAssertion(t, doc)
+				start := strings.Index(original, "<ds:Signature")
+				end := strings.Index(original, "</ds:Signature>") + len("</ds:Signature>")
+				evil := validParams()
+				evil.nameID = "admin@example.com"
+				wrapper := replaceOnce(t, stripSignatureMarkers(assertionXML(evil)), "<saml:Subject>",
+					original[start:end]+"<saml:Advice>"+original+"</saml:Advice><saml:Subject>")
+				return replaceOnce(t, doc, original, wrapper)
+			},
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "reference to another element",
+			modify:  func(p *responseParams) { p.referenceURI = "#_response" },
+			wantErr: "signature reference does not point to the signed element",
+		},
+		{
+			name:    "reference to the whole document",
+			modify:  func(p *responseParams) { p.referenceURI = " " },
+			wantErr: "signature reference does not point to the signed element",
+		},
+		{
+			name: "signed element id changed",
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, `<saml:Assertion ID="_assertion"`, `<saml:Assertion ID="_evil"`)
+			},
+			wantErr: "signature reference does not point to the signed element",
+		},
+		{
+			name: "extra reference",
+			tamper: func(t *testing.T, doc string) string {
+				start := strings.Index(doc, "<ds:Reference")
+				end := strings.Index(doc, "</ds:Reference>") + len("</ds:Reference>")
+				return replaceOnce(t, doc, "</ds:SignedInfo>", doc[start:end]+"</ds:SignedInfo>")
+			},
+			wantErr: "multiple Reference elements",
+		},
+		{
+			name:    "missing enveloped signature transform",
+			modify:  func(p *responseParams) { p.transforms = []string{AlgorithmExcC14N} },
+			wantErr: "unexpected transforms",
+		},
+		{
+			name:    "missing canonicalization transform",
+			modify:  func(p *responseParams) { p.transforms = []string{AlgorithmEnvelopedSignature} },
+			wantErr: "unexpected transforms",
+		},
+		{
+			name:    "no transforms",
+			modify:  func(p *responseParams) { p.transforms = []string{} },
+			wantErr: "unexpected transforms",
+		},
+		{
+			name: "extra transform",
+			modify: func(p *responseParams) {
+				p.transforms = []string{AlgorithmEnvelopedSignature, AlgorithmExcC14N, AlgorithmExcC14N}
+			},
+			wantErr: "unexpected transforms",
+		},
+		{
+			name: "unsupported transform",
+			modify: func(p *responseParams) {
+				p.transforms = []string{AlgorithmEnvelopedSignature, "http://www.w3.org/TR/1999/REC-xpath-19991116", AlgorithmExcC14N}
+			},
+			wantErr: "unexpected transforms",
+		},
+		{
+			name: "whitespace injected into name id",
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, ">"+testNameID+"<", "> "+testNameID+"\n<")
+			},
+			wantErr: "digest mismatch",
+		},
+		{
+			name: "whitespace injected between signed elements",
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, "</saml:Subject>", "</saml:Subject>\n")
+			},
+			wantErr: "digest mismatch",
+		},
+		{
+			name: "whitespace injected into signed info",
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, "<ds:SignatureMethod", "\n<ds:SignatureMethod")
+			},
+			wantErr: "signature does not match any trusted certificate",
+		},
+		{
+			name:   "modified signed response",
+			modify: func(p *responseParams) { p.signResponse, p.signAssertion = true, false },
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, testNameID, "admin@example.com")
+			},
+			wantErr: "digest mismatch",
+		},
+		{
+			name:   "comment injected into name id",
+			modify: func(p *responseParams) { p.nameID = "admin@example.com.evil.example" },
+			tamper: func(t *testing.T, doc string) string {
+				// Комментарии не входят в канонический вид, и подпись осталась бы верной
+				return replaceOnce(t, doc, "admin@example.com.evil.example", "admin@example.com<!---->.evil.example")
+			},
+			wantParseErr: "xml comments are not allowed",
+		},
+		{
+			name: "comment injected between signed elements",
+			tamper: func(t *testing.T, doc string) string {
+				return replaceOnce(t, doc, "</saml:Subject>", "</saml:Subject><!-- -->")
+			},
+			wantParseErr: "xml comments are not allowed",
+		},
+		{
+			name:    "wrong audience",
+			modify:  func(p *responseParams) { p.audience = "https://other.example.com/saml/metadata" },
+			wantErr: "assertion is intended for another audience",
+		},
+		{
+			name:    "wrong recipient",
+			modify:  func(p *responseParams) { p.recipient = "https://other.example.com/saml/acs" },
+			wantErr: "unexpected recipient",
+		},
+		{
+			name:    "expired",
+			modify:  func(p *responseParams) { p.notOnOrAfter = testNow.Add(-10 * time.Minute) },
+			wantErr: "has expired",
+		},
+		{
+			name:      "replayed for another request",
+			requestID: "_another_request",
+			wantErr:   "response does not match the request",
+		},
+		{
+			name: "replayed with rewritten InResponseTo",
+			tamper: func(t *testing.T, doc string) string {
+				// Подписано только утверждение: атрибут ответа можно переписать, но не подтверждение субъекта
+				return replaceOnce(t, doc, `Destination="`+testACSURL+`" InResponseTo="`+testRequestID+`"`,
+					`Destination="`+testACSURL+`" InResponseTo="_another_request"`)
+			},
+			requestID: "_another_request",
+			wantErr:   "unexpected InResponseTo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validParams()
+			if tt.modify != nil {
+				tt.modify(&p)
+			}
+			doc := idp.issue(p)
+			if tt.tamper != nil {
+				doc = tt.tamper(t, doc)
+			}
+			requestID := tt.requestID
+			if requestID == "" {
+				requestID = testRequestID
+			}
+
+			sp := &ServiceProvider{
+				EntityID: testSPEntityID,
+				ACSURL:   testACSURL,
+				Now:      func() time.Time { return testNow },
+			}
+
+			response, err := ParseResponse(base64.StdEncoding.EncodeToString([]byte(doc)))
+			if tt.wantParseErr != "" {
+				if !errors.Is(err, ErrInvalidResponse) || !strings.Contains(err.Error(), tt.wantParseErr) {
+					t.Fatalf("expected invalid response %q, got %v", tt.wantParseErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse response: %v", err)
+			}
+			assertion, err := sp.ValidateResponse(response, idp.identityProvider(), requestID)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidResponse) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected invalid response %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			wantNameID := tt.wantNameID
+			if wantNameID == "" {
+				wantNameID = p.nameID
+			}
+			if assertion.NameID != wantNameID {
+				t.Errorf("name id = %q, want %q", assertion.NameID, wantNameID)
+			}
+			if assertion.Issuer != testIdPEntityID {
+				t.Errorf("issuer = %q, want %q", assertion.Issuer, testIdPEntityID)
+			}
+			if got := assertion.Attribute("givenName"); got != "Alice" {
+				t.Errorf("givenName = %q, want %q", got, "Alice")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Форматы NameID и привязки SAML
+const (
+	NameIDFormatEmail = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	BindingHTTPPost   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	BindingRedirect   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+
+	statusSuccess             = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	subjectConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	defaultClockSkew          = 3 * time.Minute
+	requestIDSize             = 20
+)
+
+// ErrInvalidResponse ответ IdP не прошел проверку
+var ErrInvalidResponse = errors.New("invalid saml response")
+
+// ServiceProvider параметры нашей стороны (SP)
+type ServiceProvider struct {
+	// EntityID идентификатор SP, он же ожидаемый Audience в утверждениях
+	EntityID string
+	// ACSURL адрес Assertion Consumer Service, куда IdP отправляет ответ
+	ACSURL string
+	// ClockSkew допустимое расхождение часов с IdP
+	ClockSkew time.Duration
+	// Now источник текущего времени; nil - time.Now
+	Now func() time.Time
+}
+
+// IdentityProvider параметры доверенного IdP
+type IdentityProvider struct {
+	EntityID string
+	// SSOURL адрес приема AuthnRequest (HTTP-Redirect)
+	SSOURL string
+	// Certificates сертификаты подписи; несколько - на время ротации ключей
+	Certificates []*x509.Certificate
+}
+
+// ParseCertificates разбирает один или несколько PEM-сертификатов
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certificates, nil
+}
+
+// NewRequestID генерирует идентификатор AuthnRequest.
+// Значение должно быть xs:ID, поэтому начинается с подчеркивания
+func NewRequestID() (string, error) {
+	b := make([]byte, requestIDSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
+
+func (sp *ServiceProvider) now() time.Time {
+	if sp.Now != nil {
+		return sp.Now()
+	}
+	return time.Now()
+}
+
+func (sp *ServiceProvider) clockSkew() time.Duration {
+	if sp.ClockSkew > 0 {
+		return sp.ClockSkew
+	}
+	return defaultClockSkew
+}
+
+func invalidResponse(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,213 @@
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	// Регистрация хэш-функций для crypto.Hash
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Поддерживаемые алгоритмы подписи и хэширования. SHA-1 намеренно не поддерживается
+const (
+	AlgorithmRSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	AlgorithmRSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	AlgorithmECDSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	AlgorithmSHA256      = "http://www.w3.org/2001/04/xmlenc#sha256"
+	AlgorithmSHA512      = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+var signatureHashes = map[string]crypto.Hash{
+	AlgorithmRSASHA256:   crypto.SHA256,
+	AlgorithmRSASHA512:   crypto.SHA512,
+	AlgorithmECDSASHA256: crypto.SHA256,
+}
+
+var digestHashes = map[string]crypto.Hash{
+	AlgorithmSHA256: crypto.SHA256,
+	AlgorithmSHA512: crypto.SHA512,
+}
+
+// ErrNoSignature элемент не содержит подписи
+var ErrNoSignature = errors.New("element is not signed")
+
+// HasSignature проверяет, есть ли у элемента собственная подпись
+func HasSignature(el *Element) bool {
+	return len(el.ChildElements(NamespaceDSig, "Signature")) > 0
+}
+
+// VerifySignature проверяет enveloped-подпись элемента одним из сертификатов IdP.
+// Подпись должна ссылаться именно на этот элемент (по атрибуту ID) и покрывать его целиком,
+// иначе подписанный фрагмент можно было бы подменить (XML signature wrapping)
+func VerifySignature(el *Element, certificates []*x509.Certificate) error {
+	signatures := el.ChildElements(NamespaceDSig, "Signature")
+	if len(signatures) == 0 {
+		return ErrNoSignature
+	}
+	if len(signatures) > 1 {
+		return errors.New("multiple signatures")
+	}
+	signature := signatures[0]
+
+	signedInfo, err := signature.ChildElement(NamespaceDSig, "SignedInfo")
+	if err != nil {
+		return err
+	}
+
+	c14nMethod, err := signedInfo.ChildElement(NamespaceDSig, "CanonicalizationMethod")
+	if err != nil {
+		return err
+	}
+	if algorithm, _ := c14nMethod.Attr("Algorithm"); algorithm != AlgorithmExcC14N {
+		return fmt.Errorf("unsupported canonicalization method %q", algorithm)
+	}
+
+	signatureMethod, err := signedInfo.ChildElement(NamespaceDSig, "SignatureMethod")
+	if err != nil {
+		return err
+	}
+	signatureAlgorithm, _ := signatureMethod.Attr("Algorithm")
+	signatureHash, ok := signatureHashes[signatureAlgorithm]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %q", signatureAlgorithm)
+	}
+
+	if err := verifyReference(el, signature, signedInfo); err != nil {
+		return err
+	}
+
+	signatureValue, err := signature.ChildElement(NamespaceDSig, "SignatureValue")
+	if err != nil {
+		return err
+	}
+	rawSignature, err := decodeBase64(signatureValue.Text())
+	if err != nil {
+		return errors.New("malformed signature value")
+	}
+
+	h := signatureHash.New()
+	h.Write(canonicalize(signedInfo, nil, inclusiveNamespaces(c14nMethod)))
+	hashed := h.Sum(nil)
+
+	for _, certificate := range certificates {
+		if verifyWithKey(certificate.PublicKey, signatureAlgorithm, signatureHash, hashed, rawSignature) {
+			return nil
+		}
+	}
+	return errors.New("signature does not match any trusted certificate")
+}
+
+// verifyReference проверяет, что подпись содержит единственную ссылку на el и ее хэш совпадает
+func verifyReference(el, signature, signedInfo *Element) error {
+	reference, err := signedInfo.ChildElement(NamespaceDSig, "Reference")
+	if err != nil {
+		return err
+	}
+
+	id, ok := el.Attr("ID")
+	if !ok || id == "" {
+		return errors.New("signed element has no ID")
+	}
+	if uri, _ := reference.Attr("URI"); uri != "#"+id {
+		return errors.New("signature reference does not point to the signed element")
+	}
+
+	// Профиль SAML допускает только enveloped-signature и exclusive c14n: любой другой набор
+	// преобразований меняет то, что на самом деле покрыто подписью
+	transforms, err := reference.ChildElement(NamespaceDSig, "Transforms")
+	if err != nil {
+		return err
+	}
+	transformList := transforms.ChildElements(NamespaceDSig, "Transform")
+	if len(transformList) != 2 {
+		return errors.New("unexpected transforms")
+	}
+	if algorithm, _ := transformList[0].Attr("Algorithm"); algorithm != AlgorithmEnvelopedSignature {
+		return errors.New("unexpected transforms")
+	}
+	if algorithm, _ := transformList[1].Attr("Algorithm"); algorithm != AlgorithmExcC14N {
+		return errors.New("unexpected transforms")
+	}
+	prefixes := inclusiveNamespaces(transformList[1])
+
+	digestMethod, err := reference.ChildElement(NamespaceDSig, "DigestMethod")
+	if err != nil {
+		return err
+	}
+	digestAlgorithm, _ := digestMethod.Attr("Algorithm")
+	digestHash, ok := digestHashes[digestAlgorithm]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %q", digestAlgorithm)
+	}
+
+	digestValue, err := reference.ChildElement(NamespaceDSig, "DigestValue")
+	if err != nil {
+		return err
+	}
+	expected, err := decodeBase64(digestValue.Text())
+	if err != nil {
+		return errors.New("malformed digest value")
+	}
+
+	h := digestHash.New()
+	h.Write(canonicalize(el, signature, prefixes))
+	if subtle.ConstantTimeCompare(h.Sum(nil), expected) != 1 {
+		return errors.New("digest mismatch")
+	}
+	return nil
+}
+
+// inclusiveNamespaces читает PrefixList из ec:InclusiveNamespaces
+func inclusiveNamespaces(el *Element) []string {
+	for _, child := range el.ChildElements(AlgorithmExcC14N, "InclusiveNamespaces") {
+		if list, ok := child.Attr("PrefixList"); ok {
+			return strings.Fields(list)
+		}
+	}
+	return nil
+}
+
+func verifyWithKey(publicKey crypto.PublicKey, algorithm string, hash crypto.Hash, hashed, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if algorithm == AlgorithmECDSASHA256 {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(key, hash, hashed, signature) == nil
+	case *ecdsa.PublicKey:
+		if algorithm != AlgorithmECDSASHA256 {
+			return false
+		}
+		// XML-DSig кодирует подпись ECDSA как r||s фиксированной длины
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, hashed, r, s)
+	default:
+		return false
+	}
+}
+
+// decodeBase64 декодирует base64 с переносами строк, которые IdP обычно вставляют в значения
+func decodeBase64(value string) ([]byte, error) {
+	var compact bytes.Buffer
+	for _, r := range value {
+		if r != ' ' && r != '\n' && r != '\r' && r != '\t' {
+			compact.WriteRune(r)
+		}
+	}
+	return base64.StdEncoding.DecodeString(compact.String())
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNotFound объект с указанным ключом не найден
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey ключ пустой или выходит за пределы хранилища
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage хранилище файлов. Ключ - относительный путь с разделителем "/", например "avatars/<id>/64.jpg"
+type Storage interface {
+	// Put сохраняет объект; существующий объект с тем же ключом перезаписывается
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; для отсутствующего объекта возвращает ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается
+	Delete(ctx context.Context, key string) error
+	// URL возвращает публичный адрес объекта
+	URL(key string) string
+}
+
+// CleanKey проверяет ключ и приводит его к каноническому виду
+func CleanKey(key string) (string, error) {
+	if key == "" || strings.ContainsRune(key, '\\') || strings.ContainsRune(key, 0) {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
+
+// joinURL склеивает базовый адрес и ключ объекта
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
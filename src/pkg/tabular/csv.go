@@ -0,0 +1,75 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// utf8BOM метка порядка байт, которую добавляет Excel при сохранении CSV в UTF-8
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readCSV читает CSV с автоопределением разделителя (запятая, точка с запятой или табуляция)
+func readCSV(data []byte, opts Options) ([]Row, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows []Row
+	nonEmpty := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := Row{Line: line, Values: record}
+		if !row.IsEmpty() {
+			nonEmpty++
+			// Заголовок плюс MaxRows строк данных
+			if opts.MaxRows > 0 && nonEmpty > opts.MaxRows+1 {
+				return nil, ErrTooManyRows
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// detectDelimiter выбирает самый частый разделитель в первой строке вне кавычек
+func detectDelimiter(data []byte) rune {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+
+	candidates := []rune{',', ';', '\t'}
+	counts := make(map[rune]int, len(candidates))
+	quoted := false
+	for _, r := range string(data) {
+		if r == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted {
+			counts[r]++
+		}
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if counts[candidate] > counts[best] {
+			best = candidate
+		}
+	}
+	return best
+}
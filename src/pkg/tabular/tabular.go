@@ -0,0 +1,150 @@
+package tabular
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Format формат табличного файла
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var (
+	// ErrUnsupportedFormat файл не является CSV или XLSX
+	ErrUnsupportedFormat = errors.New("unsupported table format")
+	// ErrEmpty в файле нет строки заголовков
+	ErrEmpty = errors.New("table is empty")
+	// ErrTooManyRows превышен лимит строк
+	ErrTooManyRows = errors.New("too many rows")
+	// ErrInvalidXLSX повреждённый или неподдерживаемый XLSX-файл
+	ErrInvalidXLSX = errors.New("invalid xlsx file")
+)
+
+// zipMagic сигнатура ZIP-архива, в котором хранится XLSX
+var zipMagic = []byte("PK\x03\x04")
+
+// Options ограничения чтения
+type Options struct {
+	// MaxRows максимальное число строк данных (без заголовка); 0 - без ограничений
+	MaxRows int
+}
+
+// Row строка данных таблицы
+type Row struct {
+	// Line номер строки в исходном файле, начиная с 1 (заголовок - строка 1)
+	Line   int
+	Values []string
+}
+
+// Get возвращает значение ячейки по индексу колонки или пустую строку
+func (r Row) Get(index int) string {
+	if index < 0 || index >= len(r.Values) {
+		return ""
+	}
+	return r.Values[index]
+}
+
+// IsEmpty проверяет, что все ячейки строки пустые
+func (r Row) IsEmpty() bool {
+	for _, value := range r.Values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Table прочитанная таблица: первая непустая строка считается заголовком
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Index возвращает индекс колонки по имени заголовка без учёта регистра и пробелов, или -1
+func (t *Table) Index(name string) int {
+	name = normalizeHeader(name)
+	for i, header := range t.Header {
+		if normalizeHeader(header) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DetectFormat определяет формат по содержимому и, если это не удалось, по расширению файла
+func DetectFormat(filename string, data []byte) (Format, error) {
+	if bytes.HasPrefix(data, zipMagic) {
+		return FormatXLSX, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// Read читает таблицу из содержимого файла указанного формата
+func Read(data []byte, format Format, opts Options) (*Table, error) {
+	var (
+		rows []Row
+		err  error
+	)
+
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(data, opts)
+	case FormatXLSX:
+		rows, err = readXLSX(data, opts)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newTable(rows, opts)
+}
+
+// newTable отделяет заголовок от данных и пропускает пустые строки
+func newTable(rows []Row, opts Options) (*Table, error) {
+	table := &Table{}
+
+	for _, row := range rows {
+		if row.IsEmpty() {
+			continue
+		}
+
+		if table.Header == nil {
+			table.Header = make([]string, len(row.Values))
+			for j, value := range row.Values {
+				table.Header[j] = strings.TrimSpace(value)
+			}
+			continue
+		}
+
+		if opts.MaxRows > 0 && len(table.Rows) >= opts.MaxRows {
+			return nil, ErrTooManyRows
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	if table.Header == nil {
+		return nil, ErrEmpty
+	}
+
+	return table, nil
+}
+
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,324 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxXLSXPartSize ограничение распакованного размера одной части архива (защита от zip-бомб)
+	maxXLSXPartSize = 64 << 20
+	// maxXLSXColumns ограничение числа колонок в строке
+	maxXLSXColumns = 1024
+)
+
+// readXLSX читает первый лист книги XLSX (Office Open XML)
+func readXLSX(data []byte, opts Options) ([]Row, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[strings.TrimPrefix(file.Name, "/")] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings []string
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if sharedStrings, err = readSharedStrings(file); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: sheet %s not found", ErrInvalidXLSX, sheetPath)
+	}
+
+	return readSheet(sheet, sharedStrings, opts)
+}
+
+// firstSheetPath находит путь к первому листу через workbook.xml и его связи
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no sheets", ErrInvalidXLSX)
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		// Путь задаётся относительно xl/ или абсолютно от корня пакета
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Clean(path.Join("xl", rel.Target)), nil
+	}
+
+	return "", fmt.Errorf("%w: sheet relationship not found", ErrInvalidXLSX)
+}
+
+// readSharedStrings читает таблицу общих строк; форматированный текст склеивается из фрагментов
+func readSharedStrings(file *zip.File) ([]string, error) {
+	rc, err := openPart(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&sst); err != nil {
+		return nil, fmt.Errorf("%w: shared strings: %v", ErrInvalidXLSX, err)
+	}
+
+	result := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		if len(item.Runs) == 0 {
+			result[i] = item.Text
+			continue
+		}
+		var b strings.Builder
+		for _, run := range item.Runs {
+			b.WriteString(run.Text)
+		}
+		result[i] = b.String()
+	}
+
+	return result, nil
+}
+
+// xlsxCell ячейка листа
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+// xlsxRow строка листа
+type xlsxRow struct {
+	Ref   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+// readSheet потоково читает строки листа
+func readSheet(file *zip.File, sharedStrings []string, opts Options) ([]Row, error) {
+	rc, err := openPart(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	var rows []Row
+	nonEmpty := 0
+	line := 0
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: sheet: %v", ErrInvalidXLSX, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("%w: sheet row: %v", ErrInvalidXLSX, err)
+		}
+
+		// Атрибут r необязателен: без него строки идут подряд
+		if row.Ref > 0 {
+			line = row.Ref
+		} else {
+			line++
+		}
+
+		values, err := rowValues(row, sharedStrings)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed := Row{Line: line, Values: values}
+		if parsed.IsEmpty() {
+			continue
+		}
+		nonEmpty++
+		if opts.MaxRows > 0 && nonEmpty > opts.MaxRows+1 {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, parsed)
+	}
+
+	return rows, nil
+}
+
+// rowValues раскладывает ячейки строки по колонкам с учётом пропущенных ячеек
+func rowValues(row xlsxRow, sharedStrings []string) ([]string, error) {
+	var values []string
+	next := 0
+
+	for _, cell := range row.Cells {
+		column := next
+		if cell.Ref != "" {
+			parsed, ok := columnIndex(cell.Ref)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid cell reference %q", ErrInvalidXLSX, cell.Ref)
+			}
+			column = parsed
+		}
+		if column >= maxXLSXColumns {
+			return nil, fmt.Errorf("%w: too many columns", ErrInvalidXLSX)
+		}
+
+		value, err := cellValue(cell, sharedStrings)
+		if err != nil {
+			return nil, err
+		}
+
+		for len(values) <= column {
+			values = append(values, "")
+		}
+		values[column] = value
+		next = column + 1
+	}
+
+	return values, nil
+}
+
+// cellValue возвращает текстовое значение ячейки
+func cellValue(cell xlsxCell, sharedStrings []string) (string, error) {
+	switch cell.Type {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return "", fmt.Errorf("%w: invalid shared string index in %s", ErrInvalidXLSX, cell.Ref)
+		}
+		return sharedStrings[index], nil
+	case "inlineStr":
+		if len(cell.Inline.Runs) == 0 {
+			return cell.Inline.Text, nil
+		}
+		var b strings.Builder
+		for _, run := range cell.Inline.Runs {
+			b.WriteString(run.Text)
+		}
+		return b.String(), nil
+	case "b":
+		if strings.TrimSpace(cell.Value) == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "e":
+		// Ошибки формул (#N/A, #DIV/0!) не считаются данными
+		return "", nil
+	default:
+		// Числа, строки формул (str) и даты (числа с форматом) возвращаются как записаны
+		return cell.Value, nil
+	}
+}
+
+// columnIndex переводит ссылку на ячейку (например, "AB12") в индекс колонки с нуля
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			index = index*26 + int(r-'A'+1)
+			letters++
+			if letters > 3 {
+				return 0, false
+			}
+			continue
+		}
+		break
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+func decodePart(files map[string]*zip.File, name string, v any) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s not found", ErrInvalidXLSX, name)
+	}
+
+	rc, err := openPart(file)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidXLSX, name, err)
+	}
+	return nil
+}
+
+// openPart открывает часть архива с ограничением распакованного размера
+func openPart(file *zip.File) (io.ReadCloser, error) {
+	if file.UncompressedSize64 > maxXLSXPartSize {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidXLSX, file.Name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, maxXLSXPartSize), rc}, nil
+}
@@ -0,0 +1,86 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Флаги authenticator data (WebAuthn Level 3, раздел 6.1)
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagBackupEligible         byte = 0x08
+	FlagBackupState            byte = 0x10
+	FlagAttestedCredentialData byte = 0x40
+	FlagExtensionData          byte = 0x80
+)
+
+// minAuthenticatorDataLength rpIdHash (32) + flags (1) + signCount (4)
+const minAuthenticatorDataLength = 37
+
+// AuthenticatorData разобранные данные аутентификатора
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// Заполняются только при регистрации (флаг AT)
+	AAGUID              []byte
+	CredentialID        []byte
+	CredentialPublicKey []byte
+}
+
+// HasFlag проверяет, установлен ли флаг
+func (d *AuthenticatorData) HasFlag(flag byte) bool {
+	return d.Flags&flag != 0
+}
+
+// ParseAuthenticatorData разбирает authenticator data
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < minAuthenticatorDataLength {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	result := &AuthenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[minAuthenticatorDataLength:]
+
+	if result.HasFlag(FlagAttestedCredentialData) {
+		// aaguid (16) + credentialIdLength (2)
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		result.AAGUID = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength > 1023 || len(rest) < idLength {
+			return nil, errors.New("invalid credential id length")
+		}
+		result.CredentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, keyRest, err := parseCOSEKey(rest)
+		if err != nil {
+			return nil, err
+		}
+		result.CredentialPublicKey = rest[:len(rest)-len(keyRest)]
+		rest = keyRest
+	}
+
+	if result.HasFlag(FlagExtensionData) {
+		_, extRest, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		rest = extRest
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after authenticator data")
+	}
+
+	return result, nil
+}
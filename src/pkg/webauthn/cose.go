@@ -0,0 +1,142 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Алгоритмы COSE, которые поддерживает сервер (IANA COSE Algorithms)
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms алгоритмы в порядке предпочтения для pubKeyCredParams
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// Параметры COSE_Key (RFC 9052, раздел 7 и RFC 9053)
+const (
+	coseKeyKty int64 = 1
+	coseKeyAlg int64 = 3
+	coseKeyCrv int64 = -1
+	coseKeyX   int64 = -2
+	coseKeyY   int64 = -3
+	coseKeyN   int64 = -1
+	coseKeyE   int64 = -2
+
+	coseKtyOKP int64 = 1
+	coseKtyEC2 int64 = 2
+	coseKtyRSA int64 = 3
+
+	coseCrvP256    int64 = 1
+	coseCrvEd25519 int64 = 6
+)
+
+// minRSAKeyBits минимальный допустимый размер RSA ключа
+const minRSAKeyBits = 2048
+
+// PublicKey публичный ключ учетных данных, разобранный из COSE_Key
+type PublicKey struct {
+	Algorithm int64
+	Key       crypto.PublicKey
+}
+
+// ParseCOSEKey разбирает публичный ключ в формате COSE_Key
+func ParseCOSEKey(data []byte) (*PublicKey, error) {
+	key, rest, err := parseCOSEKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("cose: trailing data after key")
+	}
+	return key, nil
+}
+
+func parseCOSEKey(data []byte) (*PublicKey, []byte, error) {
+	value, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, nil, errors.New("cose: key is not a map")
+	}
+
+	kty, _ := params[coseKeyKty].(int64)
+	alg, _ := params[coseKeyAlg].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		if crv, _ := params[coseKeyCrv].(int64); crv != coseCrvP256 {
+			return nil, nil, errors.New("cose: unsupported curve")
+		}
+		x, _ := params[coseKeyX].([]byte)
+		y, _ := params[coseKeyY].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, nil, errors.New("cose: invalid ec2 coordinates")
+		}
+
+		curve := elliptic.P256()
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, nil, errors.New("cose: point is not on curve")
+		}
+		return &PublicKey{Algorithm: alg, Key: pub}, rest, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		if crv, _ := params[coseKeyCrv].(int64); crv != coseCrvEd25519 {
+			return nil, nil, errors.New("cose: unsupported curve")
+		}
+		x, _ := params[coseKeyX].([]byte)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, nil, errors.New("cose: invalid ed25519 key")
+		}
+		return &PublicKey{Algorithm: alg, Key: ed25519.PublicKey(x)}, rest, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := params[coseKeyN].([]byte)
+		e, _ := params[coseKeyE].([]byte)
+		modulus := new(big.Int).SetBytes(n)
+		exponent := new(big.Int).SetBytes(e)
+		if modulus.BitLen() < minRSAKeyBits {
+			return nil, nil, errors.New("cose: rsa key is too short")
+		}
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, nil, errors.New("cose: invalid rsa exponent")
+		}
+		return &PublicKey{Algorithm: alg, Key: &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}}, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("cose: unsupported key type %d with algorithm %d", kty, alg)
+	}
+}
+
+// Verify проверяет подпись данных ключом учетных данных
+func (k *PublicKey) Verify(data, signature []byte) error {
+	switch key := k.Key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return errors.New("invalid ecdsa signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signature) {
+			return errors.New("invalid ed25519 signature")
+		}
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unsupported key")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package webauthn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// URLEncodedBytes бинарные данные, которые в JSON передаются в base64url (как в WebAuthn JSON API браузеров)
+type URLEncodedBytes []byte
+
+// MarshalJSON кодирует данные в base64url без дополнения
+func (b URLEncodedBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON декодирует base64url с дополнением или без
+func (b *URLEncodedBytes) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// String возвращает base64url представление
+func (b URLEncodedBytes) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// RelyingPartyEntity сведения о сервисе для аутентификатора
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity сведения о пользователе для аутентификатора.
+// ID - непрозрачный идентификатор (user handle), который возвращается при входе
+type UserEntity struct {
+	ID          URLEncodedBytes `json:"id"`
+	Name        string          `json:"name"`
+	DisplayName string          `json:"displayName"`
+}
+
+// CredentialParameter допустимый алгоритм ключа
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor ссылка на существующие учетные данные
+type CredentialDescriptor struct {
+	Type       string          `json:"type"`
+	ID         URLEncodedBytes `json:"id"`
+	Transports []string        `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection требования к аутентификатору при регистрации
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions параметры navigator.credentials.create()
+type CreationOptions struct {
+	Challenge              URLEncodedBytes        `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions параметры navigator.credentials.get()
+type RequestOptions struct {
+	Challenge        URLEncodedBytes        `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse результат navigator.credentials.create() (PublicKeyCredential.toJSON())
+type RegistrationResponse struct {
+	ID       string                       `json:"id"`
+	RawID    URLEncodedBytes              `json:"rawId"`
+	Type     string                       `json:"type"`
+	Response AuthenticatorAttestationData `json:"response"`
+}
+
+// AuthenticatorAttestationData ответ аутентификатора при регистрации
+type AuthenticatorAttestationData struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+	AttestationObject URLEncodedBytes `json:"attestationObject"`
+	Transports        []string        `json:"transports,omitempty"`
+}
+
+// AssertionResponse результат navigator.credentials.get() (PublicKeyCredential.toJSON())
+type AssertionResponse struct {
+	ID       string                     `json:"id"`
+	RawID    URLEncodedBytes            `json:"rawId"`
+	Type     string                     `json:"type"`
+	Response AuthenticatorAssertionData `json:"response"`
+}
+
+// AuthenticatorAssertionData ответ аутентификатора при входе
+type AuthenticatorAssertionData struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBytes `json:"authenticatorData"`
+	Signature         URLEncodedBytes `json:"signature"`
+	UserHandle        URLEncodedBytes `json:"userHandle,omitempty"`
+}
+
+// CollectedClientData данные клиента, подписанные вместе с authenticator data
+type CollectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin,omitempty"`
+}
+
+// ParseClientData разбирает clientDataJSON
+func ParseClientData(clientDataJSON []byte) (*CollectedClientData, error) {
+	var clientData CollectedClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return nil, err
+	}
+	return &clientData, nil
+}
@@ -0,0 +1,294 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Типы церемоний в clientDataJSON
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// publicKeyCredentialType единственный тип учетных данных WebAuthn
+const publicKeyCredentialType = "public-key"
+
+// challengeSize размер challenge в байтах (не меньше 16 по спецификации)
+const challengeSize = 32
+
+// ErrVerification церемония не прошла проверку
+var ErrVerification = errors.New("webauthn verification failed")
+
+// Config параметры проверяющей стороны (Relying Party)
+type Config struct {
+	// RPID домен, к которому привязываются учетные данные
+	RPID   string
+	RPName string
+	// Origins допустимые значения origin в clientDataJSON
+	Origins []string
+	Timeout time.Duration
+	// RequireUserVerification требовать проверку пользователя (PIN, биометрия) на аутентификаторе
+	RequireUserVerification bool
+}
+
+// RelyingParty выполняет церемонии регистрации и входа
+type RelyingParty struct {
+	cfg Config
+}
+
+// New создает проверяющую сторону
+func New(cfg Config) *RelyingParty {
+	return &RelyingParty{cfg: cfg}
+}
+
+// Credential учетные данные, проверенные при регистрации
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool
+	BackupState    bool
+}
+
+// AssertionResult результат проверки входа
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+	BackupState  bool
+}
+
+// NewChallenge генерирует случайный challenge
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// CreationOptions формирует параметры регистрации.
+// exclude - уже зарегистрированные учетные данные пользователя, чтобы не создать дубликат
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: publicKeyCredentialType, Alg: alg})
+	}
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+
+	return &CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingPartyEntity{ID: rp.cfg.RPID, Name: rp.cfg.RPName},
+		User:               user,
+		PubKeyCredParams:   params,
+		Timeout:            rp.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   rp.userVerification(),
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions формирует параметры входа.
+// Пустой allow означает вход с выбором passkey на устройстве (discoverable credentials)
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.cfg.Timeout.Milliseconds(),
+		RPID:             rp.cfg.RPID,
+		AllowCredentials: allow,
+		UserVerification: rp.userVerification(),
+	}
+}
+
+// VerifyRegistration проверяет ответ аутентификатора при регистрации (WebAuthn Level 3, раздел 7.1).
+// Аттестация не запрашивается, поэтому attStmt не проверяется и модель аутентификатора не доверяется
+func (rp *RelyingParty) VerifyRegistration(challenge []byte, resp *RegistrationResponse) (*Credential, error) {
+	if resp.Type != publicKeyCredentialType {
+		return nil, verificationError("unexpected credential type")
+	}
+
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	value, rest, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, verificationError("malformed attestation object")
+	}
+	attestation, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, verificationError("malformed attestation object")
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, verificationError("missing authenticator data")
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, verificationError(err.Error())
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if !authData.HasFlag(FlagAttestedCredentialData) {
+		return nil, verificationError("missing attested credential data")
+	}
+	if !bytes.Equal(authData.CredentialID, resp.RawID) {
+		return nil, verificationError("credential id mismatch")
+	}
+
+	publicKey, err := ParseCOSEKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, verificationError(err.Error())
+	}
+
+	return &Credential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.CredentialPublicKey,
+		Algorithm:      publicKey.Algorithm,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		Transports:     resp.Response.Transports,
+		UserVerified:   authData.HasFlag(FlagUserVerified),
+		BackupEligible: authData.HasFlag(FlagBackupEligible),
+		BackupState:    authData.HasFlag(FlagBackupState),
+	}, nil
+}
+
+// VerifyAssertion проверяет ответ аутентификатора при входе (WebAuthn Level 3, раздел 7.2).
+// publicKey и signCount - сохраненные при регистрации данные учетных данных
+func (rp *RelyingParty) VerifyAssertion(challenge []byte, publicKey []byte, signCount uint32, resp *AssertionResponse) (*AssertionResult, error) {
+	if resp.Type != publicKeyCredentialType {
+		return nil, verificationError("unexpected credential type")
+	}
+
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, verificationError(err.Error())
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	key, err := ParseCOSEKey(publicKey)
+	if err != nil {
+		return nil, verificationError(err.Error())
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := make([]byte, 0, len(resp.Response.AuthenticatorData)+len(clientDataHash))
+	signed = append(signed, resp.Response.AuthenticatorData...)
+	signed = append(signed, clientDataHash[:]...)
+	if err := key.Verify(signed, resp.Response.Signature); err != nil {
+		return nil, verificationError("invalid signature")
+	}
+
+	// Счетчик, который не растет, указывает на клонированный аутентификатор.
+	// Синхронизируемые passkey всегда возвращают 0 - для них проверка не применяется
+	if (authData.SignCount != 0 || signCount != 0) && authData.SignCount <= signCount {
+		return nil, verificationError("signature counter did not increase")
+	}
+
+	return &AssertionResult{
+		SignCount:    authData.SignCount,
+		UserVerified: authData.HasFlag(FlagUserVerified),
+		BackupState:  authData.HasFlag(FlagBackupState),
+	}, nil
+}
+
+// ChallengeFromClientData извлекает challenge из clientDataJSON, чтобы найти сохраненную церемонию.
+// Результат еще не проверен и должен сверяться с сохраненным challenge при верификации
+func ChallengeFromClientData(clientDataJSON []byte) ([]byte, error) {
+	clientData, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, verificationError("malformed client data")
+	}
+
+	challenge, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
+	if err != nil {
+		return nil, verificationError("malformed challenge")
+	}
+	return challenge, nil
+}
+
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, ceremony string, challenge []byte) error {
+	clientData, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return verificationError("malformed client data")
+	}
+
+	if clientData.Type != ceremony {
+		return verificationError("unexpected ceremony type")
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return verificationError("challenge mismatch")
+	}
+
+	if !slices.Contains(rp.cfg.Origins, clientData.Origin) {
+		return verificationError(fmt.Sprintf("origin %q is not allowed", clientData.Origin))
+	}
+	if clientData.CrossOrigin {
+		return verificationError("cross-origin ceremonies are not allowed")
+	}
+
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.cfg.RPID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return verificationError("rp id hash mismatch")
+	}
+
+	if !authData.HasFlag(FlagUserPresent) {
+		return verificationError("user presence is required")
+	}
+	if rp.cfg.RequireUserVerification && !authData.HasFlag(FlagUserVerified) {
+		return verificationError("user verification is required")
+	}
+	// Флаг BS без BE недопустим (WebAuthn Level 3, раздел 6.1.3)
+	if authData.HasFlag(FlagBackupState) && !authData.HasFlag(FlagBackupEligible) {
+		return verificationError("invalid backup flags")
+	}
+
+	return nil
+}
+
+func (rp *RelyingParty) userVerification() string {
+	if rp.cfg.RequireUserVerification {
+		return "required"
+	}
+	return "preferred"
+}
+
+func verificationError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrVerification, reason)
+}
@@ -0,0 +1,457 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// softAuthenticator программный аутентификатор: формирует ответы так же, как браузер и ключ безопасности
+type softAuthenticator struct {
+	t *testing.T
+
+	ecdsaKey   *ecdsa.PrivateKey
+	ed25519Key ed25519.PrivateKey
+
+	credentialID []byte
+}
+
+func newSoftAuthenticator(t *testing.T, alg int64) *softAuthenticator {
+	t.Helper()
+
+	a := &softAuthenticator{t: t, credentialID: randomBytes(t, 16)}
+	switch alg {
+	case AlgES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.ecdsaKey = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.ed25519Key = key
+	default:
+		t.Fatalf("unsupported algorithm %d", alg)
+	}
+	return a
+}
+
+// coseKey публичный ключ аутентификатора в формате COSE_Key
+func (a *softAuthenticator) coseKey() []byte {
+	if a.ecdsaKey != nil {
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.ecdsaKey.X.FillBytes(x)
+		a.ecdsaKey.Y.FillBytes(y)
+		return encodeCBOR(a.t, map[interface{}]interface{}{
+			coseKeyKty: coseKtyEC2,
+			coseKeyAlg: AlgES256,
+			coseKeyCrv: coseCrvP256,
+			coseKeyX:   x,
+			coseKeyY:   y,
+		})
+	}
+
+	return encodeCBOR(a.t, map[interface{}]interface{}{
+		coseKeyKty: coseKtyOKP,
+		coseKeyAlg: AlgEdDSA,
+		coseKeyCrv: coseCrvEd25519,
+		coseKeyX:   []byte(a.ed25519Key.Public().(ed25519.PublicKey)),
+	})
+}
+
+func (a *softAuthenticator) sign(data []byte) []byte {
+	if a.ecdsaKey != nil {
+		digest := sha256.Sum256(data)
+		signature, err := ecdsa.SignASN1(rand.Reader, a.ecdsaKey, digest[:])
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		return signature
+	}
+	return ed25519.Sign(a.ed25519Key, data)
+}
+
+// ceremony параметры, которые тест может исказить
+type ceremony struct {
+	rpID      string
+	origin    string
+	challenge []byte
+	flags     byte
+	signCount uint32
+}
+
+func validCeremony(challenge []byte) ceremony {
+	return ceremony{
+		rpID:      testRPID,
+		origin:    testOrigin,
+		challenge: challenge,
+		flags:     FlagUserPresent | FlagUserVerified,
+	}
+}
+
+func (a *softAuthenticator) authenticatorData(c ceremony, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+
+	data := append([]byte(nil), rpIDHash[:]...)
+	flags := c.flags
+	if attested {
+		flags |= FlagAttestedCredentialData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, c.signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) register(c ceremony) *RegistrationResponse {
+	attestation := encodeCBOR(a.t, map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": a.authenticatorData(c, true),
+	})
+
+	return &RegistrationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  publicKeyCredentialType,
+		Response: AuthenticatorAttestationData{
+			ClientDataJSON:    clientDataJSON(a.t, ceremonyCreate, c),
+			AttestationObject: attestation,
+		},
+	}
+}
+
+func (a *softAuthenticator) assert(c ceremony) *AssertionResponse {
+	authData := a.authenticatorData(c, false)
+	clientData := clientDataJSON(a.t, ceremonyGet, c)
+	clientDataHash := sha256.Sum256(clientData)
+
+	return &AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  publicKeyCredentialType,
+		Response: AuthenticatorAssertionData{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         a.sign(append(append([]byte(nil), authData...), clientDataHash[:]...)),
+		},
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	tests := []struct {
+		name      string
+		alg       int64
+		requireUV bool
+		modify    func(c *ceremony)
+		// wantErr причина отказа; пустая строка - проверка проходит
+		wantErr string
+	}{
+		{name: "valid es256", alg: AlgES256, requireUV: true},
+		{name: "valid ed25519", alg: AlgEdDSA, requireUV: true},
+		{
+			name:    "bad origin",
+			alg:     AlgES256,
+			modify:  func(c *ceremony) { c.origin = "https://evil.example" },
+			wantErr: "is not allowed",
+		},
+		{
+			name:    "wrong rp id hash",
+			alg:     AlgES256,
+			modify:  func(c *ceremony) { c.rpID = "evil.example" },
+			wantErr: "rp id hash mismatch",
+		},
+		{
+			name:    "challenge mismatch",
+			alg:     AlgEdDSA,
+			modify:  func(c *ceremony) { c.challenge = []byte("another challenge") },
+			wantErr: "challenge mismatch",
+		},
+		{
+			name:      "user verification required",
+			alg:       AlgES256,
+			requireUV: true,
+			modify:    func(c *ceremony) { c.flags = FlagUserPresent },
+			wantErr:   "user verification is required",
+		},
+		{
+			name:   "user verification preferred",
+			alg:    AlgES256,
+			modify: func(c *ceremony) { c.flags = FlagUserPresent },
+		},
+		{
+			name:    "user not present",
+			alg:     AlgEdDSA,
+			modify:  func(c *ceremony) { c.flags = FlagUserVerified },
+			wantErr: "user presence is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := newTestRelyingParty(tt.requireUV)
+			authenticator := newSoftAuthenticator(t, tt.alg)
+			challenge := randomBytes(t, challengeSize)
+
+			c := validCeremony(challenge)
+			if tt.modify != nil {
+				tt.modify(&c)
+			}
+
+			credential, err := rp.VerifyRegistration(challenge, authenticator.register(c))
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrVerification) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected verification error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if credential.Algorithm != tt.alg {
+				t.Errorf("algorithm = %d, want %d", credential.Algorithm, tt.alg)
+			}
+			if string(credential.ID) != string(authenticator.credentialID) {
+				t.Errorf("credential id mismatch")
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	tests := []struct {
+		name      string
+		alg       int64
+		requireUV bool
+		// stored счетчик подписей, сохраненный при предыдущем входе
+		stored uint32
+		modify func(c *ceremony)
+		tamper func(resp *AssertionResponse)
+		// wantErr причина отказа; пустая строка - проверка проходит
+		wantErr string
+	}{
+		{
+			name:      "valid es256",
+			alg:       AlgES256,
+			requireUV: true,
+			stored:    4,
+			modify:    func(c *ceremony) { c.signCount = 5 },
+		},
+		{
+			name:      "valid ed25519",
+			alg:       AlgEdDSA,
+			requireUV: true,
+			stored:    4,
+			modify:    func(c *ceremony) { c.signCount = 5 },
+		},
+		{
+			name: "synced passkey without counter",
+			alg:  AlgES256,
+		},
+		{
+			name:    "bad origin",
+			alg:     AlgES256,
+			modify:  func(c *ceremony) { c.origin = "https://example.com.evil.example" },
+			wantErr: "is not allowed",
+		},
+		{
+			name:    "wrong rp id hash",
+			alg:     AlgEdDSA,
+			modify:  func(c *ceremony) { c.rpID = "evil.example" },
+			wantErr: "rp id hash mismatch",
+		},
+		{
+			name:    "sign count regression",
+			alg:     AlgES256,
+			stored:  10,
+			modify:  func(c *ceremony) { c.signCount = 9 },
+			wantErr: "signature counter did not increase",
+		},
+		{
+			name:    "sign count not increased",
+			alg:     AlgEdDSA,
+			stored:  10,
+			modify:  func(c *ceremony) { c.signCount = 10 },
+			wantErr: "signature counter did not increase",
+		},
+		{
+			name:    "counter reset to zero",
+			alg:     AlgES256,
+			stored:  10,
+			wantErr: "signature counter did not increase",
+		},
+		{
+			name:      "user verification required",
+			alg:       AlgES256,
+			requireUV: true,
+			modify:    func(c *ceremony) { c.flags = FlagUserPresent },
+			wantErr:   "user verification is required",
+		},
+		{
+			name:    "invalid signature",
+			alg:     AlgEdDSA,
+			tamper:  func(resp *AssertionResponse) { resp.Response.Signature[0] ^= 0xff },
+			wantErr: "invalid signature",
+		},
+		{
+			name: "signed with another key",
+			alg:  AlgES256,
+			tamper: func(resp *AssertionResponse) {
+				other := newSoftAuthenticator(t, AlgES256)
+				clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+				signed := append(append([]byte(nil), resp.Response.AuthenticatorData...), clientDataHash[:]...)
+				resp.Response.Signature = other.sign(signed)
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			name: "registration ceremony type",
+			alg:  AlgES256,
+			tamper: func(resp *AssertionResponse) {
+				challenge, _ := ChallengeFromClientData(resp.Response.ClientDataJSON)
+				resp.Response.ClientDataJSON = clientDataJSON(t, ceremonyCreate, validCeremony(challenge))
+			},
+			wantErr: "unexpected ceremony type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := newTestRelyingParty(tt.requireUV)
+			authenticator := newSoftAuthenticator(t, tt.alg)
+			challenge := randomBytes(t, challengeSize)
+
+			c := validCeremony(challenge)
+			if tt.modify != nil {
+				tt.modify(&c)
+			}
+			resp := authenticator.assert(c)
+			if tt.tamper != nil {
+				tt.tamper(resp)
+			}
+
+			result, err := rp.VerifyAssertion(challenge, authenticator.coseKey(), tt.stored, resp)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrVerification) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected verification error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.SignCount != c.signCount {
+				t.Errorf("sign count = %d, want %d", result.SignCount, c.signCount)
+			}
+		})
+	}
+}
+
+func newTestRelyingParty(requireUV bool) *RelyingParty {
+	return New(Config{
+		RPID:                    testRPID,
+		RPName:                  "Test",
+		Origins:                 []string{testOrigin},
+		RequireUserVerification: requireUV,
+	})
+}
+
+func clientDataJSON(t *testing.T, ceremonyType string, c ceremony) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(CollectedClientData{
+		Type:      ceremonyType,
+		Challenge: base64.RawURLEncoding.EncodeToString(c.challenge),
+		Origin:    c.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	t.Helper()
+
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encodeCBOR кодирует подмножество CBOR, которое разбирает decodeCBOR
+func encodeCBOR(t *testing.T, value interface{}) []byte {
+	t.Helper()
+
+	switch v := value.(type) {
+	case int64:
+		if v >= 0 {
+			return cborHeader(0, uint64(v))
+		}
+		return cborHeader(1, uint64(-1-v))
+	case []byte:
+		return append(cborHeader(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHeader(3, uint64(len(v))), v...)
+	case map[interface{}]interface{}:
+		// Порядок ключей фиксирован, чтобы вывод был детерминированным
+		keys := make([][]byte, 0, len(v))
+		encoded := make(map[string][]byte, len(v))
+		for key, item := range v {
+			k := encodeCBOR(t, key)
+			keys = append(keys, k)
+			encoded[string(k)] = encodeCBOR(t, item)
+		}
+		sort.Slice(keys, func(i, j int) bool { return string(keys[i]) < string(keys[j]) })
+
+		out := cborHeader(5, uint64(len(v)))
+		for _, k := range keys {
+			out = append(out, k...)
+			out = append(out, encoded[string(k)]...)
+		}
+		return out
+	default:
+		t.Fatalf("unsupported cbor value %T", value)
+		return nil
+	}
+}
+
+func cborHeader(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+	}
+}